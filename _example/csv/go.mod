@@ -4,6 +4,4 @@ go 1.16
 
 replace github.com/jyopp/absorb => ../..
 
-require (
-	github.com/jyopp/absorb v0.0.0-00010101000000-000000000000 // indirect
-)
+require github.com/jyopp/absorb v0.0.0-00010101000000-000000000000
@@ -83,12 +83,12 @@ func main() {
 	// And for good measure, read the file in the background
 	fmt.Println("\n=== Reading structs in the background ===")
 	ch := make(chan *PersonRecord)
-	go func() {
-		defer close(ch)
-		absorb.Absorb(ch, reader)
-	}()
+	wait := absorb.Go(ch, reader)
 
 	for person := range ch {
 		fmt.Printf("Got %v via channel\n", person)
 	}
+	if err = wait(); err != nil {
+		panic(err)
+	}
 }
@@ -0,0 +1,10 @@
+module github.com/jyopp/absorb/_example/yaml
+
+go 1.16
+
+replace github.com/jyopp/absorb => ../..
+
+require (
+	github.com/jyopp/absorb v0.0.0-00010101000000-000000000000
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jyopp/absorb"
+)
+
+type CrewMember struct {
+	Name    string
+	Rank    string
+	Deck    *int
+	Station string `yaml:"station"`
+}
+
+func main() {
+	f, err := os.Open("testdata/crew.yaml")
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
+	source := &YAMLSource{ReadSeeker: f}
+
+	fmt.Println("=== Reading structs from YAML ===")
+	var crew []CrewMember
+	if err = absorb.Absorb(&crew, source); err != nil {
+		panic(err)
+	}
+	for _, member := range crew {
+		if member.Deck != nil {
+			fmt.Printf("%s (%s) is on deck %d\n", member.Name, member.Rank, *member.Deck)
+		} else {
+			fmt.Printf("%s (%s) has no assigned deck\n", member.Name, member.Rank)
+		}
+	}
+
+	fmt.Println("\n=== Reading maps from YAML ===")
+	var records []map[string]interface{}
+	if err = absorb.Absorb(&records, source); err != nil {
+		panic(err)
+	}
+	fmt.Printf("Got %+v\n", records)
+}
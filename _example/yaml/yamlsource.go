@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/jyopp/absorb"
+	"gopkg.in/yaml.v3"
+)
+
+// YAMLSource reads a YAML stream whose documents are each a sequence of mappings,
+// the common "list of records" shape used by config fixtures.
+type YAMLSource struct {
+	io.ReadSeeker
+}
+
+// Emit implements absorb.Absorbable
+//
+// The keys passed to Open are the union of every mapping's keys, in the order they
+// are first seen. Keys missing from a record are absorbed as nil.
+func (s *YAMLSource) Emit(into absorb.Absorber) error {
+	if _, err := s.ReadSeeker.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	// Mapping keys can only be known after reading every document, so decode the
+	// whole stream into nodes and defer value decoding until the rows are absorbed.
+	var records []*yaml.Node
+	var keys []string
+	keyIdx := make(map[string]int)
+
+	decoder := yaml.NewDecoder(s.ReadSeeker)
+	for {
+		var doc yaml.Node
+		if err := decoder.Decode(&doc); err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		seq := &doc
+		if seq.Kind == yaml.DocumentNode && len(seq.Content) > 0 {
+			seq = seq.Content[0]
+		}
+		if seq.Kind != yaml.SequenceNode {
+			return fmt.Errorf("yaml document at line %d is not a sequence", seq.Line)
+		}
+		for _, record := range seq.Content {
+			if record.Kind != yaml.MappingNode {
+				return fmt.Errorf("yaml record at line %d is not a mapping", record.Line)
+			}
+			// Mapping nodes hold alternating key and value nodes.
+			for i := 0; i < len(record.Content); i += 2 {
+				key := record.Content[i].Value
+				if _, ok := keyIdx[key]; !ok {
+					keyIdx[key] = len(keys)
+					keys = append(keys, key)
+				}
+			}
+			records = append(records, record)
+		}
+	}
+	if len(keys) == 0 && len(records) > 0 {
+		return errors.New("yaml records contain no keys")
+	}
+
+	into.Open("yaml", len(records), keys...)
+	defer into.Close()
+
+	rowData := make([]interface{}, len(keys))
+	for _, record := range records {
+		for idx := range rowData {
+			rowData[idx] = nil
+		}
+		for i := 0; i < len(record.Content); i += 2 {
+			var value interface{}
+			if err := record.Content[i+1].Decode(&value); err != nil {
+				return err
+			}
+			rowData[keyIdx[record.Content[i].Value]] = value
+		}
+		into.Absorb(rowData...)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package absorb
 
 import (
 	"reflect"
+	"time"
 )
 
 // Absorbable defines the interface for types that may fill Absorbers with values.
@@ -15,43 +16,116 @@ type Absorbable interface {
 type Absorber interface {
 	// Open configures the Absorber to accept elements using the given set of keys.
 	// The given tagname (such as "mydb") is preferred when mapping keys to struct fields.
+	// Sources serving several conventions may pass a comma-separated list of tagnames
+	// (such as "sqlite,db"); each field is mapped by the first of these that it declares.
+	// Fields promoted from embedded structs are mapped too, and nil pointers to embedded
+	// structs are allocated as their fields are assigned.
 	// Count is a hint about the number of items this Absorber can produce. If the number
 	// of items is unknown, pass -1.
 	//
-	// If no keys are provided, Absorb may be called at most once, with a single value.
+	// If no keys are provided, Absorb may be called at most once, with a single value,
+	// unless the Flatten option is set. A key provided more than once is absorbed from its
+	// last column, unless the DuplicateKeys option is set.
 	//
 	// Panics if count is greater than the absorber's maximum size.
 	Open(tag string, count int, keys ...string)
 	// Absorb creates an output element from the given values and adds it to the output.
 	//
-	// If the output type is a channel, this method may block.
+	// If the output type is a channel, this method may block. Each send on a pointer
+	// channel is a newly allocated element, owned by the receiver.
 	// If the output type is array (not slice), panics on overflow.
+	//
+	// Values may be wrapped in a reflect.Value, or implement driver.Valuer, as values read
+	// through reflection or from a database driver do. Each is unwrapped before it is
+	// converted, unless the destination has the value's own type.
 	Absorb(values ...interface{})
 	// Close releases internal resources and assigns the output when relevant.
+	//
+	// Calls to Open, Absorb, and Close made out of order panic with ErrNotOpen,
+	// ErrAlreadyOpen, or ErrClosed.
 	Close()
 }
 
+// Absorbers are not safe for concurrent use. Open, Absorb, and Close must be called in
+// sequence, though not necessarily from the same goroutine, as long as each call happens
+// before the next. The destination belongs to the Absorber until Close returns: a slice,
+// map, or pointer destination must not be read before then, except that a channel may be
+// received from, and a callback runs, on any goroutine. See Go to absorb asynchronously.
+
+// OverflowAbsorber is implemented by Absorbers for channel destinations. When created with
+// SendContext or SendTimeout, rows that could not be sent are collected instead.
+type OverflowAbsorber interface {
+	Absorber
+	// Overflow returns a slice of the channel's element type, holding every row that was
+	// not sent, in order. Returns nil if every row was sent.
+	Overflow() interface{}
+}
+
+// ResettableAbsorber is implemented by Absorbers created with New.
+//
+// An Absorber may be opened again after it is closed, replacing the contents of its
+// destination with the next result set. Reset also retargets it to a new destination,
+// so that one Absorber, with its options, can be reused across many calls to Emit.
+type ResettableAbsorber interface {
+	Absorber
+	// Reset makes the Absorber write into dst the next time it is opened, as if it had
+	// been created by New with the same options. Rows kept as overflow are discarded.
+	// Panics with ErrAlreadyOpen if the Absorber is open, and like New, panics if dst is
+	// not a valid destination.
+	Reset(dst interface{})
+}
+
 /*
-	Absorb absorbs all source values into a new Absorber for dst.
-	Equivalent to src.Emit(absorb.New(dst)).
-
-	Examples:
-	  var mySlice []structType
-	  err := absorb.Absorb(&mySlice, dataSource)
-	  structChan := make(chan structType)
-	  err = absorb.Absorb(structChan, rowReader)
+Absorb absorbs all source values into a new Absorber for dst.
+Equivalent to src.Emit(absorb.New(dst, opts...)), unless the Resume option is given
+and src is a ResumableSource, which is then asked to skip to the resume point.
+
+Examples:
+
+	var mySlice []structType
+	err := absorb.Absorb(&mySlice, dataSource)
+	structChan := make(chan structType)
+	err = absorb.Absorb(structChan, rowReader)
 */
-func Absorb(dst interface{}, src Absorbable) error {
-	return src.Emit(New(dst))
+func Absorb(dst interface{}, src Absorbable, opts ...Option) error {
+	return New(dst, opts...).(*absorberImpl).emit(src)
+}
+
+// emit absorbs every row of src, asking a ResumableSource to skip to the resume point.
+func (a *absorberImpl) emit(src Absorbable) error {
+	var err error
+	if resumable, ok := src.(ResumableSource); ok && a.cfg.resume > 0 {
+		// The source skips rows itself, so the absorber doesn't need to.
+		a.sourceSkips = true
+		err = resumable.EmitFrom(a, a.cfg.resume)
+	} else {
+		err = src.Emit(a)
+	}
+	if err != nil {
+		return err
+	}
+	// Rows skipped with CollectErrors are reported once the source succeeds.
+	return a.Errors()
 }
 
 // Create a new Absorber that writes elements of the corresponding type into dst.
-// Panics if dst is not an assignable reference or a channel.
-func New(dst interface{}) Absorber {
+// Options are applied in order, and configure key mapping and value conversion.
+//
+// Besides references and channels, dst may be a callback of type func(T), which is
+// called with each element as it is absorbed.
+//
+// Byte slices and arrays follow the same rules as other slices and arrays, with one
+// addition. Opened without keys, a *[]byte or *[N]byte receives a single string or
+// []byte value whole, and an array panics if the length differs. Opened with one key, a
+// *[]byte receives the concatenation of every row's string or []byte value, and a
+// number is appended as a single byte. A *[][]byte receives one element per row.
+// Panics if dst is not an assignable reference, a channel, or a callback.
+func New(dst interface{}, opts ...Option) Absorber {
 	// Consider the types:
 	// DstVal           ContainerVal   Elem
 	// *[]struct        []struct       struct
 	// chan struct      <---           struct
+	// func(struct)     <---           struct
 	// *struct          <---           struct
 	// *int             <---           int
 	// *[10]map[s]i     [10]map[s]i    map[s]i
@@ -61,26 +135,35 @@ func New(dst interface{}) Absorber {
 	// The best workaround is to not use absorb for single-valued iteration of this type.
 	// If absorb is required, create an Absorber that just stores the arguments to Absorb().
 
-	dstVal := reflect.ValueOf(dst)
-	var setVal reflect.Value
+	a := &absorberImpl{
+		dst:    dst,
+		setVal: destination(dst),
+		cfg:    newConfig(opts),
+	}
+	trackLeaks(a)
+	return a
+}
 
+// destination returns the value that elements are written into for dst.
+func destination(dst interface{}) reflect.Value {
+	dstVal := reflect.ValueOf(dst)
 	switch dstVal.Kind() {
 	case reflect.Ptr:
 		// The default case; We'll set dstVal.Elem() when accepting values.
-		setVal = dstVal.Elem()
+		return dstVal.Elem()
 	case reflect.Chan:
 		if dstVal.Type().ChanDir() == reflect.RecvDir {
 			panic("cannot absorb into receive-only channel of type " + dstVal.Type().String())
 		}
 		// It is correct to pass Channels directly; Skip a level of indirection.
-		setVal = dstVal
+		return dstVal
+	case reflect.Func:
+		if t := dstVal.Type(); t.NumIn() != 1 || t.NumOut() != 0 || t.IsVariadic() {
+			panic("cannot absorb into callback of type " + t.String() + "; must be func(T)")
+		}
+		return dstVal
 	default:
-		panic("cannot absorb into (non-ptr, non-chan) " + dstVal.Type().String())
-	}
-
-	return &absorberImpl{
-		dst:    dst,
-		setVal: setVal,
+		panic("cannot absorb into (non-ptr, non-chan, non-func) " + dstVal.Type().String())
 	}
 }
 
@@ -90,42 +173,141 @@ type absorberImpl struct {
 	setVal  reflect.Value
 	builder *elementBuilder
 	unwrap  bool
+	// elemType is the type allocated for each element, which is the builder's type
+	// unless elements are wrapped in an Envelope.
+	elemType reflect.Type
+	envelope bool
+	// tracksPresence is set if elements implement PresenceTracker.
+	tracksPresence bool
+	// validates is set if elements implement AfterAbsorber.
+	validates bool
+	// direct is set while open when rows are built in place in a slice or array, and no
+	// option needs to see or recover each row.
+	direct bool
+	state  lifecycle
+	cfg    *config
+	// parallel is set while open, when elements are built by a pool of workers.
+	parallel *parallelRun
+	// overflow holds unsent rows, once a bounded channel send has failed.
+	overflow reflect.Value
+	// keyed is set when rows are indexed into a map[K]T, or grouped into a map[K][]T.
+	keyed *keyedPlan
+	// upsert is set when rows update the elements of a slice by key.
+	upsert *upsertPlan
+	// opIdx is the index of the operation column, or -1 unless Operations is set.
+	opIdx int
+	// defaults is set when an Override supplies default values for the element type.
+	defaults *defaultsPlan
+	// skip counts the rows left to discard before a resume point, unless sourceSkips
+	// indicates that the source skipped them already.
+	skip        int
+	sourceSkips bool
+	// width is the number of keys passed to Open, which each row must match.
+	width int
+	// flatten is set when each value of a row is absorbed as its own element.
+	flatten bool
+	// keep holds the indexes of the columns absorbed from each row, when columns with
+	// duplicate keys are dropped.
+	keep []int
+	// errs holds the errors of rows skipped with CollectErrors, and dropped counts the
+	// rows that left no element in a slice or array destination.
+	errs    []error
+	dropped int
+	// failure is the panic that aborted a call to Absorb, recorded for OnClose.
+	failure error
+	// stack is the creation stack, captured only when leak detection is enabled.
+	stack []byte
+}
+
+// lifecycle tracks whether an Absorber is between calls to Open and Close.
+type lifecycle int
+
+const (
+	lifecycleNew lifecycle = iota
+	lifecycleOpen
+	lifecycleClosed
+)
+
+// checkOpen panics with a lifecycle error if the absorber is not open.
+func (a *absorberImpl) checkOpen() {
+	switch a.state {
+	case lifecycleNew:
+		panic(ErrNotOpen)
+	case lifecycleClosed:
+		panic(ErrClosed)
+	}
 }
 
 func (a *absorberImpl) Open(tag string, count int, keys ...string) {
+	if a.state == lifecycleOpen {
+		panic(ErrAlreadyOpen)
+	}
+	a.width, a.upsert = len(keys), nil
+	keys, a.keep = a.cfg.resolveDuplicates(keys)
+	openKeys := keys
 	// Examine setVal to get element type and descend into its type structure as needed.
 	elemTyp := a.setVal.Type()
+	a.flatten = a.cfg.flatten && len(keys) == 0
 	switch elemTyp.Kind() {
 	case reflect.Array:
-		if count > elemTyp.Len() {
+		if count > elemTyp.Len() && !a.flatten {
 			panic("cannot absorb: would exceed capacity of " + elemTyp.String())
 		}
 		// one key => array of single values; no keys => single value of type array
-		if len(keys) > 0 {
+		if len(keys) > 0 || a.flatten {
 			elemTyp = elemTyp.Elem()
 		}
 	case reflect.Slice:
 		// one key => slice of values; no keys => single value of type slice
-		if len(keys) > 0 {
+		if len(keys) > 0 || a.flatten {
 			// Ensure an array of correct dimension is allocated
 			cap := count
 			if cap < 0 {
-				cap = 16
+				cap = minSliceCap
+			}
+			if !a.cfg.partial && a.cfg.opColumn == "" {
+				a.setVal.Set(reflect.MakeSlice(elemTyp, 0, cap))
 			}
-			a.setVal.Set(reflect.MakeSlice(elemTyp, 0, cap))
 
 			elemTyp = elemTyp.Elem()
 		}
-	case reflect.Chan:
-		elemTyp = elemTyp.Elem()
+	case reflect.Map:
+		a.flatten = false
+		if a.keyed = a.cfg.planKeyed(elemTyp, keys); a.keyed != nil {
+			// Rows are indexed, or grouped into slices, by key.
+			if a.cfg.opColumn == "" || a.setVal.IsNil() {
+				a.setVal.Set(reflect.MakeMap(elemTyp))
+			}
+			elemTyp = a.keyed.elemType(elemTyp)
+			if v := a.keyed.value; v >= 0 {
+				keys = keys[v : v+1]
+			}
+		} else if count > 1 {
+			panic("cannot absorb multiple values into single-valued type " + elemTyp.String())
+		}
+	case reflect.Chan, reflect.Func:
+		if elemTyp.Kind() == reflect.Chan {
+			elemTyp = elemTyp.Elem()
+		} else {
+			elemTyp = elemTyp.In(0)
+		}
+		if pool := a.cfg.pool; pool != nil {
+			if t := pool.elemType(); t != elemTyp && reflect.PtrTo(t) != elemTyp {
+				panic("cannot absorb " + elemTyp.String() + " with pool of " + t.String())
+			}
+		}
 	default:
+		a.flatten = false
 		if count > 1 {
 			panic("cannot absorb multiple values into single-valued type " + elemTyp.String())
 		}
 	}
 
 	// Reset the index; An absorber could be re-used.
-	a.idx = 0
+	a.idx, a.dropped, a.errs, a.failure = 0, 0, nil, nil
+	if !a.sourceSkips {
+		a.skip = a.cfg.resume
+	}
 
 	if elemTyp.Kind() == reflect.Ptr {
 		// If we ended on a pointer type, dereference it one more time
@@ -135,21 +317,281 @@ func (a *absorberImpl) Open(tag string, count int, keys ...string) {
 		// Else indicate that we DON'T have a pointer, so elements may need to be unwrapped before accepting them
 		a.unwrap = true
 	}
-	a.builder = getBuilder(elemTyp, tag, keys)
+	a.elemType = elemTyp
+	// Envelopes are built by absorbing the row into their Value field.
+	a.envelope = reflect.PtrTo(elemTyp).Implements(enveloperType)
+	if a.envelope {
+		elemTyp = elemTyp.Field(0).Type
+		if elemTyp.Kind() == reflect.Ptr {
+			elemTyp = elemTyp.Elem()
+		}
+	}
+	keys = a.cfg.normalize(keys)
+	override := a.cfg.overrides[elemTyp.String()]
+	override.checkRequired(keys)
+	a.defaults, keys = override.planDefaults(keys)
+	a.builder = getBuilder(a.cfg.registry, elemTyp, a.cfg.tagChain(tag), keys, override, a.cfg.matcher)
+	a.tracksPresence = reflect.PtrTo(elemTyp).Implements(presenceTrackerType)
+	a.validates = reflect.PtrTo(elemTyp).Implements(afterAbsorberType)
+	if (a.cfg.partial || a.cfg.opColumn != "") && a.setVal.Kind() == reflect.Slice && len(keys) > 0 {
+		a.upsert = a.planUpsert(openKeys)
+	}
+	a.planOperations(openKeys)
+	if a.cfg.strict {
+		a.builder.checkMapped(a.cfg)
+	}
+	a.state = lifecycleOpen
+	if a.cfg.workers > 1 && (a.setVal.Kind() == reflect.Chan || a.setVal.Kind() == reflect.Func) {
+		a.parallel = a.startParallel()
+	}
+	a.direct = a.planDirect()
+	if l := a.cfg.logger; l != nil {
+		l.opened = time.Now()
+		l.Debug("absorb: open", "type", a.elemType.String(), "tag", tag, "count", count, "keys", keys, "mapping", a.builder.mapping())
+	}
+	if a.cfg.onOpen != nil {
+		a.cfg.onOpen(openKeys)
+	}
 }
 
 func (a *absorberImpl) Absorb(values ...interface{}) {
+	a.checkOpen()
+	if a.flatten && len(values) != 1 {
+		// Each value is absorbed as its own row.
+		for _, value := range values {
+			a.Absorb(value)
+		}
+		return
+	}
+	if a.skip > 0 {
+		a.skip--
+		return
+	}
+	if a.direct && len(values) == a.width {
+		idx := a.idx
+		a.idx = idx + 1
+		a.absorbDirect(idx, values)
+		return
+	}
+	if a.cfg.onClose != nil {
+		defer a.notePanic()
+	}
+	if a.cfg.collectErrors || a.cfg.onError != nil {
+		defer a.recoverRow(a.idx)
+	}
+	if a.cfg.onRow != nil {
+		a.cfg.onRow(a.idx, values)
+	}
+	values = a.checkArity(values)
+	if a.keep != nil {
+		values = keepColumns(values, a.keep)
+	}
+	if a.cfg.trimSpace || a.cfg.emptyAsNil {
+		values = a.cfg.cleanStrings(values)
+	}
+	a.cfg.spendQuota(values)
+	row := values
+	if a.keyed != nil {
+		values = a.keyed.project(values)
+	}
+	if a.defaults != nil {
+		values = a.defaults.apply(values)
+	}
+	if a.cfg.copyValues {
+		values = copyBytes(values)
+	}
 	idx := a.idx
-	elem := getDst(a.setVal, a.builder.Type, idx)
-	a.builder.absorb(elem, values)
 	a.idx = idx + 1
-	// For channel types only, we need to Send the newly-created value
-	if a.setVal.Kind() == reflect.Chan {
+	if a.opIdx >= 0 && a.operation(row) == Delete {
+		a.remove(idx, values, row)
+		a.checkpoint(idx)
+		return
+	}
+	if a.parallel != nil {
+		a.parallel.submit(idx, values)
+		return
+	}
+	if a.keyed != nil {
+		a.insert(idx, a.build(idx, values), row)
+	} else {
+		a.deliver(idx, a.build(idx, values))
+	}
+	a.checkpoint(idx)
+}
+
+// planDirect reports whether rows may be absorbed with absorbDirect: The destination is
+// a slice or array of elements built from each row's values, and no option transforms,
+// observes, or recovers rows.
+func (a *absorberImpl) planDirect() bool {
+	if kind := a.setVal.Kind(); kind != reflect.Slice && kind != reflect.Array ||
+		a.elemType == a.setVal.Type() || a.elemType == byteType || a.flatten {
+		return false
+	}
+	if a.keyed != nil || a.upsert != nil || a.opIdx >= 0 || a.defaults != nil || a.keep != nil ||
+		a.parallel != nil || a.envelope || a.tracksPresence || a.validates {
+		return false
+	}
+	cfg := a.cfg
+	return cfg.onClose == nil && !cfg.collectErrors && cfg.onError == nil && cfg.onRow == nil &&
+		!cfg.trimSpace && !cfg.emptyAsNil && cfg.quota == nil && !cfg.copyValues &&
+		cfg.finalizer == nil && cfg.checkpointer == nil
+}
+
+// absorbDirect builds the row at idx in place. One deferred call annotates conversion
+// errors with both the key and the row, where build and the builder defer one each.
+func (a *absorberImpl) absorbDirect(idx int, values []interface{}) {
+	key := -1
+	defer a.annotateKey(idx, &key, values)
+	a.builder.fill(getDst(a.setVal, a.elemType, idx-a.dropped), values, a.cfg, nil, &key)
+}
+
+// annotateKey combines the builder's recoverKey with annotate.
+// It must be deferred directly, to recover the panic.
+func (a *absorberImpl) annotateKey(idx int, key *int, values []interface{}) {
+	if p := recover(); p != nil {
+		if *key >= 0 {
+			p = a.builder.conversionError(p, *key, values[*key])
+		}
+		if err, ok := p.(*ConversionError); ok && err.Row == 0 {
+			err.Row = a.cfg.resume + idx + 1
+		}
+		panic(p)
+	}
+}
+
+// build creates or locates the element at idx, and absorbs values into it.
+func (a *absorberImpl) build(idx int, values []interface{}) reflect.Value {
+	defer a.annotate(idx)
+	var elem reflect.Value
+	if a.keyed != nil {
+		elem = reflect.New(a.elemType)
+	} else if pool := a.cfg.pool; pool != nil && (a.setVal.Kind() == reflect.Chan || a.setVal.Kind() == reflect.Func) {
+		elem = pool.get()
+	} else if a.upsert != nil {
+		elem = a.upsert.locate(a.setVal, a.elemType, values, a.cfg)
+		if a.opIdx >= 0 && !a.cfg.partial {
+			// Operations replace the element, rather than updating it.
+			elem.Set(reflect.Zero(elem.Type()))
+		}
+	} else if a.setVal.Kind() == reflect.Slice && a.elemType == byteType {
+		// Rows are concatenated, rather than stored by index.
+		if appendText(a.setVal, values) {
+			return a.setVal
+		}
+		elem = getDst(a.setVal, a.elemType, a.setVal.Len())
+	} else {
+		elem = getDst(a.setVal, a.elemType, idx-a.dropped)
+	}
+	target, env := elem, reflect.Value{}
+	if a.envelope {
+		target, env = a.openEnvelope(elem, idx)
+	}
+	a.builder.absorb(target, values, a.cfg, a.errorHandler(idx))
+	a.recordPresence(target, env, values)
+	if a.cfg.finalizer != nil {
+		a.finalize(target, values)
+	}
+	if a.validates {
+		a.validate(target, idx)
+	}
+	return elem
+}
+
+// insert stores the element at idx in a keyed map destination, under the key from row.
+func (a *absorberImpl) insert(idx int, elem reflect.Value, row []interface{}) {
+	defer a.annotate(idx)
+	a.keyed.insert(a.setVal, elem, row, a.cfg)
+}
+
+// annotate adds the row number to a ConversionError raised for the row at idx.
+// It must be deferred directly, to recover the panic.
+func (a *absorberImpl) annotate(idx int) {
+	if p := recover(); p != nil {
+		if err, ok := p.(*ConversionError); ok && err.Row == 0 {
+			err.Row = a.cfg.resume + idx + 1
+		}
+		panic(p)
+	}
+}
+
+// deliver passes a newly-created element to a channel or callback destination.
+// Other destinations are written in place by build, so there is nothing to do.
+func (a *absorberImpl) deliver(idx int, elem reflect.Value) {
+	switch a.setVal.Kind() {
+	case reflect.Chan:
+		if a.unwrap {
+			a.send(idx, reflect.Indirect(elem))
+		} else {
+			a.send(idx, elem)
+		}
+	case reflect.Func:
 		if a.unwrap {
-			elem = reflect.Indirect(elem)
+			a.setVal.Call([]reflect.Value{reflect.Indirect(elem)})
+		} else {
+			a.setVal.Call([]reflect.Value{elem})
+		}
+	default:
+		return
+	}
+	if a.unwrap && a.cfg.pool != nil {
+		// Value elements were copied as they were delivered, so they may be reused.
+		a.cfg.pool.put(elem)
+	}
+}
+
+// send sends elem to the channel destination, or appends it to the overflow slice if the
+// consumer's context is done, the send times out, or a previous send failed.
+func (a *absorberImpl) send(idx int, elem reflect.Value) {
+	cfg := a.cfg
+	if !a.overflow.IsValid() {
+		if cfg.sendCtx == nil && cfg.sendTimeout <= 0 {
+			a.setVal.Send(elem)
+			return
+		}
+
+		cases := []reflect.SelectCase{{Dir: reflect.SelectSend, Chan: a.setVal, Send: elem}}
+		if cfg.sendCtx != nil {
+			cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(cfg.sendCtx.Done())})
+		}
+		if cfg.sendTimeout > 0 {
+			timer := time.NewTimer(cfg.sendTimeout)
+			defer timer.Stop()
+			cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(timer.C)})
+		}
+		if chosen, _, _ := reflect.Select(cases); chosen == 0 {
+			return
+		}
+		a.overflow = reflect.MakeSlice(reflect.SliceOf(a.setVal.Type().Elem()), 0, 16)
+	}
+	if cfg.logger != nil {
+		cfg.logger.Debug("absorb: row not sent", "row", idx)
+	}
+	a.overflow = reflect.Append(a.overflow, elem)
+}
+
+func (a *absorberImpl) Overflow() interface{} {
+	if !a.overflow.IsValid() {
+		return nil
+	}
+	return a.overflow.Interface()
+}
+
+// copyBytes returns values with each []byte replaced by a copy.
+// The given slice is returned unmodified if it contains no byte slices.
+func copyBytes(values []interface{}) []interface{} {
+	var copied []interface{}
+	for idx, value := range values {
+		if b, ok := value.([]byte); ok {
+			if copied == nil {
+				copied = append([]interface{}(nil), values...)
+			}
+			copied[idx] = append([]byte(nil), b...)
 		}
-		a.setVal.Send(elem)
 	}
+	if copied == nil {
+		return values
+	}
+	return copied
 }
 
 // TODO: make this getDst(into reflect.Value, idx int) reflect.Value
@@ -157,22 +599,25 @@ func (a *absorberImpl) Absorb(values ...interface{}) {
 func getDst(into reflect.Value, eType reflect.Type, idx int) reflect.Value {
 	// Append an element to an output value.
 	switch into.Kind() {
-	case reflect.Chan:
-		// Return new, writable value of channel's type
+	case reflect.Chan, reflect.Func:
+		// Return new, writable value of channel's or callback's type
 		return reflect.New(eType)
 	case reflect.Slice:
-		if into.Type().Elem().Kind() == reflect.Uint8 {
-			// Special Absorb(&[]byte, ...) support
+		if eType == into.Type() {
+			// Without keys, the row's single value is the whole slice.
 			break
 		}
 		if into.Cap() <= idx {
-			// Grow slice by appending a zero value to it
-			into.Set(reflect.Append(into, reflect.Zero(into.Type().Elem())))
-		} else if into.Len() <= idx {
+			growSlice(into, idx+1)
+		}
+		if into.Len() <= idx {
 			into.SetLen(idx + 1)
 		}
 		return into.Index(idx)
 	case reflect.Array:
+		if eType == into.Type() {
+			break
+		}
 		// Arrays have fixed capacity and length, so this panics if out of range
 		return into.Index(idx)
 	case reflect.Ptr:
@@ -186,7 +631,115 @@ func getDst(into reflect.Value, eType reflect.Type, idx int) reflect.Value {
 	return into
 }
 
+// appendText appends a row's single string or byte slice value to a byte slice, so that
+// the rows absorbed into a *[]byte with one key are concatenated. Returns false if it
+// does not apply, so that other values, such as numbers, are absorbed as single bytes.
+func appendText(into reflect.Value, values []interface{}) bool {
+	if len(values) != 1 {
+		return false
+	}
+	var text []byte
+	switch v := values[0].(type) {
+	case string:
+		text = []byte(v)
+	case []byte:
+		text = v
+	default:
+		return false
+	}
+	if into.Cap()-into.Len() < len(text) {
+		growSlice(into, into.Len()+len(text))
+	}
+	n := into.Len()
+	into.SetLen(n + len(text))
+	reflect.Copy(into.Slice(n, n+len(text)), reflect.ValueOf(text))
+	return true
+}
+
+var byteType = reflect.TypeOf(byte(0))
+
+// minSliceCap is the capacity allocated for slice destinations when the row count is unknown.
+const minSliceCap = 16
+
+// trim fits a slice or array destination to the rows absorbed since Open. A slice's
+// unused capacity is released if more than a quarter of it is unused, as when the count
+// passed to Open overestimated the rows, and the elements of an array past the last row
+// are zeroed. Destinations updated in place by Partial or Operations keep their length.
+func (a *absorberImpl) trim() {
+	kind := a.setVal.Kind()
+	if kind != reflect.Slice && kind != reflect.Array || a.elemType == a.setVal.Type() || a.elemType == byteType {
+		// The destination is a single value, or holds concatenated text.
+		return
+	}
+	if !a.cfg.partial && a.cfg.opColumn == "" {
+		if rows := a.idx - a.dropped; rows < a.setVal.Len() {
+			if kind == reflect.Slice {
+				a.setVal.SetLen(rows)
+			} else {
+				for i := rows; i < a.setVal.Len(); i++ {
+					a.setVal.Index(i).Set(reflect.Zero(a.setVal.Type().Elem()))
+				}
+			}
+		}
+	}
+	if n := a.setVal.Len(); kind == reflect.Slice && a.setVal.Cap()-n > a.setVal.Cap()/4 {
+		trimmed := reflect.MakeSlice(a.setVal.Type(), n, n)
+		reflect.Copy(trimmed, a.setVal)
+		a.setVal.Set(trimmed)
+	}
+}
+
+// growSlice reallocates into with room for at least n elements, doubling its capacity so
+// that absorbing rows of unknown count takes amortized constant time per row.
+func growSlice(into reflect.Value, n int) {
+	cap := 2 * into.Cap()
+	if cap < minSliceCap {
+		cap = minSliceCap
+	}
+	if cap < n {
+		cap = n
+	}
+	grown := reflect.MakeSlice(into.Type(), into.Len(), cap)
+	reflect.Copy(grown, into)
+	into.Set(grown)
+}
+
+func (a *absorberImpl) Reset(dst interface{}) {
+	if a.state == lifecycleOpen {
+		panic(ErrAlreadyOpen)
+	}
+	a.dst, a.setVal = dst, destination(dst)
+	a.overflow = reflect.Value{}
+	a.sourceSkips = false
+	a.state = lifecycleNew
+}
+
 func (a *absorberImpl) Close() {
+	a.checkOpen()
+	if a.cfg.onClose != nil {
+		defer a.closed()
+	}
+	if a.parallel != nil {
+		run := a.parallel
+		a.parallel = nil
+		// Wait for every row to be delivered, and re-raise any panic from a worker.
+		run.finish()
+		if run.order == nil && a.idx > 0 {
+			a.checkpoint(a.idx - 1)
+		}
+	}
+	if l := a.cfg.logger; l != nil {
+		overflow := 0
+		if a.overflow.IsValid() {
+			overflow = a.overflow.Len()
+		}
+		l.Debug("absorb: close", "type", a.elemType.String(), "rows", a.idx, "overflow", overflow, "elapsed", time.Since(l.opened))
+	}
+	if a.upsert != nil {
+		a.upsert.compact(a.setVal)
+	}
+	a.trim()
 	// Not strictly necessary, but the Open/Close pattern is clear and useful.
 	a.builder = nil
+	a.state = lifecycleClosed
 }
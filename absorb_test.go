@@ -2,8 +2,10 @@ package absorb_test
 
 import (
 	"bytes"
+	"context"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/jyopp/absorb"
 )
@@ -388,3 +390,425 @@ func TestPanics(t *testing.T) {
 		_ = absorb.New(rcvOnly)
 	})
 }
+
+// Require fn to panic with err in a subtest named "name"
+func suberror(t *testing.T, name string, err error, fn func()) {
+	t.Run(name, func(t *testing.T) {
+		defer func() {
+			if r := recover(); r != err {
+				t.Fatalf("Expected panic %v for %s, got %v", err, name, r)
+			}
+		}()
+
+		fn()
+	})
+}
+
+func TestLifecycle(t *testing.T) {
+	suberror(t, "Absorb Before Open", absorb.ErrNotOpen, func() {
+		var dst int
+		absorb.New(&dst).Absorb(1)
+	})
+	suberror(t, "Close Before Open", absorb.ErrNotOpen, func() {
+		var dst int
+		absorb.New(&dst).Close()
+	})
+	suberror(t, "Double Open", absorb.ErrAlreadyOpen, func() {
+		var dst []int
+		abs := absorb.New(&dst)
+		abs.Open("", 1, "int")
+		abs.Open("", 1, "int")
+	})
+	suberror(t, "Absorb After Close", absorb.ErrClosed, func() {
+		var dst []int
+		abs := absorb.New(&dst)
+		abs.Open("", 1, "int")
+		abs.Close()
+		abs.Absorb(1)
+	})
+	suberror(t, "Double Close", absorb.ErrClosed, func() {
+		var dst []int
+		abs := absorb.New(&dst)
+		abs.Open("", 1, "int")
+		abs.Close()
+		abs.Close()
+	})
+
+	// An absorber may be reopened after it is closed.
+	var dst []int
+	abs := absorb.New(&dst)
+	for _, expect := range []int{1, 2} {
+		abs.Open("", 1, "int")
+		abs.Absorb(expect)
+		abs.Close()
+		if len(dst) != 1 || dst[0] != expect {
+			t.Fatal("Expected", []int{expect}, "but got", dst)
+		}
+	}
+}
+
+func TestChannelAliasing(t *testing.T) {
+	type Row struct {
+		ID   int
+		Blob []byte
+	}
+
+	// Emits rows reusing a single buffer, as some database drivers do.
+	emit := func(abs absorb.Absorber) {
+		abs.Open("", 2, "ID", "Blob")
+		defer abs.Close()
+		buf := []byte{0}
+		for i := 1; i <= 2; i++ {
+			buf[0] = byte(i)
+			abs.Absorb(i, buf)
+		}
+	}
+
+	ch := make(chan *Row, 2)
+	emit(absorb.New(ch))
+	first, second := <-ch, <-ch
+	if first == second || first.ID != 1 || second.ID != 2 {
+		t.Fatalf("Expected distinct elements per send, got %+v and %+v", first, second)
+	}
+	if first.Blob[0] != 2 {
+		t.Fatal("Expected default handoff to alias the source's buffer")
+	}
+
+	emit(absorb.New(ch, absorb.CopyOnSend()))
+	first, second = <-ch, <-ch
+	if first.Blob[0] != 1 || second.Blob[0] != 2 {
+		t.Fatalf("Expected CopyOnSend to detach buffers, got %v and %v", first.Blob, second.Blob)
+	}
+}
+
+func TestSendTimeoutOverflow(t *testing.T) {
+	ch := make(chan *TestDst, 1)
+	abs := absorb.New(ch, absorb.SendTimeout(10*time.Millisecond))
+	if err := testSource.Emit(testSource{i: 3}, abs); err != nil {
+		t.Fatal(err)
+	}
+
+	// The buffered row was sent, and the consumer never received the rest.
+	if sent := <-ch; sent.Actual != 1 {
+		t.Fatalf("Expected first row to be sent, got %+v", sent)
+	}
+	overflow, ok := abs.(absorb.OverflowAbsorber).Overflow().([]*TestDst)
+	if !ok || len(overflow) != 2 || overflow[0].Actual != 2 || overflow[1].Actual != 3 {
+		t.Fatalf("Expected two overflow rows, got %+v", overflow)
+	}
+}
+
+func TestSendContextOverflow(t *testing.T) {
+	// The consumer has already gone away.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	ch := make(chan TestDst)
+	abs := absorb.New(ch, absorb.SendContext(ctx))
+	if err := testSource.Emit(testSource{i: 3}, abs); err != nil {
+		t.Fatal(err)
+	}
+	if overflow := abs.(absorb.OverflowAbsorber).Overflow().([]TestDst); len(overflow) != 3 {
+		t.Fatalf("Expected every row to overflow after cancellation, got %+v", overflow)
+	}
+}
+
+func TestByteDestinations(t *testing.T) {
+	// Without keys, a single value is the whole slice or array.
+	var blob []byte
+	abs := absorb.New(&blob)
+	abs.Open("", 1)
+	abs.Absorb("whole")
+	abs.Close()
+	if string(blob) != "whole" {
+		t.Fatalf("Expected whole, got %q", blob)
+	}
+	var hash [4]byte
+	abs = absorb.New(&hash)
+	abs.Open("", 1)
+	abs.Absorb([]byte{1, 2, 3, 4})
+	abs.Close()
+	if hash != [4]byte{1, 2, 3, 4} {
+		t.Fatalf("Unexpected array %v", hash)
+	}
+	subpanic(t, "Array Length", func() {
+		abs := absorb.New(&hash)
+		abs.Open("", 1)
+		abs.Absorb("abc")
+	})
+	subpanic(t, "Second Value", func() {
+		abs := absorb.New(&blob)
+		abs.Open("", 2)
+		abs.Absorb("a")
+		abs.Absorb("b")
+	})
+
+	// With one key, rows are concatenated, and numbers are single bytes.
+	abs = absorb.New(&blob)
+	abs.Open("", -1, "chunk")
+	abs.Absorb("con")
+	abs.Absorb([]byte("cat"))
+	abs.Absorb('!')
+	abs.Close()
+	if string(blob) != "concat!" {
+		t.Fatalf("Expected concat!, got %q", blob)
+	}
+
+	// A slice of byte slices receives one element per row.
+	var chunks [][]byte
+	abs = absorb.New(&chunks)
+	abs.Open("", 2, "chunk")
+	abs.Absorb("one")
+	abs.Absorb([]byte("two"))
+	abs.Close()
+	if expect := [][]byte{[]byte("one"), []byte("two")}; !reflect.DeepEqual(chunks, expect) {
+		t.Fatalf("Expected %q, got %q", expect, chunks)
+	}
+}
+
+func TestWholeStruct(t *testing.T) {
+	var dst []TestDst
+
+	abs := absorb.New(&dst)
+	abs.Open("", 2, "value")
+	defer abs.Close()
+
+	// A single value of the element type is assigned whole, by value or by reference.
+	expect := []TestDst{{Name: "one", Actual: 1}, {Name: "two", Actual: 2}}
+	abs.Absorb(expect[0])
+	abs.Absorb(&expect[1])
+	if !reflect.DeepEqual(dst, expect) {
+		t.Fatal("Expected", expect, "but got", dst)
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	var ids []int64
+	abs := absorb.New(&ids, absorb.Flatten())
+	abs.Open("", -1)
+	abs.Absorb(int64(1), 2, uint8(3))
+	abs.Absorb(int64(4))
+	abs.Absorb()
+	abs.Close()
+	if expect := []int64{1, 2, 3, 4}; !reflect.DeepEqual(ids, expect) {
+		t.Fatal("Expected", expect, "but got", ids)
+	}
+
+	var names [3]string
+	abs = absorb.New(&names, absorb.Flatten())
+	abs.Open("", 1)
+	abs.Absorb("a", "b", "c")
+	abs.Close()
+	if names != [3]string{"a", "b", "c"} {
+		t.Fatal("Unexpected array", names)
+	}
+
+	ch := make(chan *string, 2)
+	abs = absorb.New(ch, absorb.Flatten())
+	abs.Open("", 1)
+	abs.Absorb("x", "y")
+	abs.Close()
+	if x, y := <-ch, <-ch; *x != "x" || *y != "y" {
+		t.Fatal("Unexpected values", *x, *y)
+	}
+
+	// Rows with keys, and single-valued destinations, are absorbed as before.
+	var dst []TestDst
+	if err := absorb.Absorb(&dst, testSource{i: 2}, absorb.Flatten()); err != nil || len(dst) != 2 {
+		t.Fatal("Unexpected result", dst, err)
+	}
+	subpanic(t, "Single value", func() {
+		var dst int
+		abs := absorb.New(&dst, absorb.Flatten())
+		abs.Open("", 1)
+		abs.Absorb(1, 3)
+	})
+}
+
+func TestStructSliceGrowth(t *testing.T) {
+	src := testSource{i: 100000}
+	var dst []TestDst
+
+	if err := absorb.Absorb(&dst, src); err != nil {
+		t.Fatal(err)
+	}
+	if len(dst) != src.i {
+		t.Fatalf("Expected %d structs, got %d", src.i, len(dst))
+	}
+	if last := dst[len(dst)-1]; last.Actual != src.i {
+		t.Fatalf("Expected last element %d, got %+v", src.i, last)
+	}
+	if cap(dst) >= 2*src.i {
+		t.Fatalf("Expected capacity under %d, got %d", 2*src.i, cap(dst))
+	}
+}
+
+// countedSource emits the same rows as testSource, but passes their count to Open.
+type countedSource struct{ testSource }
+
+func (cs countedSource) Emit(into absorb.Absorber) error {
+	into.Open("test", cs.i, "Name", "Aliased")
+	defer into.Close()
+
+	for i := 0; i < cs.i; i++ {
+		into.Absorb("test", i+1)
+	}
+	return nil
+}
+
+// BenchmarkStructSlice compares growing a slice of unknown length with allocating it
+// once for a known count. With reflect.Append's growth, Unknown took about 66ms and
+// 21.8MB per op; doubling brings it to about 47ms and 12.4MB.
+func BenchmarkStructSlice(b *testing.B) {
+	for _, bm := range []struct {
+		name string
+		src  absorb.Absorbable
+	}{
+		{"Unknown", testSource{i: 100000}},
+		{"Known", countedSource{testSource{i: 100000}}},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for n := 0; n < b.N; n++ {
+				var dst []TestDst
+				if err := absorb.Absorb(&dst, bm.src); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// fieldsSource emits i rows, which set all three fields of TestDst.
+type fieldsSource struct{ i int }
+
+func (fs fieldsSource) Emit(into absorb.Absorber) error {
+	into.Open("test", fs.i, "Name", "Aliased", "Unused")
+	defer into.Close()
+
+	for i := 0; i < fs.i; i++ {
+		into.Absorb("test", i+1, i)
+	}
+	return nil
+}
+
+// BenchmarkStructFields measures the per-row cost of absorbing into a slice of structs
+// without options.
+func BenchmarkStructFields(b *testing.B) {
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		var dst []TestDst
+		if err := absorb.Absorb(&dst, fieldsSource{i: 1000}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestReset(t *testing.T) {
+	var first, second []TestDst
+	abs := absorb.New(&first).(absorb.ResettableAbsorber)
+	if err := (testSource{i: 2}).Emit(abs); err != nil {
+		t.Fatal(err)
+	}
+	// Opening again replaces the destination's contents.
+	if err := (testSource{i: 3}).Emit(abs); err != nil {
+		t.Fatal(err)
+	}
+	if len(first) != 3 {
+		t.Fatalf("Expected 3 structs, got %d", len(first))
+	}
+
+	abs.Reset(&second)
+	if err := (testSource{i: 1}).Emit(abs); err != nil {
+		t.Fatal(err)
+	}
+	if len(first) != 3 || len(second) != 1 || second[0].Actual != 1 {
+		t.Fatalf("Unexpected results after Reset: %+v, %+v", first, second)
+	}
+
+	abs.Open("test", -1, "Name")
+	subpanic(t, "Reset while open", func() {
+		abs.Reset(&first)
+	})
+	subpanic(t, "Reset to invalid destination", func() {
+		absorb.New(&first).(absorb.ResettableAbsorber).Reset(first)
+	})
+}
+
+type EmbeddedBase struct {
+	ID      int
+	Created string `test:"created_at"`
+}
+
+type EmbeddedAudit struct {
+	Owner string
+}
+
+type embeddingDst struct {
+	*EmbeddedBase
+	*EmbeddedAudit `test:"audit"`
+	Name           string
+	Owner          string
+}
+
+func TestEmbeddedFields(t *testing.T) {
+	var dst []embeddingDst
+	abs := absorb.New(&dst)
+	abs.Open("test", 2, "id", "created_at", "Name", "Owner")
+	abs.Absorb(1, "today", "a", "me")
+	abs.Absorb(2, "yesterday", "b", "you")
+	abs.Close()
+
+	// Promoted fields are reached by allocating the nil embedded pointer.
+	if len(dst) != 2 || dst[0].EmbeddedBase == nil || dst[1].ID != 2 || dst[1].Created != "yesterday" {
+		t.Fatalf("Unexpected elements %+v", dst)
+	}
+	// Fields of a tagged embedded struct aren't promoted, and outer fields come first.
+	if dst[0].EmbeddedAudit != nil || dst[0].Owner != "me" || dst[0].Name != "a" {
+		t.Fatalf("Unexpected element %+v", dst[0])
+	}
+
+	// Pointers to unexported embedded structs can't be allocated.
+	type hidden struct{ *embeddedHidden }
+	subpanic(t, "unexported embedded pointer", func() {
+		var dst []hidden
+		absorb.Absorb(&dst, testSource{i: 1})
+	})
+}
+
+type embeddedHidden struct {
+	Name string
+}
+
+func TestCapacityTrim(t *testing.T) {
+	// An overestimated count leaves no unused capacity behind.
+	var dst []TestDst
+	abs := absorb.New(&dst)
+	abs.Open("test", 1000, "Name", "Aliased")
+	if cap(dst) != 1000 {
+		t.Fatalf("Expected capacity for 1000 rows, got %d", cap(dst))
+	}
+	abs.Absorb("a", 1)
+	abs.Absorb("b", 2)
+	abs.Close()
+	if len(dst) != 2 || cap(dst) != 2 {
+		t.Fatalf("Expected 2 rows with no spare capacity, got len %d, cap %d", len(dst), cap(dst))
+	}
+
+	// Slices grow geometrically when the count is unknown.
+	if err := absorb.Absorb(&dst, testSource{i: 100}); err != nil {
+		t.Fatal(err)
+	}
+	if len(dst) != 100 || cap(dst) > 134 {
+		t.Fatalf("Expected 100 rows without excess capacity, got len %d, cap %d", len(dst), cap(dst))
+	}
+
+	// Elements of an array past the last row are cleared.
+	arr := [3]TestDst{{Name: "stale"}, {Name: "stale"}, {Name: "stale"}}
+	if err := absorb.Absorb(&arr, testSource{i: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if arr[0].Actual != 1 || arr[1] != (TestDst{}) || arr[2] != (TestDst{}) {
+		t.Fatalf("Unexpected array %+v", arr)
+	}
+}
@@ -0,0 +1,211 @@
+package absorb
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+)
+
+// Aggregate returns an Absorber that computes running statistics for the given columns in
+// a single pass, without keeping any rows: the count, sum, minimum, maximum, and mean of
+// each column's values. If groupBy is not empty, rows are grouped by the value of that
+// column, and statistics are kept for each group. Results are available once the
+// Absorber is closed.
+//
+// Values may be numbers, math/big values, or numeric strings or byte slices; nil values
+// and empty strings are ignored. Other values panic with a *ConversionError.
+//
+// Example:
+//
+//	agg := absorb.Aggregate("region", "amount")
+//	err := src.Emit(agg)
+//	for _, group := range agg.Results() {
+//		fmt.Println(group.Key, group.Columns["amount"].Mean())
+//	}
+func Aggregate(groupBy string, columns ...string) *Aggregator {
+	return &Aggregator{groupBy: groupBy, columns: columns}
+}
+
+// Stats holds running statistics for the values of a column.
+type Stats struct {
+	// Count is the number of values, not counting nil values.
+	Count int
+	Sum   float64
+	// Min and Max are the smallest and largest values, or 0 if there were none.
+	Min, Max float64
+}
+
+// Mean returns the average of the values, or NaN if there were none.
+func (s Stats) Mean() float64 {
+	if s.Count == 0 {
+		return math.NaN()
+	}
+	return s.Sum / float64(s.Count)
+}
+
+func (s *Stats) add(v float64) {
+	if s.Count == 0 || v < s.Min {
+		s.Min = v
+	}
+	if s.Count == 0 || v > s.Max {
+		s.Max = v
+	}
+	s.Count++
+	s.Sum += v
+}
+
+// Group holds the statistics for the rows sharing a value of the group-by column.
+type Group struct {
+	// Key is the group-by column's value, or nil if rows are not grouped. Byte slices are
+	// converted to strings.
+	Key interface{}
+	// Rows is the number of rows in the group.
+	Rows int
+	// Columns holds the statistics of each aggregated column.
+	Columns map[string]Stats
+}
+
+// Aggregator computes statistics for the rows it absorbs. See Aggregate.
+type Aggregator struct {
+	groupBy string
+	columns []string
+	// indexes are the positions of the group-by column, or -1, and of each aggregated
+	// column in the keys given to Open.
+	groupIdx int
+	indexes  []int
+	// width is the number of keys given to Open, which each row must have.
+	width  int
+	groups map[interface{}]*Group
+	order  []interface{}
+	open   bool
+	closed bool
+}
+
+func (a *Aggregator) Open(tag string, count int, keys ...string) {
+	if a.open {
+		panic(ErrAlreadyOpen)
+	}
+	a.groupIdx, a.width = -1, len(keys)
+	if a.groupBy != "" {
+		a.groupIdx = aggregateColumn(keys, a.groupBy)
+	}
+	a.indexes = make([]int, len(a.columns))
+	for i, column := range a.columns {
+		a.indexes[i] = aggregateColumn(keys, column)
+	}
+	if a.groups == nil {
+		a.groups = make(map[interface{}]*Group)
+	}
+	a.open = true
+}
+
+func (a *Aggregator) Absorb(values ...interface{}) {
+	if !a.open {
+		panic(ErrNotOpen)
+	}
+	if len(values) != a.width {
+		panic(fmt.Errorf("%w: %d values for %d keys", ErrArity, len(values), a.width))
+	}
+	var key interface{}
+	if a.groupIdx >= 0 {
+		key = plainValue(values[a.groupIdx])
+		if b, ok := key.([]byte); ok {
+			key = string(b)
+		}
+		if key != nil && !reflect.TypeOf(key).Comparable() {
+			panic(fmt.Sprintf("cannot group rows by %s values of %q", reflect.TypeOf(key), a.groupBy))
+		}
+	}
+	group := a.groups[key]
+	if group == nil {
+		group = &Group{Key: key, Columns: make(map[string]Stats, len(a.columns))}
+		a.groups[key] = group
+		a.order = append(a.order, key)
+	}
+	group.Rows++
+	for i, column := range a.columns {
+		if v, ok := aggregateValue(column, values[a.indexes[i]]); ok {
+			stats := group.Columns[column]
+			stats.add(v)
+			group.Columns[column] = stats
+		}
+	}
+}
+
+// Close completes the statistics. Rows from several calls to Open are aggregated together.
+func (a *Aggregator) Close() {
+	if !a.open {
+		panic(ErrNotOpen)
+	}
+	a.open, a.closed = false, true
+}
+
+// Results returns the statistics for each group, in the order each group was first seen,
+// or for all rows as a single group if rows are not grouped. Panics with ErrNotOpen if the
+// Aggregator has not been closed.
+func (a *Aggregator) Results() []Group {
+	if !a.closed || a.open {
+		panic(ErrNotOpen)
+	}
+	results := make([]Group, len(a.order))
+	for idx, key := range a.order {
+		group := *a.groups[key]
+		group.Columns = make(map[string]Stats, len(a.columns))
+		for column, stats := range a.groups[key].Columns {
+			group.Columns[column] = stats
+		}
+		results[idx] = group
+	}
+	return results
+}
+
+// aggregateColumn returns the index of column in keys, or panics with ErrMissingKey.
+func aggregateColumn(keys []string, column string) int {
+	for idx, key := range keys {
+		if key == column {
+			return idx
+		}
+	}
+	panic(fmt.Errorf("%w: cannot aggregate column %q", ErrMissingKey, column))
+}
+
+// plainValue unwraps a value passed as a reflect.Value, or implementing driver.Valuer.
+func plainValue(value interface{}) interface{} {
+	if v, ok := value.(reflect.Value); ok {
+		value = valueOf(v)
+	}
+	if valuer, ok := value.(driver.Valuer); ok {
+		if v, err := valuer.Value(); err == nil {
+			value = v
+		}
+	}
+	return value
+}
+
+// aggregateValue returns value as a float64, or false if it is nil or an empty string.
+func aggregateValue(column string, value interface{}) (float64, bool) {
+	value = plainValue(value)
+	if value == nil {
+		return 0, false
+	}
+	text, ok := bigText(reflect.ValueOf(value))
+	if ok && text == "" {
+		return 0, false
+	}
+	if !ok {
+		panic(&ConversionError{Key: column, Src: reflect.TypeOf(value), Dst: float64Type, Err: fmt.Errorf("%T is not a number", value)})
+	}
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		// Rationals are written as fractions, such as "1/3".
+		r, isRat := new(big.Rat).SetString(text)
+		if !isRat {
+			panic(&ConversionError{Key: column, Src: reflect.TypeOf(value), Dst: float64Type, Err: err})
+		}
+		f, _ = r.Float64()
+	}
+	return f, true
+}
@@ -0,0 +1,70 @@
+package absorb_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/jyopp/absorb"
+)
+
+func TestAggregate(t *testing.T) {
+	src := messySource{
+		{"a", 1},
+		{[]byte("b"), "2.5"},
+		{"a", nil},
+		{"a", uint8(5)},
+		{"b", ""},
+	}
+	agg := absorb.Aggregate("Name", "Aliased")
+	subpanic(t, "results before Close", func() {
+		agg.Results()
+	})
+	if err := src.Emit(agg); err != nil {
+		t.Fatal(err)
+	}
+	results := agg.Results()
+	if len(results) != 2 || results[0].Key != "a" || results[1].Key != "b" {
+		t.Fatalf("Unexpected groups %+v", results)
+	}
+	a, b := results[0], results[1]
+	if stats := a.Columns["Aliased"]; a.Rows != 3 || stats != (absorb.Stats{Count: 2, Sum: 6, Min: 1, Max: 5}) || stats.Mean() != 3 {
+		t.Fatalf("Unexpected group %+v", a)
+	}
+	if stats := b.Columns["Aliased"]; b.Rows != 2 || stats.Count != 1 || stats.Mean() != 2.5 {
+		t.Fatalf("Unexpected group %+v", b)
+	}
+
+	// Without a group-by column, all rows form one group.
+	total := absorb.Aggregate("", "Aliased")
+	if err := src.Emit(total); err != nil {
+		t.Fatal(err)
+	}
+	if results := total.Results(); len(results) != 1 || results[0].Key != nil || results[0].Columns["Aliased"].Sum != 8.5 {
+		t.Fatalf("Unexpected results %+v", results)
+	}
+	if mean := (absorb.Stats{}).Mean(); !math.IsNaN(mean) {
+		t.Fatalf("Expected NaN mean without values, got %v", mean)
+	}
+
+	subpanic(t, "missing column", func() {
+		src.Emit(absorb.Aggregate("", "Missing"))
+	})
+	func() {
+		defer func() {
+			if err, _ := recover().(error); !errors.Is(err, absorb.ErrArity) {
+				t.Fatalf("Expected ErrArity for a short row, got %v", err)
+			}
+		}()
+		messySource{{"a"}}.Emit(absorb.Aggregate("Name", "Aliased"))
+	}()
+	func() {
+		defer func() {
+			var convErr *absorb.ConversionError
+			if err, _ := recover().(error); !errors.As(err, &convErr) || convErr.Key != "Aliased" {
+				t.Fatalf("Expected a conversion error, got %v", err)
+			}
+		}()
+		messySource{{"a", "many"}}.Emit(absorb.Aggregate("", "Aliased"))
+	}()
+}
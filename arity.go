@@ -0,0 +1,44 @@
+package absorb
+
+import "fmt"
+
+// ArityPolicy determines what an Absorber does with a row that has more or fewer values
+// than the keys passed to Open. Policies may be combined, such as ArityTruncate|ArityPad.
+type ArityPolicy int
+
+// ArityError panics with an error wrapping ErrArity. This is the default.
+const ArityError ArityPolicy = 0
+
+const (
+	// ArityTruncate discards values beyond the number of keys.
+	ArityTruncate ArityPolicy = 1 << iota
+	// ArityPad absorbs missing values at the end of a row as nil.
+	ArityPad
+)
+
+// Arity sets the policy for rows with the wrong number of values. It does not apply when
+// Open is called without keys.
+func Arity(policy ArityPolicy) Option {
+	return func(c *config) {
+		c.arity = policy
+	}
+}
+
+// checkArity returns values adjusted to the number of keys passed to Open, according to
+// the arity policy. The given slice is not modified.
+func (a *absorberImpl) checkArity(values []interface{}) []interface{} {
+	width := a.width
+	if width == 0 || len(values) == width {
+		return values
+	}
+	policy := a.cfg.arity
+	switch {
+	case len(values) > width && policy&ArityTruncate != 0:
+		return values[:width]
+	case len(values) < width && policy&ArityPad != 0:
+		padded := make([]interface{}, width)
+		copy(padded, values)
+		return padded
+	}
+	panic(fmt.Errorf("%w: row %d has %d values for %d keys", ErrArity, a.cfg.resume+a.idx+1, len(values), width))
+}
@@ -0,0 +1,49 @@
+package absorb_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jyopp/absorb"
+)
+
+func TestArity(t *testing.T) {
+	var dst []TestDst
+	abs := absorb.New(&dst, absorb.Arity(absorb.ArityTruncate|absorb.ArityPad))
+	abs.Open("test", -1, "Name", "Aliased")
+	abs.Absorb("long", 1, "extra")
+	abs.Absorb("short")
+	abs.Close()
+	expect := []TestDst{{Name: "long", Actual: 1}, {Name: "short"}}
+	if len(dst) != 2 || dst[0] != expect[0] || dst[1] != expect[1] {
+		t.Fatalf("Expected %+v, got %+v", expect, dst)
+	}
+
+	t.Run("Error", func(t *testing.T) {
+		defer func() {
+			err, _ := recover().(error)
+			if !errors.Is(err, absorb.ErrArity) {
+				t.Fatal("Expected ErrArity, got", err)
+			}
+			if !strings.Contains(err.Error(), "row 2 has 1 values for 2 keys") {
+				t.Fatal("Expected row number in error, got", err)
+			}
+		}()
+		abs := absorb.New(&dst)
+		abs.Open("test", -1, "Name", "Aliased")
+		abs.Absorb("ok", 1)
+		abs.Absorb("short")
+	})
+
+	t.Run("Truncate only", func(t *testing.T) {
+		defer func() {
+			if err, _ := recover().(error); !errors.Is(err, absorb.ErrArity) {
+				t.Fatal("Expected ErrArity, got", err)
+			}
+		}()
+		abs := absorb.New(&dst, absorb.Arity(absorb.ArityTruncate))
+		abs.Open("test", -1, "Name", "Aliased")
+		abs.Absorb("short")
+	})
+}
@@ -0,0 +1,41 @@
+package absorb
+
+import "reflect"
+
+// Go absorbs src into dst on a new goroutine, and returns a function that waits for it to
+// finish and returns its error. A panic while absorbing, such as an impossible
+// conversion, is returned as an error rather than crashing the program. Like New, Go
+// panics immediately if dst is not a valid destination.
+//
+// If dst is a channel, Go closes it once every row has been sent, so that it can be
+// ranged over; Call wait after the range ends. Otherwise, dst must not be read until
+// wait returns. The wait function may be called any number of times, from any goroutine.
+//
+// Example:
+//
+//	ch := make(chan *Person)
+//	wait := absorb.Go(ch, reader)
+//	for person := range ch {
+//		fmt.Println(person.Name)
+//	}
+//	if err := wait(); err != nil {
+//		return err
+//	}
+func Go(dst interface{}, src Absorbable, opts ...Option) (wait func() error) {
+	a := New(dst, opts...).(*absorberImpl)
+	var err error
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if a.setVal.Kind() == reflect.Chan {
+			defer a.setVal.Close()
+		}
+		defer recoverError(&err)
+		err = a.emit(src)
+	}()
+
+	return func() error {
+		<-done
+		return err
+	}
+}
@@ -0,0 +1,97 @@
+package absorb_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jyopp/absorb"
+)
+
+// These tests define which Absorber methods may be called from which goroutines, and are
+// meant to be run with the race detector.
+
+func TestGoChannel(t *testing.T) {
+	ch := make(chan *TestDst)
+	wait := absorb.Go(ch, repeatSource{1, 2, 3})
+	total := 0
+	for dst := range ch {
+		total += dst.Actual
+	}
+	if err := wait(); err != nil || total != 6 {
+		t.Fatalf("Expected a total of 6, got %d (%v)", total, err)
+	}
+	// Waiting again returns the same result.
+	if err := wait(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGoSlice(t *testing.T) {
+	var dst []TestDst
+	wait := absorb.Go(&dst, repeatSource{1, 2, 3})
+	if err := wait(); err != nil || len(dst) != 3 || dst[2].Actual != 3 {
+		t.Fatalf("Unexpected result %+v (%v)", dst, err)
+	}
+}
+
+func TestGoErrors(t *testing.T) {
+	errSource := errors.New("source failed")
+	ch := make(chan TestDst)
+	wait := absorb.Go(ch, failingSource{errSource})
+	for range ch {
+		t.Fatal("Expected no rows")
+	}
+	if err := wait(); !errors.Is(err, errSource) {
+		t.Fatalf("Expected the source's error, got %v", err)
+	}
+
+	// Panics while absorbing are returned as errors.
+	var nums []int
+	wait = absorb.Go(&nums, keysSource{"a", "b"})
+	if err := wait(); err == nil {
+		t.Fatal("Expected an error absorbing two keys into []int")
+	}
+
+	subpanic(t, "Invalid destination", func() {
+		absorb.Go(TestDst{}, repeatSource{1})
+	})
+}
+
+func TestGoroutineHandoff(t *testing.T) {
+	// Each call may be made on a different goroutine, as long as it happens after the last.
+	var dst []TestDst
+	abs := absorb.New(&dst)
+	for _, step := range []func(){
+		func() { abs.Open("", 2, "Name", "Aliased") },
+		func() { abs.Absorb("a", 1) },
+		func() { abs.Absorb("b", 2) },
+		abs.Close,
+	} {
+		done := make(chan struct{})
+		go func(step func()) {
+			defer close(done)
+			step()
+		}(step)
+		<-done
+	}
+	if len(dst) != 2 || dst[1].Name != "b" {
+		t.Fatalf("Unexpected result %+v", dst)
+	}
+}
+
+func TestCallbackGoroutine(t *testing.T) {
+	// Callbacks run on the goroutine calling Absorb, and may send results elsewhere.
+	results := make(chan string, 3)
+	wait := absorb.Go(func(dst TestDst) { results <- dst.Name }, repeatSource{1, 2, 3})
+	if err := wait(); err != nil {
+		t.Fatal(err)
+	}
+	close(results)
+	count := 0
+	for range results {
+		count++
+	}
+	if count != 3 {
+		t.Fatalf("Expected 3 callbacks, got %d", count)
+	}
+}
@@ -0,0 +1,145 @@
+package absorb
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	bigIntType   = reflect.TypeOf(big.Int{})
+	bigFloatType = reflect.TypeOf(big.Float{})
+	bigRatType   = reflect.TypeOf(big.Rat{})
+)
+
+// convertBig sets a big.Int, big.Float, or big.Rat dst from a string, []byte, number, or
+// other math/big value, without passing through a float64. Returns false if it does not
+// apply. Panics if the value cannot be represented, such as 1.5 in a big.Int.
+//
+// Floats are converted from their shortest decimal representation, so that 0.1 becomes
+// the big.Rat 1/10. A big.Float dst keeps its precision if it has one, or uses 64 bits.
+func convertBig(dst, src reflect.Value, dstType reflect.Type) bool {
+	if dstType != bigIntType && dstType != bigFloatType && dstType != bigRatType {
+		return false
+	}
+	text, ok := bigText(src)
+	if !ok {
+		return false
+	}
+
+	ok = false
+	switch z := dst.Addr().Interface().(type) {
+	case *big.Int:
+		_, ok = z.SetString(text, 10)
+		if !ok {
+			// Accept integral values written as decimals, such as "100.00" or 1e3.
+			if r, isRat := new(big.Rat).SetString(text); isRat && r.IsInt() {
+				z.Set(r.Num())
+				ok = true
+			}
+		}
+	case *big.Float:
+		if strings.Contains(text, "/") {
+			var r *big.Rat
+			if r, ok = new(big.Rat).SetString(text); ok {
+				z.SetRat(r)
+			}
+		} else {
+			_, ok = z.SetString(text)
+		}
+	case *big.Rat:
+		_, ok = z.SetString(text)
+	}
+	if !ok {
+		panic(&ConversionError{Src: src.Type(), Dst: dstType, Err: fmt.Errorf("invalid number %q", text)})
+	}
+	return true
+}
+
+// bigText returns the exact decimal text of a string, []byte, number, or math/big value.
+func bigText(src reflect.Value) (string, bool) {
+	if src.CanAddr() {
+		switch v := src.Addr().Interface().(type) {
+		case *big.Int:
+			return v.String(), true
+		case *big.Float:
+			return v.Text('g', -1), true
+		case *big.Rat:
+			return ratText(v), true
+		}
+	}
+	switch v := valueOf(src).(type) {
+	case big.Int:
+		return v.String(), true
+	case big.Float:
+		return v.Text('g', -1), true
+	case big.Rat:
+		return ratText(&v), true
+	}
+
+	switch class, bits := numericInfo(src.Kind()); class {
+	case classInt:
+		return strconv.FormatInt(src.Int(), 10), true
+	case classUint:
+		return strconv.FormatUint(src.Uint(), 10), true
+	case classFloat:
+		return strconv.FormatFloat(src.Float(), 'g', -1, bits), true
+	}
+	switch {
+	case src.Kind() == reflect.String:
+		return strings.TrimSpace(src.String()), true
+	case src.Kind() == reflect.Slice && src.Type().Elem().Kind() == reflect.Uint8:
+		return strings.TrimSpace(string(src.Bytes())), true
+	}
+	return "", false
+}
+
+// ratText returns r as a decimal if it has a finite decimal expansion, such as "2.5",
+// or as a fraction otherwise, such as "1/3".
+func ratText(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+	// The expansion is finite if the denominator has no prime factors but 2 and 5.
+	denom := new(big.Int).Set(r.Denom())
+	digits := 0
+	for _, factor := range []int64{2, 5} {
+		f, rem := big.NewInt(factor), new(big.Int)
+		for n := 0; ; n++ {
+			if _, rem = new(big.Int).QuoRem(denom, f, rem); rem.Sign() != 0 {
+				if n > digits {
+					digits = n
+				}
+				break
+			}
+			denom.Quo(denom, f)
+		}
+	}
+	if denom.Cmp(big.NewInt(1)) != 0 {
+		return r.RatString()
+	}
+	return r.FloatString(digits)
+}
+
+// Decimal converts numbers and strings into destinations of type T by passing their exact
+// decimal text to parse. It is the hook for arbitrary-precision decimal types, such as
+// shopspring/decimal, that absorb does not depend on.
+//
+// Example:
+//
+//	opt := absorb.Decimal(decimal.NewFromString)
+func Decimal[T any](parse func(text string) (T, error)) Option {
+	return Converter(reflect.TypeOf((*T)(nil)).Elem(), func(value interface{}) (interface{}, error) {
+		v := reflect.ValueOf(value)
+		if v.Kind() == reflect.Ptr {
+			v = v.Elem()
+		}
+		text, ok := bigText(v)
+		if !ok {
+			return nil, fmt.Errorf("%T is not a number", value)
+		}
+		return parse(text)
+	})
+}
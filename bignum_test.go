@@ -0,0 +1,75 @@
+package absorb_test
+
+import (
+	"errors"
+	"math/big"
+	"strconv"
+	"testing"
+
+	"github.com/jyopp/absorb"
+)
+
+func TestBigNumbers(t *testing.T) {
+	type Ledger struct {
+		Total   big.Int
+		Balance *big.Rat
+		Rate    *big.Float
+	}
+	var dst []Ledger
+	abs := absorb.New(&dst)
+	abs.Open("", 3, "Total", "Balance", "Rate")
+	abs.Absorb("123456789012345678901234567890", "1234.10", 0.1)
+	abs.Absorb(int64(-7), 0.1, "1/4")
+	abs.Absorb([]byte("1e3"), big.NewInt(3), big.NewRat(1, 8))
+	abs.Close()
+
+	for idx, expect := range [][3]string{
+		{"123456789012345678901234567890", "12341/10", "0.1"},
+		{"-7", "1/10", "0.25"},
+		{"1000", "3", "0.125"},
+	} {
+		row := dst[idx]
+		got := [3]string{row.Total.String(), row.Balance.RatString(), row.Rate.Text('g', -1)}
+		if got != expect {
+			t.Fatalf("Row %d: expected %v, got %v", idx, expect, got)
+		}
+	}
+
+	for _, value := range []interface{}{"1.5", "ten", true} {
+		func() {
+			defer func() {
+				if err, _ := recover().(*absorb.ConversionError); err == nil || err.Key != "Total" {
+					t.Fatalf("Expected a conversion error for %v, got %v", value, err)
+				}
+			}()
+			abs := absorb.New(&dst)
+			abs.Open("", 1, "Total")
+			abs.Absorb(value)
+		}()
+	}
+}
+
+// money stands in for an arbitrary-precision decimal type.
+type money struct{ text string }
+
+func parseMoney(text string) (money, error) {
+	if _, err := strconv.ParseFloat(text, 64); err != nil {
+		return money{}, errors.New("invalid amount")
+	}
+	return money{text}, nil
+}
+
+func TestDecimal(t *testing.T) {
+	var dst []struct {
+		Amount money
+		Fee    *money
+	}
+	abs := absorb.New(&dst, absorb.Decimal(parseMoney))
+	abs.Open("", 2, "Amount", "Fee")
+	abs.Absorb("19.99", 0.1)
+	abs.Absorb(int64(12), big.NewRat(5, 2))
+	abs.Close()
+	if dst[0].Amount.text != "19.99" || dst[0].Fee.text != "0.1" || dst[1].Amount.text != "12" || dst[1].Fee.text != "2.5" {
+		t.Fatalf("Unexpected values %+v", dst)
+	}
+}
@@ -0,0 +1,110 @@
+package absorb
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// Buckets returns an Absorber that groups elements into dst by the time in the column
+// named key, truncated to a multiple of interval. Each bucket is keyed by the start of its
+// interval, in UTC. Like a slice, dst is replaced by a new map when the Absorber is opened.
+//
+// The time column is converted to time.Time like any other value, so converters for
+// time.Time apply. Elements are built sequentially; The Parallel option is ignored.
+// Panics on Open if the key is missing.
+//
+// Example:
+//
+//	var hourly map[time.Time][]Request
+//	err := src.Emit(absorb.Buckets(&hourly, "timestamp", time.Hour))
+func Buckets[T any](dst *map[time.Time][]T, key string, interval time.Duration, opts ...Option) Absorber {
+	b := &bucketAbsorber[T]{dst: dst, key: key, interval: interval}
+	opts = append(opts[:len(opts):len(opts)], func(c *config) { c.workers = 0 })
+	b.inner = New(b.add, opts...).(*absorberImpl)
+	return b
+}
+
+// KeepBuckets limits a Buckets destination to the n most recent intervals, like a ring.
+// Older buckets are dropped as newer ones are created, and rows that would fall into a
+// dropped bucket are discarded.
+func KeepBuckets(n int) Option {
+	return func(c *config) {
+		c.keepBuckets = n
+	}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+type bucketAbsorber[T any] struct {
+	dst      *map[time.Time][]T
+	key      string
+	interval time.Duration
+	inner    *absorberImpl
+	keyIdx   int
+	// starts is the sorted start time of every bucket, kept when the buckets are limited.
+	starts []time.Time
+	// current is the bucket start of the row being absorbed.
+	current time.Time
+}
+
+func (b *bucketAbsorber[T]) Open(tag string, count int, keys ...string) {
+	b.keyIdx = -1
+	for idx, k := range keys {
+		if k == b.key {
+			b.keyIdx = idx
+		}
+	}
+	if b.keyIdx < 0 {
+		panic(fmt.Errorf("%w: cannot bucket by %q", ErrMissingKey, b.key))
+	}
+	*b.dst = make(map[time.Time][]T)
+	b.starts = nil
+	b.inner.Open(tag, count, keys...)
+}
+
+func (b *bucketAbsorber[T]) Absorb(values ...interface{}) {
+	b.inner.checkOpen()
+	t := reflect.New(timeType).Elem()
+	if val := reflect.ValueOf(values[b.keyIdx]); val.IsValid() {
+		_assign(t, val, b.inner.cfg)
+	}
+	b.current = t.Interface().(time.Time).Truncate(b.interval).UTC()
+	if b.reserve(b.current) {
+		b.inner.Absorb(values...)
+	}
+}
+
+// reserve makes room for the bucket starting at start, when buckets are limited.
+// Returns false if the bucket is older than every bucket kept.
+func (b *bucketAbsorber[T]) reserve(start time.Time) bool {
+	keep := b.inner.cfg.keepBuckets
+	if keep <= 0 {
+		return true
+	}
+	if _, ok := (*b.dst)[start]; ok {
+		return true
+	}
+	idx := sort.Search(len(b.starts), func(i int) bool { return b.starts[i].After(start) })
+	if len(b.starts) >= keep {
+		if idx == 0 {
+			return false
+		}
+		delete(*b.dst, b.starts[0])
+		b.starts = b.starts[1:]
+		idx--
+	}
+	b.starts = append(b.starts, time.Time{})
+	copy(b.starts[idx+1:], b.starts[idx:])
+	b.starts[idx] = start
+	return true
+}
+
+func (b *bucketAbsorber[T]) add(elem T) {
+	(*b.dst)[b.current] = append((*b.dst)[b.current], elem)
+}
+
+func (b *bucketAbsorber[T]) Close() {
+	b.inner.Close()
+}
@@ -0,0 +1,57 @@
+package absorb_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jyopp/absorb"
+)
+
+type event struct {
+	At   time.Time
+	Name string
+}
+
+// eventSource emits one event per given offset from base, in minutes.
+type eventSource struct {
+	base    time.Time
+	minutes []int
+}
+
+func (es eventSource) Emit(into absorb.Absorber) error {
+	into.Open("test", len(es.minutes), "At", "Name")
+	defer into.Close()
+	for idx, m := range es.minutes {
+		into.Absorb(es.base.Add(time.Duration(m)*time.Minute), string(rune('a'+idx)))
+	}
+	return nil
+}
+
+func TestBuckets(t *testing.T) {
+	base := time.Date(2024, 1, 2, 3, 0, 0, 0, time.UTC)
+	src := eventSource{base: base, minutes: []int{1, 5, 12, 59, 61, 180}}
+
+	var hourly map[time.Time][]event
+	if err := src.Emit(absorb.Buckets(&hourly, "At", time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if len(hourly) != 3 || len(hourly[base]) != 4 || len(hourly[base.Add(time.Hour)]) != 1 {
+		t.Fatalf("Unexpected buckets %v", hourly)
+	}
+	if e := hourly[base.Add(3*time.Hour)]; len(e) != 1 || e[0].Name != "f" {
+		t.Fatalf("Unexpected last bucket %v", e)
+	}
+
+	// Keep the two most recent buckets, discarding rows older than both.
+	src.minutes = []int{61, 1, 180, 2, 62}
+	if err := src.Emit(absorb.Buckets(&hourly, "At", time.Hour, absorb.KeepBuckets(2))); err != nil {
+		t.Fatal(err)
+	}
+	if len(hourly) != 2 || len(hourly[base.Add(time.Hour)]) != 2 || len(hourly[base.Add(3*time.Hour)]) != 1 {
+		t.Fatalf("Unexpected limited buckets %v", hourly)
+	}
+
+	subpanic(t, "Missing Key", func() {
+		src.Emit(absorb.Buckets(&hourly, "When", time.Hour))
+	})
+}
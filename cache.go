@@ -0,0 +1,136 @@
+package absorb
+
+import (
+	"container/list"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// builderKey identifies a cached builder by its element type, the version of the codecs
+// it was built with, and a fingerprint of the tags, keys, and override it was built for.
+type builderKey struct {
+	typ    reflect.Type
+	codecs int
+	key    string
+}
+
+type cachedBuilder struct {
+	key     builderKey
+	builder *elementBuilder
+	// elem is the builder's position in recent, or nil once it has been evicted.
+	elem *list.Element
+}
+
+// builderCache holds the builders made by Open, so that each mapping is computed once.
+// Sources with ad-hoc key sets, such as dynamic SELECT column lists, add a builder for
+// every distinct set, so the cache may be capped; Least recently used builders are evicted.
+//
+// Lookups don't lock unless the cache is capped, when they must record their use.
+type builderCache struct {
+	entries sync.Map // builderKey => *cachedBuilder
+	// limit is accessed atomically, so that lookups can check it without locking.
+	limit int64
+	// mu guards recent, which orders every entry from most to least recently used, or
+	// from newest to oldest if the cache is not capped.
+	mu     sync.Mutex
+	recent list.List
+}
+
+var cachedBuilders builderCache
+
+// get returns the builder for key, calling build and caching the result if needed.
+// Builders are made outside of the lock, since they may be expensive for wide structs.
+func (c *builderCache) get(key builderKey, build func() *elementBuilder) *elementBuilder {
+	if cached, ok := c.entries.Load(key); ok {
+		c.touch(cached.(*cachedBuilder))
+		return cached.(*cachedBuilder).builder
+	}
+
+	builder := build()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cached, ok := c.entries.Load(key); ok {
+		// Another Open made the same builder first.
+		return cached.(*cachedBuilder).builder
+	}
+	cached := &cachedBuilder{key: key, builder: builder}
+	cached.elem = c.recent.PushFront(cached)
+	c.entries.Store(key, cached)
+	c.trim()
+	return builder
+}
+
+// touch records the use of a cached builder, if the cache is capped.
+func (c *builderCache) touch(cached *cachedBuilder) {
+	if atomic.LoadInt64(&c.limit) <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cached.elem != nil {
+		c.recent.MoveToFront(cached.elem)
+	}
+}
+
+// trim evicts the least recently used builders over the limit. The caller holds mu.
+func (c *builderCache) trim() {
+	for limit := int(c.limit); limit > 0 && c.recent.Len() > limit; {
+		c.remove(c.recent.Back().Value.(*cachedBuilder))
+	}
+}
+
+// remove evicts a cached builder. The caller holds mu.
+func (c *builderCache) remove(cached *cachedBuilder) {
+	c.recent.Remove(cached.elem)
+	cached.elem = nil
+	c.entries.Delete(cached.key)
+}
+
+// ClearCache discards every cached mapping. Absorbers that are already open keep using
+// their mappings until they are closed.
+func ClearCache() {
+	c := &cachedBuilders
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for c.recent.Len() > 0 {
+		c.remove(c.recent.Back().Value.(*cachedBuilder))
+	}
+}
+
+// InvalidateCache discards the cached mappings for elements of type t, which may also be
+// given as a pointer type.
+func InvalidateCache(t reflect.Type) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	c := &cachedBuilders
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for elem := c.recent.Front(); elem != nil; {
+		next := elem.Next()
+		if cached := elem.Value.(*cachedBuilder); cached.key.typ == t {
+			c.remove(cached)
+		}
+		elem = next
+	}
+}
+
+// SetCacheLimit caps the number of cached mappings at n, evicting the least recently used
+// mappings over the limit. A limit of zero or less removes the cap, which is the default.
+func SetCacheLimit(n int) {
+	c := &cachedBuilders
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	atomic.StoreInt64(&c.limit, int64(n))
+	c.trim()
+}
+
+// CacheLen returns the number of cached mappings.
+func CacheLen() int {
+	c := &cachedBuilders
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.recent.Len()
+}
@@ -0,0 +1,97 @@
+package absorb_test
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/jyopp/absorb"
+)
+
+// keysSource emits a single row with the given keys, all in the "test" namespace.
+type keysSource []string
+
+func (ks keysSource) Emit(into absorb.Absorber) error {
+	into.Open("test", 1, ks...)
+	defer into.Close()
+	into.Absorb(make([]interface{}, len(ks))...)
+	return nil
+}
+
+func TestClearCache(t *testing.T) {
+	var dst TestDst
+	if err := absorb.Absorb(&dst, testSource{i: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if absorb.CacheLen() == 0 {
+		t.Fatal("Expected a cached mapping after absorbing")
+	}
+	absorb.ClearCache()
+	if n := absorb.CacheLen(); n != 0 {
+		t.Fatalf("Expected an empty cache, got %d mappings", n)
+	}
+}
+
+func TestInvalidateCache(t *testing.T) {
+	type other struct{ Name string }
+	absorb.ClearCache()
+	var dst TestDst
+	var o other
+	absorb.Absorb(&dst, keysSource{"Name"})
+	absorb.Absorb(&o, keysSource{"Name"})
+	if n := absorb.CacheLen(); n != 2 {
+		t.Fatalf("Expected 2 cached mappings, got %d", n)
+	}
+
+	absorb.InvalidateCache(reflect.TypeOf(&dst))
+	if n := absorb.CacheLen(); n != 1 {
+		t.Fatalf("Expected 1 cached mapping, got %d", n)
+	}
+	// The remaining mapping still works.
+	if err := absorb.Absorb(&o, keysSource{"Name"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCacheLimit(t *testing.T) {
+	absorb.ClearCache()
+	absorb.SetCacheLimit(2)
+	defer absorb.SetCacheLimit(0)
+
+	var dst TestDst
+	for _, key := range []string{"Name", "Aliased", "Unused", "name"} {
+		if err := absorb.Absorb(&dst, keysSource{key}); err != nil {
+			t.Fatal(err)
+		}
+		if n := absorb.CacheLen(); n > 2 {
+			t.Fatalf("Expected at most 2 cached mappings, got %d", n)
+		}
+	}
+
+	absorb.SetCacheLimit(1)
+	if n := absorb.CacheLen(); n != 1 {
+		t.Fatalf("Expected lowering the limit to evict mappings, got %d", n)
+	}
+}
+
+func TestCacheConcurrent(t *testing.T) {
+	defer absorb.SetCacheLimit(0)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var dst TestDst
+			for j := 0; j < 100; j++ {
+				if err := absorb.Absorb(&dst, keysSource{[]string{"Name", "Aliased", "Unused"}[j%3]}); err != nil {
+					t.Error(err)
+					return
+				}
+				if i == 0 && j%10 == 0 {
+					absorb.SetCacheLimit(j % 20)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
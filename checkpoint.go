@@ -0,0 +1,59 @@
+package absorb
+
+// Checkpointer records the progress of an absorption, so that an interrupted import may
+// be resumed with the Resume option.
+type Checkpointer interface {
+	// Checkpoint is called once each row has been absorbed, with the offset of the row
+	// following it in the source. Rows before offset need not be emitted again.
+	Checkpoint(offset int)
+}
+
+// CheckpointFunc adapts a function to the Checkpointer interface.
+type CheckpointFunc func(offset int)
+
+func (fn CheckpointFunc) Checkpoint(offset int) {
+	fn(offset)
+}
+
+// ResumableSource is implemented by Absorbables that can skip to a row efficiently,
+// such as by seeking a file or adding an OFFSET clause to a query.
+type ResumableSource interface {
+	Absorbable
+	// EmitFrom behaves like Emit, but skips the first offset rows of the source.
+	EmitFrom(into Absorber, offset int) error
+}
+
+// WithCheckpointer calls cp.Checkpoint after each row is absorbed. For channel and
+// callback destinations, a row is absorbed once it has been delivered; Rows kept as
+// overflow are never checkpointed.
+//
+// With unordered Parallel delivery, rows may be delivered out of order, so only the
+// final offset is recorded, once Close has delivered every row.
+func WithCheckpointer(cp Checkpointer) Option {
+	return func(c *config) {
+		c.checkpointer = cp
+	}
+}
+
+// Resume starts absorbing at the given source offset, usually the last one recorded by
+// a Checkpointer. When used with the Absorb function and a ResumableSource, the source
+// skips to offset itself; Otherwise the Absorber discards the first offset rows.
+// Offsets passed to a Checkpointer include the skipped rows.
+//
+// Example:
+//
+//	offset := loadOffset()
+//	err := absorb.Absorb(sink, src, absorb.Resume(offset),
+//		absorb.WithCheckpointer(absorb.CheckpointFunc(saveOffset)))
+func Resume(offset int) Option {
+	return func(c *config) {
+		c.resume = offset
+	}
+}
+
+// checkpoint records that the element at idx has been delivered.
+func (a *absorberImpl) checkpoint(idx int) {
+	if cp := a.cfg.checkpointer; cp != nil && !a.overflow.IsValid() {
+		cp.Checkpoint(a.cfg.resume + idx + 1)
+	}
+}
@@ -0,0 +1,83 @@
+package absorb_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jyopp/absorb"
+)
+
+// resumableSource records the offset given to EmitFrom.
+type resumableSource struct {
+	testSource
+	from *int
+}
+
+func (rs resumableSource) EmitFrom(into absorb.Absorber, offset int) error {
+	*rs.from = offset
+	into.Open("test", -1, "Name", "Aliased")
+	defer into.Close()
+	for i := offset; i < rs.i; i++ {
+		into.Absorb("test", i+1)
+	}
+	return nil
+}
+
+func TestCheckpoint(t *testing.T) {
+	var offsets []int
+	record := absorb.WithCheckpointer(absorb.CheckpointFunc(func(offset int) {
+		offsets = append(offsets, offset)
+	}))
+
+	var dst []TestDst
+	if err := absorb.Absorb(&dst, testSource{i: 3}, record); err != nil {
+		t.Fatal(err)
+	}
+	if expect := []int{1, 2, 3}; !reflect.DeepEqual(offsets, expect) {
+		t.Fatalf("Expected checkpoints %v, got %v", expect, offsets)
+	}
+
+	// Sources that can't skip rows have them discarded by the absorber.
+	offsets = nil
+	if err := absorb.Absorb(&dst, testSource{i: 5}, absorb.Resume(3), record); err != nil {
+		t.Fatal(err)
+	}
+	if expect := []int{4, 5}; !reflect.DeepEqual(offsets, expect) {
+		t.Fatalf("Expected checkpoints %v, got %v", expect, offsets)
+	}
+	if len(dst) != 2 || dst[0].Actual != 4 {
+		t.Fatalf("Unexpected resumed rows %+v", dst)
+	}
+
+	// Resumable sources skip rows themselves.
+	var from int
+	dst = nil
+	if err := absorb.Absorb(&dst, resumableSource{testSource{i: 5}, &from}, absorb.Resume(4)); err != nil {
+		t.Fatal(err)
+	}
+	if from != 4 || len(dst) != 1 || dst[0].Actual != 5 {
+		t.Fatalf("Unexpected resume from %d into %+v", from, dst)
+	}
+}
+
+func TestCheckpointParallel(t *testing.T) {
+	for _, ordered := range []bool{true, false} {
+		var last int
+		count := 0
+		dst := func(TestDst) {}
+		err := absorb.Absorb(dst, testSource{i: 20}, absorb.Parallel(4, ordered),
+			absorb.WithCheckpointer(absorb.CheckpointFunc(func(offset int) {
+				if offset <= last {
+					t.Errorf("Checkpoint %d follows %d", offset, last)
+				}
+				last = offset
+				count++
+			})))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if last != 20 || (!ordered && count != 1) {
+			t.Fatalf("Unexpected final checkpoint %d after %d checkpoints", last, count)
+		}
+	}
+}
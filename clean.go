@@ -0,0 +1,51 @@
+package absorb
+
+import "strings"
+
+// TrimSpace removes leading and trailing white space from every string value before it
+// is assigned, as dirty CSV and spreadsheet exports often need.
+func TrimSpace() Option {
+	return func(c *config) {
+		c.trimSpace = true
+	}
+}
+
+// EmptyAsNil absorbs empty strings as nil, leaving fields at their zero value, or at their
+// default if an Override supplies one, rather than failing to convert "" to a number or
+// time. Combined with TrimSpace, strings of only white space are nil too.
+func EmptyAsNil() Option {
+	return func(c *config) {
+		c.emptyAsNil = true
+	}
+}
+
+// cleanStrings returns values with strings trimmed or replaced by nil, as configured.
+// The given slice is returned unmodified if no value changes.
+func (c *config) cleanStrings(values []interface{}) []interface{} {
+	var cleaned []interface{}
+	for idx, value := range values {
+		s, ok := value.(string)
+		if !ok {
+			continue
+		}
+		var v interface{} = s
+		if c.trimSpace {
+			s = strings.TrimSpace(s)
+			v = s
+		}
+		if c.emptyAsNil && s == "" {
+			v = nil
+		}
+		if v == value {
+			continue
+		}
+		if cleaned == nil {
+			cleaned = append([]interface{}(nil), values...)
+		}
+		cleaned[idx] = v
+	}
+	if cleaned == nil {
+		return values
+	}
+	return cleaned
+}
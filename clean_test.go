@@ -0,0 +1,52 @@
+package absorb_test
+
+import (
+	"testing"
+
+	"github.com/jyopp/absorb"
+)
+
+type cleanDst struct {
+	Name  string
+	Count int
+	Note  *string
+	Tier  string
+}
+
+func TestTrimSpace(t *testing.T) {
+	var dst []cleanDst
+	abs := absorb.New(&dst, absorb.TrimSpace())
+	abs.Open("", 1, "Name", "Note", "Tier")
+	abs.Absorb("  ann\t", " ", []byte(" raw "))
+	abs.Close()
+	if dst[0].Name != "ann" || dst[0].Note == nil || *dst[0].Note != "" || dst[0].Tier != " raw " {
+		t.Fatalf("Unexpected values %+v", dst[0])
+	}
+}
+
+func TestEmptyAsNil(t *testing.T) {
+	var dst []cleanDst
+	abs := absorb.New(&dst, absorb.EmptyAsNil(), absorb.NumberLocale(',', '.'), absorb.WithOverrides(absorb.Override{
+		Type:     "absorb_test.cleanDst",
+		Defaults: map[string]interface{}{"Tier": "free"},
+	}))
+	abs.Open("", 2, "Name", "Count", "Note", "Tier")
+	abs.Absorb("ann", "", "", "")
+	abs.Absorb("bob", "3", " ", "gold")
+	abs.Close()
+	if d := dst[0]; d.Name != "ann" || d.Count != 0 || d.Note != nil || d.Tier != "free" {
+		t.Fatalf("Unexpected values %+v", d)
+	}
+	// Without TrimSpace, white space is not empty.
+	if d := dst[1]; d.Count != 3 || d.Note == nil || *d.Note != " " || d.Tier != "gold" {
+		t.Fatalf("Unexpected values %+v", d)
+	}
+
+	abs = absorb.New(&dst, absorb.EmptyAsNil(), absorb.TrimSpace())
+	abs.Open("", 1, "Note")
+	abs.Absorb("   ")
+	abs.Close()
+	if dst[0].Note != nil {
+		t.Fatalf("Expected a nil Note, got %q", *dst[0].Note)
+	}
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const annotation = "//absorb:generate"
+
+// generate returns the source of a file declaring setters for the named struct types in
+// the given source file, or for its annotated struct types if names is empty.
+func generate(filename string, src []byte, names []string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[strings.TrimSpace(name)] = true
+	}
+
+	g := &generator{fset: fset, file: file, imports: map[string]string{}}
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			st, ok := ts.Type.(*ast.StructType)
+			if !ok {
+				continue
+			}
+			if len(names) > 0 {
+				if !wanted[ts.Name.Name] {
+					continue
+				}
+				delete(wanted, ts.Name.Name)
+			} else if !annotated(gen.Doc) && !annotated(ts.Doc) {
+				continue
+			}
+			if err := g.genType(ts.Name.Name, st); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for name := range wanted {
+		return nil, fmt.Errorf("no struct type %s in %s", name, filename)
+	}
+	if g.body.Len() == 0 {
+		return nil, fmt.Errorf("no struct types to generate in %s", filename)
+	}
+	return g.source()
+}
+
+func annotated(doc *ast.CommentGroup) bool {
+	if doc == nil {
+		return false
+	}
+	for _, c := range doc.List {
+		if strings.TrimSpace(c.Text) == annotation {
+			return true
+		}
+	}
+	return false
+}
+
+type generator struct {
+	fset *token.FileSet
+	file *ast.File
+	body bytes.Buffer
+	// imports maps the package names used by generated code to their import paths.
+	imports map[string]string
+}
+
+func (g *generator) genType(name string, st *ast.StructType) error {
+	fmt.Fprintf(&g.body, "\nvar _%sAbsorbSetters = map[string]absorb.FieldSetter{\n", name)
+	for _, field := range st.Fields.List {
+		// Embedded and unexported fields are left to reflection.
+		for _, ident := range field.Names {
+			if !ident.IsExported() {
+				continue
+			}
+			typ, err := g.typeString(field.Type)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(&g.body, "\t%q: func(elem, value interface{}) bool {\n", ident.Name)
+			if ptr, ok := field.Type.(*ast.StarExpr); ok {
+				elemType, err := g.typeString(ptr.X)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(&g.body, "\t\tswitch v := value.(type) {\n\t\tcase %s:\n\t\t\telem.(*%s).%s = v\n", typ, name, ident.Name)
+				fmt.Fprintf(&g.body, "\t\tcase %s:\n\t\t\telem.(*%s).%s = &v\n", elemType, name, ident.Name)
+				fmt.Fprintf(&g.body, "\t\tdefault:\n\t\t\treturn false\n\t\t}\n\t\treturn true\n")
+			} else {
+				fmt.Fprintf(&g.body, "\t\tv, ok := value.(%s)\n\t\tif ok {\n\t\t\telem.(*%s).%s = v\n\t\t}\n\t\treturn ok\n", typ, name, ident.Name)
+			}
+			g.body.WriteString("\t},\n")
+		}
+	}
+	g.body.WriteString("}\n\n")
+	fmt.Fprintf(&g.body, "// AbsorbFieldSetters implements absorb.GeneratedSetters\n")
+	fmt.Fprintf(&g.body, "func (*%s) AbsorbFieldSetters() map[string]absorb.FieldSetter {\n\treturn _%sAbsorbSetters\n}\n", name, name)
+	return nil
+}
+
+// typeString prints a type expression, recording the imports it refers to.
+func (g *generator) typeString(expr ast.Expr) (string, error) {
+	var err error
+	ast.Inspect(expr, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if pkg, ok := sel.X.(*ast.Ident); ok {
+			if importPath := g.importPath(pkg.Name); importPath != "" {
+				g.imports[pkg.Name] = importPath
+			} else if err == nil {
+				err = fmt.Errorf("cannot find import for package %s", pkg.Name)
+			}
+		}
+		return false
+	})
+	var buf bytes.Buffer
+	printer.Fprint(&buf, g.fset, expr)
+	return buf.String(), err
+}
+
+// importPath returns the path of the file's import with the given package name.
+// Packages are assumed to be named for the last element of their path, unless renamed.
+func (g *generator) importPath(name string) string {
+	for _, imp := range g.file.Imports {
+		importPath, _ := strconv.Unquote(imp.Path.Value)
+		if imp.Name != nil {
+			if imp.Name.Name == name {
+				return importPath
+			}
+		} else if path.Base(importPath) == name {
+			return importPath
+		}
+	}
+	return ""
+}
+
+func (g *generator) source() ([]byte, error) {
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by absorbgen. DO NOT EDIT.\n\npackage %s\n\nimport (\n", g.file.Name.Name)
+	names := make([]string, 0, len(g.imports))
+	for name := range g.imports {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		importPath := g.imports[name]
+		if path.Base(importPath) == name {
+			fmt.Fprintf(&out, "\t%q\n", importPath)
+		} else {
+			fmt.Fprintf(&out, "\t%s %q\n", name, importPath)
+		}
+	}
+	if len(names) > 0 {
+		out.WriteString("\n")
+	}
+	out.WriteString("\t\"github.com/jyopp/absorb\"\n)\n")
+	out.Write(g.body.Bytes())
+	return format.Source(out.Bytes())
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+const input = `package users
+
+import (
+	"time"
+	sq "database/sql"
+)
+
+//absorb:generate
+type User struct {
+	ID, Group int64
+	Name      string ` + "`db:\"name\"`" + `
+	Seen      *time.Time
+	Email     sq.NullString
+	secret    string
+}
+
+type Skipped struct {
+	Name string
+}
+`
+
+func TestGenerate(t *testing.T) {
+	out, err := generate("users.go", []byte(input), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := string(out)
+	for _, expect := range []string{
+		"// Code generated by absorbgen. DO NOT EDIT.",
+		"\tsq \"database/sql\"\n",
+		"\t\"time\"\n",
+		"func (*User) AbsorbFieldSetters() map[string]absorb.FieldSetter {",
+		"\"Group\": func(elem, value interface{}) bool {\n\t\tv, ok := value.(int64)",
+		"case time.Time:\n\t\t\telem.(*User).Seen = &v",
+		"value.(sq.NullString)",
+	} {
+		if !strings.Contains(src, expect) {
+			t.Errorf("Expected output to contain %q:\n%s", expect, src)
+		}
+	}
+	if strings.Contains(src, "secret") || strings.Contains(src, "Skipped") {
+		t.Errorf("Unexpected unexported or unannotated fields:\n%s", src)
+	}
+
+	if _, err := generate("users.go", []byte(input), []string{"Skipped"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := generate("users.go", []byte(input), []string{"Missing"}); err == nil {
+		t.Fatal("Expected an error for a missing type")
+	}
+}
@@ -0,0 +1,63 @@
+// Command absorbgen generates field setters for struct types, which absorb uses to assign
+// values without reflection. Annotate each struct type with an //absorb:generate comment,
+// and run absorbgen with go generate:
+//
+//	//go:generate absorbgen
+//
+//	//absorb:generate
+//	type User struct {
+//		ID   int64
+//		Name string `db:"name"`
+//	}
+//
+// For an input file user.go, the setters are written to user_absorb.go. Alternatively, name
+// the types to generate with the -type flag, as a comma-separated list.
+//
+// Generated setters only handle values of each field's exact type, or of the type a
+// pointer field points to. Other values are still converted by absorb with reflection.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+func main() {
+	typeNames := flag.String("type", "", "comma-separated list of type names; defaults to types annotated with //absorb:generate")
+	output := flag.String("output", "", "output file name; defaults to <file>_absorb.go")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: absorbgen [-type T1,T2] [-output file] [file.go]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	input := os.Getenv("GOFILE")
+	if flag.NArg() > 0 {
+		input = flag.Arg(0)
+	}
+	if input == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *output == "" {
+		*output = strings.TrimSuffix(input, ".go") + "_absorb.go"
+	}
+
+	var types []string
+	if *typeNames != "" {
+		types = strings.Split(*typeNames, ",")
+	}
+	src, err := os.ReadFile(input)
+	if err == nil {
+		src, err = generate(input, src, types)
+	}
+	if err == nil {
+		err = os.WriteFile(*output, src, 0o644)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "absorbgen:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,92 @@
+package absorb
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// A Codec decodes the raw text of a value for a field of type dstType, returning a value
+// that is then assigned to the field like any other. Fields select a codec by name with
+// a struct tag option, such as `csv:"payload,base64"`, `csv:"id,hex"`, or
+// `csv:"flags,json"`. Values that are not strings or byte slices are assigned directly.
+type Codec func(raw []byte, dstType reflect.Type) (interface{}, error)
+
+// builtinCodecs are available to every field, unless replaced with RegisterCodec.
+var builtinCodecs = map[string]Codec{
+	// base64 accepts the standard and URL-safe alphabets, with or without padding.
+	"base64": func(raw []byte, _ reflect.Type) (interface{}, error) {
+		text := strings.TrimRight(strings.TrimSpace(string(raw)), "=")
+		if strings.ContainsAny(text, "-_") {
+			return base64.RawURLEncoding.DecodeString(text)
+		}
+		return base64.RawStdEncoding.DecodeString(text)
+	},
+	"hex": func(raw []byte, _ reflect.Type) (interface{}, error) {
+		text := strings.TrimSpace(string(raw))
+		if len(text) > 1 && text[0] == '0' && (text[1] == 'x' || text[1] == 'X') {
+			text = text[2:]
+		}
+		return hex.DecodeString(text)
+	},
+	// json unmarshals the value into a new value of the field's type.
+	"json": func(raw []byte, dstType reflect.Type) (interface{}, error) {
+		v := reflect.New(dstType)
+		if err := json.Unmarshal(raw, v.Interface()); err != nil {
+			return nil, err
+		}
+		return v.Elem().Interface(), nil
+	},
+}
+
+// RegisterCodec makes fn available to struct fields as the tag option name, replacing any
+// codec of the same name, including the built-in "base64", "hex", and "json" codecs.
+// Passing a nil fn removes the codec. Mappings cached before the update are discarded.
+func RegisterCodec(name string, fn Codec) {
+	updateRegistry(func(r *registry) {
+		if fn == nil {
+			delete(r.codecs, name)
+		} else {
+			r.codecs[name] = fn
+		}
+		r.codecVersion++
+	})
+	ClearCache()
+}
+
+// codec returns the codec selected by a field's tag options, or nil if there is none.
+func (r *registry) codec(opts tagOptions) (name string, codec Codec) {
+	for s := string(opts); s != ""; {
+		var opt string
+		opt, s, _ = strings.Cut(s, ",")
+		name = strings.TrimSpace(opt)
+		if fn, ok := r.codecs[name]; ok {
+			return name, fn
+		}
+	}
+	return "", nil
+}
+
+// assignCodec decodes the raw text of src with codec, then assigns the result to dst.
+func assignCodec(dst, src reflect.Value, name string, codec Codec, cfg *config) {
+	var raw []byte
+	switch {
+	case src.Kind() == reflect.String:
+		raw = []byte(src.String())
+	case isBytes(src.Type()):
+		raw = src.Bytes()
+	default:
+		_assign(dst, src, cfg)
+		return
+	}
+	decoded, err := codec(raw, dst.Type())
+	if err != nil {
+		panic(&ConversionError{Src: src.Type(), Dst: dst.Type(), Err: fmt.Errorf("%s: %w", name, err)})
+	}
+	if decoded != nil {
+		_assign(dst, reflect.ValueOf(decoded), cfg)
+	}
+}
@@ -0,0 +1,87 @@
+package absorb_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jyopp/absorb"
+)
+
+type codecSource []interface{}
+
+func (cs codecSource) Emit(into absorb.Absorber) error {
+	into.Open("csv", 1, "payload", "id", "flags", "name")
+	defer into.Close()
+	into.Absorb(cs...)
+	return nil
+}
+
+type codecDst struct {
+	Payload []byte          `csv:"payload,base64"`
+	ID      [4]byte         `csv:"id,hex"`
+	Flags   map[string]bool `csv:"flags,json"`
+	Name    *string         `csv:"name,upper"`
+}
+
+func TestCodecs(t *testing.T) {
+	absorb.RegisterCodec("upper", func(raw []byte, _ reflect.Type) (interface{}, error) {
+		return strings.ToUpper(string(raw)), nil
+	})
+	defer absorb.RegisterCodec("upper", nil)
+
+	for _, values := range []codecSource{
+		codecSource{"aGVsbG8=", "0xdeadbeef", `{"admin":true}`, "ann"},
+		codecSource{[]byte("aGVsbG8"), []byte("DEADBEEF"), []byte(`{"admin": true}`), []byte("ann")},
+	} {
+		var dst codecDst
+		if err := absorb.Absorb(&dst, values); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(dst.Payload, []byte("hello")) || dst.ID != [4]byte{0xde, 0xad, 0xbe, 0xef} ||
+			!dst.Flags["admin"] || dst.Name == nil || *dst.Name != "ANN" {
+			t.Fatalf("Unexpected values %+v from %v", dst, values)
+		}
+	}
+
+	// Values that are not text are assigned without decoding.
+	var dst codecDst
+	if err := absorb.Absorb(&dst, codecSource{nil, [4]byte{1, 2, 3, 4}, map[string]bool{"x": true}, nil}); err != nil {
+		t.Fatal(err)
+	}
+	if dst.ID != [4]byte{1, 2, 3, 4} || !dst.Flags["x"] {
+		t.Fatalf("Unexpected values %+v", dst)
+	}
+
+	defer func() {
+		err, _ := recover().(*absorb.ConversionError)
+		if err == nil || err.Key != "flags" || !strings.HasPrefix(err.Err.Error(), "json: ") {
+			t.Fatalf("Expected a conversion error for invalid JSON, got %v", err)
+		}
+	}()
+	absorb.Absorb(&dst, codecSource{nil, nil, "{", nil})
+}
+
+func TestCodecSnapshot(t *testing.T) {
+	absorb.RegisterCodec("upper", func(raw []byte, _ reflect.Type) (interface{}, error) {
+		return strings.ToUpper(string(raw)), nil
+	})
+	defer absorb.RegisterCodec("upper", nil)
+
+	var before, after codecDst
+	abs := absorb.New(&before)
+	// Absorbers created before an update keep the codecs of their snapshot.
+	absorb.RegisterCodec("upper", func(raw []byte, _ reflect.Type) (interface{}, error) {
+		return "replaced", nil
+	})
+	if err := (codecSource{nil, nil, nil, "ann"}).Emit(abs); err != nil {
+		t.Fatal(err)
+	}
+	if err := absorb.Absorb(&after, codecSource{nil, nil, nil, "ann"}); err != nil {
+		t.Fatal(err)
+	}
+	if *before.Name != "ANN" || *after.Name != "replaced" {
+		t.Fatalf("Expected each Absorber to use its own codec, got %q and %q", *before.Name, *after.Name)
+	}
+}
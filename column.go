@@ -0,0 +1,42 @@
+package absorb
+
+import "fmt"
+
+// Column absorbs the values of the column named key from src into a slice of T, applying
+// the usual conversions to each value. Other columns are discarded without building an
+// element for the row, and nil values are absorbed as the zero value of T.
+func Column[T any](src Absorbable, key string, opts ...Option) ([]T, error) {
+	var values []T
+	col := &columnAbsorber{next: New(&values, opts...), key: key}
+	err := src.Emit(col)
+	return values, err
+}
+
+// columnAbsorber passes a single column of each row to next.
+type columnAbsorber struct {
+	next   Absorber
+	key    string
+	column int
+}
+
+func (c *columnAbsorber) Open(tag string, count int, keys ...string) {
+	c.column = -1
+	for idx, key := range keys {
+		if key == c.key {
+			c.column = idx
+			break
+		}
+	}
+	if c.column < 0 {
+		panic(fmt.Errorf("%w: cannot project column %q", ErrMissingKey, c.key))
+	}
+	c.next.Open(tag, count, c.key)
+}
+
+func (c *columnAbsorber) Absorb(values ...interface{}) {
+	c.next.Absorb(values[c.column])
+}
+
+func (c *columnAbsorber) Close() {
+	c.next.Close()
+}
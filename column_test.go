@@ -0,0 +1,45 @@
+package absorb_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/jyopp/absorb"
+)
+
+func TestColumn(t *testing.T) {
+	values, err := absorb.Column[float64](testSource{i: 3}, "Aliased")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(values, []float64{1, 2, 3}) {
+		t.Fatalf("Unexpected values %v", values)
+	}
+
+	names, err := absorb.Column[*string](testSource{i: 2}, "Name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 2 || *names[0] != "test" || *names[1] != "test" {
+		t.Fatalf("Unexpected names %v", names)
+	}
+
+	// nil values are absorbed as zero values.
+	unset, err := absorb.Column[int](keysSource{"Name", "Aliased"}, "Aliased")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(unset, []int{0}) {
+		t.Fatalf("Unexpected values %v", unset)
+	}
+
+	t.Run("Missing", func(t *testing.T) {
+		defer func() {
+			if err, _ := recover().(error); !errors.Is(err, absorb.ErrMissingKey) {
+				t.Fatal("Expected ErrMissingKey, got", err)
+			}
+		}()
+		absorb.Column[int](testSource{i: 1}, "Missing")
+	})
+}
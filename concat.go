@@ -0,0 +1,101 @@
+package absorb
+
+import "fmt"
+
+// Concat returns an Absorbable that emits each of srcs in order into the same Absorber,
+// which is opened once, so that multi-file or multi-shard imports fill one destination.
+//
+// The keys and tag of the first source to open are used for every row. Later sources may
+// emit their keys in a different order, or omit some of them, which are absorbed as nil.
+// Emit panics if a later source emits a key the first did not. Emitting stops at the first
+// source that returns an error.
+func Concat(srcs ...Absorbable) Absorbable {
+	return concat(srcs)
+}
+
+type concat []Absorbable
+
+func (c concat) Emit(into Absorber) error {
+	m := &concatAbsorber{next: into, count: -1}
+	if len(c) == 1 {
+		// The count of a single source is still accurate.
+		m.count = 0
+	}
+	defer func() {
+		if m.opened {
+			into.Close()
+		}
+	}()
+	for _, src := range c {
+		if err := src.Emit(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// concatAbsorber passes each source's rows to next, which it opens only once.
+type concatAbsorber struct {
+	next   Absorber
+	count  int
+	opened bool
+	keys   []string
+	// columns maps each of the current source's columns to a column of keys.
+	// It is nil when the source's keys are identical to keys.
+	columns []int
+	rowData []interface{}
+}
+
+func (c *concatAbsorber) Open(tag string, count int, keys ...string) {
+	if !c.opened {
+		if c.count == 0 {
+			c.count = count
+		}
+		c.keys = append([]string(nil), keys...)
+		c.rowData = make([]interface{}, len(keys))
+		c.opened = true
+		c.columns = nil
+		c.next.Open(tag, c.count, keys...)
+		return
+	}
+
+	c.columns = nil
+	if len(keys) == len(c.keys) {
+		same := true
+		for idx, key := range keys {
+			same = same && key == c.keys[idx]
+		}
+		if same {
+			return
+		}
+	}
+	c.columns = make([]int, len(keys))
+	for idx, key := range keys {
+		c.columns[idx] = -1
+		for col, k := range c.keys {
+			if k == key {
+				c.columns[idx] = col
+			}
+		}
+		if c.columns[idx] < 0 {
+			panic(fmt.Errorf("cannot concatenate source with key %q, which the first source did not emit", key))
+		}
+	}
+}
+
+func (c *concatAbsorber) Absorb(values ...interface{}) {
+	if c.columns == nil {
+		c.next.Absorb(values...)
+		return
+	}
+	for idx := range c.rowData {
+		c.rowData[idx] = nil
+	}
+	for idx, value := range values {
+		c.rowData[c.columns[idx]] = value
+	}
+	c.next.Absorb(c.rowData...)
+}
+
+// Close does nothing; The destination is closed once every source has been emitted.
+func (c *concatAbsorber) Close() {}
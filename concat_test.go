@@ -0,0 +1,36 @@
+package absorb_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jyopp/absorb"
+)
+
+// aliasSource emits rows with only the Aliased key.
+type aliasSource []int
+
+func (rs aliasSource) Emit(into absorb.Absorber) error {
+	into.Open("test", len(rs), "Aliased")
+	defer into.Close()
+	for _, i := range rs {
+		into.Absorb(i)
+	}
+	return nil
+}
+
+func TestConcat(t *testing.T) {
+	var dst []TestDst
+	src := absorb.Concat(testSource{i: 2}, aliasSource{7}, repeatSource{9})
+	if err := absorb.Absorb(&dst, src); err != nil {
+		t.Fatal(err)
+	}
+	expect := []TestDst{{"test", 1, 0}, {"test", 2, 0}, {"", 7, 0}, {"test", 9, 0}}
+	if !reflect.DeepEqual(dst, expect) {
+		t.Fatalf("Expected %+v, got %+v", expect, dst)
+	}
+
+	subpanic(t, "Extra Key", func() {
+		absorb.Absorb(&dst, absorb.Concat(aliasSource{1}, testSource{i: 1}))
+	})
+}
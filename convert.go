@@ -0,0 +1,390 @@
+package absorb
+
+import (
+	"encoding"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// convertBuiltin handles conversions from strings that reflect cannot perform:
+// formatted complex numbers such as "1+2i", and delimited lists of numbers such as
+// "1.5, 2.5, 3" into numeric slices. Strings are copied into byte slices as text, rather
+// than parsed as lists. Returns false if it does not apply.
+func convertBuiltin(dst, src reflect.Value, dstType reflect.Type) bool {
+	if src.Kind() != reflect.String {
+		return false
+	}
+	switch dstType.Kind() {
+	case reflect.Complex64, reflect.Complex128:
+		c, err := strconv.ParseComplex(strings.TrimSpace(src.String()), dstType.Bits())
+		if err != nil {
+			panic(fmt.Errorf("cannot convert %q to %s: %w", src.String(), dstType, err))
+		}
+		dst.Set(reflect.ValueOf(c).Convert(dstType))
+		return true
+	case reflect.Slice:
+		if dstType.Elem().Kind() == reflect.Uint8 {
+			dst.Set(reflect.ValueOf([]byte(src.String())).Convert(dstType))
+			return true
+		}
+		if class, _ := numericInfo(dstType.Elem().Kind()); class == classNone || class == classComplex {
+			return false
+		}
+		dst.Set(parseNumberList(src.String(), dstType))
+		return true
+	}
+	return false
+}
+
+// defaultBools are the strings accepted for bool destinations, unless the BoolStrings
+// option replaces them. They are matched without regard to case.
+var defaultBools = map[string]bool{
+	"1": true, "t": true, "true": true, "y": true, "yes": true, "on": true,
+	"0": false, "f": false, "false": false, "n": false, "no": false, "off": false,
+}
+
+// BoolStrings sets the strings accepted for bool destinations, which are matched without
+// regard to case or surrounding space. By default, "1", "t", "true", "y", "yes", and "on"
+// are true, and "0", "f", "false", "n", "no", and "off" are false. Other strings panic.
+func BoolStrings(trues, falses []string) Option {
+	bools := make(map[string]bool, len(trues)+len(falses))
+	for _, s := range trues {
+		bools[strings.ToLower(s)] = true
+	}
+	for _, s := range falses {
+		bools[strings.ToLower(s)] = false
+	}
+	return func(c *config) {
+		c.bools = bools
+	}
+}
+
+// convertBool parses a string into a bool dst, using the strings accepted by cfg.
+// Returns false if it does not apply.
+func convertBool(dst, src reflect.Value, dstType reflect.Type, cfg *config) bool {
+	if dstType.Kind() != reflect.Bool || src.Kind() != reflect.String {
+		return false
+	}
+	bools := cfg.bools
+	if bools == nil {
+		bools = defaultBools
+	}
+	b, ok := bools[strings.ToLower(strings.TrimSpace(src.String()))]
+	if !ok {
+		panic(&ConversionError{Src: src.Type(), Dst: dstType, Err: fmt.Errorf("invalid boolean %q", src.String())})
+	}
+	dst.SetBool(b)
+	return true
+}
+
+// numberLocale holds the separators of numbers formatted for a locale.
+type numberLocale struct {
+	thousands, decimal rune
+}
+
+// NumberLocale parses strings into numeric fields, using the given thousands separator
+// and decimal mark, such as NumberLocale('.', ',') for "1.234,56" in European exports.
+// A thousands separator of ' ' also matches non-breaking spaces, as in "1 234,56", and
+// one of 0 disallows grouping. Integer fields accept decimals with no fractional part,
+// such as "1.000,00". Without this option, strings are parsed as plain numbers, such as
+// "-1234.5", by convertNumberText.
+func NumberLocale(thousands, decimal rune) Option {
+	return func(c *config) {
+		c.locale = &numberLocale{thousands: thousands, decimal: decimal}
+	}
+}
+
+// convertLocaleNumber parses a string into a numeric dst, using the separators of cfg's
+// locale. Returns false if it does not apply.
+func convertLocaleNumber(dst, src reflect.Value, dstType reflect.Type, cfg *config) bool {
+	loc := cfg.locale
+	if loc == nil || src.Kind() != reflect.String {
+		return false
+	}
+	class, _ := numericInfo(dstType.Kind())
+	if class == classNone || class == classComplex {
+		return false
+	}
+	text, ok := loc.normalize(strings.TrimSpace(src.String()))
+	if !ok {
+		panic(&ConversionError{Src: src.Type(), Dst: dstType, Err: fmt.Errorf("invalid number %q", src.String())})
+	}
+	if class != classFloat {
+		// Integers may be written with a zero fraction.
+		if whole, frac, ok := strings.Cut(text, "."); ok && strings.Trim(frac, "0") == "" && whole != "" {
+			text = whole
+		}
+	}
+	parsed, err := parseNumber(text, dstType)
+	if err != nil {
+		panic(&ConversionError{Src: src.Type(), Dst: dstType, Err: fmt.Errorf("invalid number %q", src.String())})
+	}
+	dst.Set(parsed)
+	return true
+}
+
+// convertNumberText parses a plain number, such as "42" or "-1.5e3", from a string into a
+// real numeric dst, and a duration, such as "1m30s", into a time.Duration dst, so that
+// text sources such as forms and command lines fill typed fields. Returns false if it
+// does not apply.
+func convertNumberText(dst, src reflect.Value, dstType reflect.Type) bool {
+	if src.Kind() != reflect.String {
+		return false
+	}
+	text := strings.TrimSpace(src.String())
+	if dstType == durationType {
+		d, err := time.ParseDuration(text)
+		if err != nil {
+			panic(&ConversionError{Src: src.Type(), Dst: dstType, Err: err})
+		}
+		dst.SetInt(int64(d))
+		return true
+	}
+	if class, _ := numericInfo(dstType.Kind()); class == classNone || class == classComplex {
+		return false
+	}
+	parsed, err := parseNumber(text, dstType)
+	if err != nil {
+		panic(&ConversionError{Src: src.Type(), Dst: dstType, Err: fmt.Errorf("invalid number %q", src.String())})
+	}
+	dst.Set(parsed)
+	return true
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// normalize rewrites text with the locale's separators as a plain number, such as
+// "1234.56" for "1.234,56". Returns false if separators are misplaced, such as a
+// thousands separator after the decimal mark, or in a group of other than three digits.
+func (loc *numberLocale) normalize(text string) (string, bool) {
+	var b strings.Builder
+	group, grouped, fraction := 0, false, false
+	for _, r := range text {
+		switch {
+		case r == loc.decimal && !fraction:
+			if grouped && group != 3 {
+				return "", false
+			}
+			fraction = true
+			b.WriteByte('.')
+		case r == loc.thousands && r != 0, loc.thousands == ' ' && (r == '\u00a0' || r == '\u202f'):
+			if fraction || (grouped && group != 3) || (!grouped && group == 0) {
+				return "", false
+			}
+			group, grouped = 0, true
+		case r >= '0' && r <= '9':
+			group++
+			b.WriteRune(r)
+		case r == '.' || r == ',':
+			return "", false
+		default:
+			// Signs and exponents are left for the parser to validate.
+			b.WriteRune(r)
+		}
+	}
+	if grouped && !fraction && group != 3 {
+		return "", false
+	}
+	return b.String(), true
+}
+
+// convertText decodes a string or byte slice into a dst whose address implements
+// encoding.TextUnmarshaler, such as uuid.UUID or time.Time. A byte slice is copied
+// instead when dst is a byte slice or array of the same length, as raw bytes from a
+// database are. Returns false if it does not apply.
+func convertText(dst, src reflect.Value, dstType reflect.Type) bool {
+	if !reflect.PtrTo(dstType).Implements(textUnmarshalerType) {
+		return false
+	}
+	var text []byte
+	switch {
+	case src.Kind() == reflect.String:
+		text = []byte(src.String())
+	case isBytes(src.Type()):
+		if dstType.Kind() == reflect.Slice && dstType.Elem().Kind() == reflect.Uint8 ||
+			dstType.Kind() == reflect.Array && dstType.Elem().Kind() == reflect.Uint8 && dstType.Len() == src.Len() {
+			return false
+		}
+		text = src.Bytes()
+	default:
+		return false
+	}
+	if err := dst.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText(text); err != nil {
+		panic(&ConversionError{Src: src.Type(), Dst: dstType, Err: err})
+	}
+	return true
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// isBytes reports whether t is a byte slice type.
+func isBytes(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
+}
+
+// convertByteArray copies a string or byte slice into a fixed-size byte array, such as a
+// [16]byte UUID or [32]byte hash. A [16]byte array also accepts a UUID's text, such as
+// "6ba7b810-9dad-11d1-80b4-00c04fd430c8". Returns false if it does not apply.
+// Panics if the value's length does not match the array's.
+func convertByteArray(dst, src reflect.Value, dstType reflect.Type) bool {
+	if dstType.Kind() != reflect.Array || dstType.Elem().Kind() != reflect.Uint8 {
+		return false
+	}
+	if src.Kind() != reflect.String && !isBytes(src.Type()) {
+		return false
+	}
+	if dstType.Len() == 16 && src.Len() > 16 {
+		var text string
+		if src.Kind() == reflect.String {
+			text = src.String()
+		} else {
+			text = string(src.Bytes())
+		}
+		uuid, err := parseUUID(text)
+		if err != nil {
+			panic(&ConversionError{Src: src.Type(), Dst: dstType, Err: err})
+		}
+		reflect.Copy(dst, reflect.ValueOf(uuid[:]))
+		return true
+	}
+	if src.Len() != dstType.Len() {
+		panic(&ConversionError{Src: src.Type(), Dst: dstType, Err: fmt.Errorf("length %d does not match %d", src.Len(), dstType.Len())})
+	}
+	reflect.Copy(dst, src)
+	return true
+}
+
+// parseUUID parses a UUID written as 32 hexadecimal digits, optionally grouped by hyphens
+// as 8-4-4-4-12, and optionally enclosed in braces or prefixed with "urn:uuid:".
+func parseUUID(text string) (uuid [16]byte, err error) {
+	s := strings.TrimSpace(text)
+	if len(s) >= 9 && strings.EqualFold(s[:9], "urn:uuid:") {
+		s = s[9:]
+	} else if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") {
+		s = s[1 : len(s)-1]
+	}
+	if len(s) == 36 {
+		if s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+			return uuid, fmt.Errorf("invalid UUID %q", text)
+		}
+		s = s[:8] + s[9:13] + s[14:18] + s[19:23] + s[24:]
+	}
+	if len(s) != 32 {
+		return uuid, fmt.Errorf("invalid UUID %q", text)
+	}
+	if _, err := hex.Decode(uuid[:], []byte(s)); err != nil {
+		return uuid, fmt.Errorf("invalid UUID %q", text)
+	}
+	return uuid, nil
+}
+
+// parseNumberList parses a list of numbers separated by commas, semicolons, or spaces
+// into a slice of type sliceType.
+func parseNumberList(list string, sliceType reflect.Type) reflect.Value {
+	items := strings.FieldsFunc(list, func(r rune) bool {
+		return r == ',' || r == ';' || unicode.IsSpace(r)
+	})
+	elemType := sliceType.Elem()
+	slice := reflect.MakeSlice(sliceType, len(items), len(items))
+	for idx, item := range items {
+		parsed, err := parseNumber(item, elemType)
+		if err != nil {
+			panic(fmt.Errorf("cannot convert %q to %s: %w", list, sliceType, err))
+		}
+		slice.Index(idx).Set(parsed)
+	}
+	return slice
+}
+
+// parseNumber parses text as a number of the given real numeric type.
+func parseNumber(text string, typ reflect.Type) (reflect.Value, error) {
+	v := reflect.New(typ).Elem()
+	class, bits := numericInfo(typ.Kind())
+	switch class {
+	case classInt:
+		n, err := strconv.ParseInt(text, 10, bits)
+		v.SetInt(n)
+		return v, err
+	case classUint:
+		n, err := strconv.ParseUint(text, 10, bits)
+		v.SetUint(n)
+		return v, err
+	case classFloat:
+		f, err := strconv.ParseFloat(text, bits)
+		v.SetFloat(f)
+		return v, err
+	}
+	return v, fmt.Errorf("%s is not a real number type", typ)
+}
+
+// complexPart identifies a key that holds one part of a complex number field.
+type complexPart int
+
+const (
+	noPart complexPart = iota
+	realPart
+	imagPart
+)
+
+// complexSuffixes pair keys such as "z_re" and "z_im" into a complex field named "z".
+var complexSuffixes = []struct {
+	suffix string
+	part   complexPart
+}{
+	{"_re", realPart}, {"_im", imagPart},
+	{"_real", realPart}, {"_imag", imagPart},
+	{".re", realPart}, {".im", imagPart},
+}
+
+// complexField returns the complex field and part that key refers to, if any.
+func complexField(key string, lookup func(string) (reflect.StructField, bool)) (reflect.StructField, complexPart) {
+	for _, s := range complexSuffixes {
+		if len(key) > len(s.suffix) && strings.EqualFold(key[len(key)-len(s.suffix):], s.suffix) {
+			field, ok := lookup(key[:len(key)-len(s.suffix)])
+			t := field.Type
+			if ok && t.Kind() == reflect.Ptr {
+				t = t.Elem()
+			}
+			if ok && (t.Kind() == reflect.Complex64 || t.Kind() == reflect.Complex128) {
+				return field, s.part
+			}
+		}
+	}
+	return reflect.StructField{}, noPart
+}
+
+var float64Type = reflect.TypeOf(float64(0))
+
+// assignComplexPart sets the real or imaginary part of a complex dst from src, which may
+// be any real number or a string.
+func assignComplexPart(dst, src reflect.Value, part complexPart, cfg *config) {
+	if dst.Kind() == reflect.Ptr {
+		if dst.IsZero() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		dst = dst.Elem()
+	}
+
+	f := reflect.New(float64Type).Elem()
+	if src = reflect.Indirect(src); src.Kind() == reflect.String {
+		parsed, err := parseNumber(strings.TrimSpace(src.String()), float64Type)
+		if err != nil {
+			panic(fmt.Errorf("cannot convert %q to complex part: %w", src.String(), err))
+		}
+		f = parsed
+	} else {
+		_assign(f, src, cfg)
+	}
+
+	c := dst.Complex()
+	if part == realPart {
+		dst.SetComplex(complex(f.Float(), imag(c)))
+	} else {
+		dst.SetComplex(complex(real(c), f.Float()))
+	}
+}
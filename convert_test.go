@@ -0,0 +1,411 @@
+package absorb_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jyopp/absorb"
+)
+
+func TestComplex(t *testing.T) {
+	type Sample struct {
+		Z       complex128
+		W       *complex64
+		Unknown int
+	}
+
+	var dst []Sample
+	abs := absorb.New(&dst)
+	abs.Open("", 2, "Z", "W_re", "w_im", "ignored")
+	abs.Absorb("1+2i", 3.0, "-4.5", "ignored")
+	abs.Absorb("(0.5-1i)", int64(1), nil, "ignored")
+	abs.Close()
+
+	if dst[0].Z != 1+2i || *dst[0].W != 3-4.5i {
+		t.Fatalf("Unexpected first sample %+v", dst[0])
+	}
+	if dst[1].Z != 0.5-1i || *dst[1].W != 1 {
+		t.Fatalf("Unexpected second sample %+v", dst[1])
+	}
+
+	subpanic(t, "Malformed Complex", func() {
+		var z complex128
+		abs := absorb.New(&z)
+		abs.Open("", 1)
+		abs.Absorb("1+2j")
+	})
+}
+
+func TestNumberList(t *testing.T) {
+	type Series struct {
+		Floats []float64
+		Ints   []int32
+		Bytes  []byte
+	}
+
+	var dst Series
+	abs := absorb.New(&dst)
+	abs.Open("", 1, "Floats", "Ints", "Bytes")
+	abs.Absorb("1.5, 2.5;3 4e2", " -1,2 ", "1, 2")
+	abs.Close()
+
+	// Strings are copied into byte slices as text, not parsed as lists.
+	if expect := (Series{[]float64{1.5, 2.5, 3, 400}, []int32{-1, 2}, []byte("1, 2")}); !reflect.DeepEqual(dst, expect) {
+		t.Fatalf("Expected %+v, got %+v", expect, dst)
+	}
+
+	subpanic(t, "Malformed List", func() {
+		abs := absorb.New(&dst)
+		abs.Open("", 1, "Ints")
+		abs.Absorb("1, 2.5")
+	})
+}
+
+func TestConversionError(t *testing.T) {
+	var dst []TestDst
+	t.Run("Panics", func(t *testing.T) {
+		defer func() {
+			err, _ := recover().(*absorb.ConversionError)
+			if err == nil || err.Key != "Aliased" || err.Src != reflect.TypeOf([]int(nil)) || err.Dst != reflect.TypeOf(0) {
+				t.Fatalf("Expected ConversionError for key Aliased, got %v", err)
+			}
+		}()
+		abs := absorb.New(&dst)
+		abs.Open("test", 1, "Name", "Aliased")
+		abs.Absorb("test", []int{1})
+	})
+
+	abs := absorb.NewV2(&dst)
+	ctx := context.Background()
+	columns := []absorb.ColumnSpec{{Key: "Name"}, {Key: "Aliased"}}
+	if err := abs.Open(ctx, "test", 1, columns...); err != nil {
+		t.Fatal(err)
+	}
+	err := abs.Absorb(ctx, struct{}{}, 1)
+	var convErr *absorb.ConversionError
+	if !errors.As(err, &convErr) || convErr.Key != "Name" {
+		t.Fatal("Expected ConversionError for key Name, got", err)
+	}
+	if msg := err.Error(); msg != `absorb: row 1: cannot convert struct {} to string for key "Name" (field TestDst.Name)` {
+		t.Fatal("Unexpected message", msg)
+	}
+
+	// Slices are converted to arrays only when they are long enough.
+	var arr [4]byte
+	subpanic(t, "Short array", func() {
+		abs := absorb.New(&arr)
+		abs.Open("", 1)
+		abs.Absorb([]byte{1, 2})
+	})
+}
+
+func TestConversionErrorContext(t *testing.T) {
+	type Outer struct {
+		Name  string
+		Small int8
+	}
+	defer func() {
+		err, _ := recover().(*absorb.ConversionError)
+		if err == nil {
+			t.Fatal("Expected ConversionError")
+		}
+		if err.Row != 3 || err.Key != "small" || err.Field != "Outer.Small" {
+			t.Fatalf("Unexpected context in %v", err)
+		}
+		if !errors.Is(err, absorb.ErrNumericRange) {
+			t.Fatal("Expected wrapped ErrNumericRange, got", err.Err)
+		}
+	}()
+	var dst []Outer
+	abs := absorb.New(&dst, absorb.CheckedNumbers(), absorb.Resume(1))
+	abs.Open("", -1, "Name", "small")
+	for _, n := range []int{1000, 1, 1000} {
+		abs.Absorb("name", n)
+	}
+}
+
+// cents is a driver.Valuer, as custom database types are.
+type cents int
+
+func (c cents) Value() (driver.Value, error) {
+	return float64(c) / 100, nil
+}
+
+func TestWrappedValues(t *testing.T) {
+	type Sample struct {
+		Name   string
+		Amount float64
+		Raw    cents
+		Any    interface{}
+	}
+	var dst []Sample
+	abs := absorb.New(&dst)
+	abs.Open("", 2, "Name", "Amount", "Raw", "Any")
+	abs.Absorb(reflect.ValueOf("first"), cents(150), cents(150), reflect.ValueOf(7))
+	abs.Absorb(reflect.Value{}, nil, nil, reflect.Value{})
+	abs.Close()
+
+	expect := []Sample{{"first", 1.5, 150, 7}, {}}
+	if !reflect.DeepEqual(dst, expect) {
+		t.Fatalf("Expected %+v, got %+v", expect, dst)
+	}
+}
+
+func TestNullTypes(t *testing.T) {
+	type Sample struct {
+		Name  sql.NullString
+		Count sql.NullInt64
+		When  sql.NullTime
+		Ratio *sql.NullFloat64
+	}
+	when := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	var dst []Sample
+	abs := absorb.New(&dst)
+	abs.Open("", 2, "Name", "Count", "When", "Ratio")
+	abs.Absorb("ann", 3, when, "0.5")
+	abs.Absorb(nil, nil, nil, nil)
+	abs.Close()
+
+	ratio := sql.NullFloat64{Float64: 0.5, Valid: true}
+	expect := []Sample{
+		{sql.NullString{String: "ann", Valid: true}, sql.NullInt64{Int64: 3, Valid: true}, sql.NullTime{Time: when, Valid: true}, &ratio},
+		{},
+	}
+	if !reflect.DeepEqual(dst, expect) {
+		t.Fatalf("Expected %+v, got %+v", expect, dst)
+	}
+
+	// Nil values reset fields that were already valid.
+	single := expect[0]
+	abs = absorb.New(&single)
+	abs.Open("", 1, "Name", "Count")
+	abs.Absorb(nil, nil)
+	abs.Close()
+	if single.Name.Valid || single.Count.Valid || !single.When.Valid {
+		t.Fatalf("Unexpected fields after absorbing nil: %+v", single)
+	}
+
+	subpanic(t, "Unscannable", func() {
+		abs := absorb.New(&dst)
+		abs.Open("", 1, "Count")
+		abs.Absorb("many")
+	})
+}
+
+func TestBytesStrings(t *testing.T) {
+	type Label string
+	type Sample struct {
+		Text  string
+		Blob  []byte
+		Label *Label
+		Raw   json.RawMessage
+	}
+	buf := []byte("text")
+	var dst []Sample
+	abs := absorb.New(&dst)
+	abs.Open("", 1, "Text", "Blob", "Label", "Raw")
+	abs.Absorb(buf, "blob", []byte("label"), `{"a":1}`)
+	abs.Close()
+	// Strings are copied out of the source's buffers.
+	buf[0] = 'n'
+
+	label := Label("label")
+	expect := []Sample{{"text", []byte("blob"), &label, json.RawMessage(`{"a":1}`)}}
+	if !reflect.DeepEqual(dst, expect) {
+		t.Fatalf("Expected %+v, got %+v", expect, dst)
+	}
+}
+
+func TestByteArrays(t *testing.T) {
+	type Sample struct {
+		Hash [4]byte
+		Code *[2]byte
+	}
+	var dst []Sample
+	abs := absorb.New(&dst)
+	abs.Open("", 1, "Hash", "Code")
+	abs.Absorb([]byte{1, 2, 3, 4}, "ab")
+	abs.Close()
+	code := [2]byte{'a', 'b'}
+	if expect := []Sample{{[4]byte{1, 2, 3, 4}, &code}}; !reflect.DeepEqual(dst, expect) {
+		t.Fatalf("Expected %+v, got %+v", expect, dst)
+	}
+
+	for _, value := range []interface{}{[]byte{1, 2, 3}, []byte{1, 2, 3, 4, 5}, "abc"} {
+		func() {
+			defer func() {
+				err, _ := recover().(*absorb.ConversionError)
+				if err == nil || err.Key != "Hash" || err.Err == nil {
+					t.Fatalf("Expected a length mismatch for %v, got %v", value, err)
+				}
+			}()
+			abs := absorb.New(&dst)
+			abs.Open("", 1, "Hash")
+			abs.Absorb(value)
+		}()
+	}
+}
+
+// uuidText stands in for UUID types such as uuid.UUID, which decode their own text.
+type uuidText [16]byte
+
+func (u *uuidText) UnmarshalText(text []byte) error {
+	if len(text) != 36 {
+		return errors.New("invalid UUID")
+	}
+	_, err := hex.Decode(u[:], []byte(strings.ReplaceAll(string(text), "-", "")))
+	return err
+}
+
+func TestUUIDs(t *testing.T) {
+	type Sample struct {
+		ID     [16]byte
+		Ref    *uuidText
+		Parent uuidText
+		At     time.Time
+	}
+	const text = "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+	raw, _ := hex.DecodeString("6ba7b8109dad11d180b400c04fd430c8")
+	var dst []Sample
+	abs := absorb.New(&dst)
+	abs.Open("", 2, "ID", "Ref", "Parent", "At")
+	abs.Absorb(text, []byte(text), raw, "2024-05-01T12:00:00Z")
+	abs.Absorb("{"+strings.ToUpper(text)+"}", text, text, []byte("2024-05-01T12:00:00Z"))
+	abs.Close()
+
+	var id [16]byte
+	copy(id[:], raw)
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for idx, row := range dst {
+		if row.ID != id || *row.Ref != uuidText(id) || row.Parent != uuidText(id) || !row.At.Equal(at) {
+			t.Fatalf("Row %d: unexpected values %+v", idx, row)
+		}
+	}
+
+	for key, value := range map[string]interface{}{"ID": "6ba7b810-9dad-11d1-80b4-00c04fd430cz", "Ref": "6ba7b810"} {
+		func() {
+			defer func() {
+				if err, _ := recover().(*absorb.ConversionError); err == nil || err.Key != key || err.Err == nil {
+					t.Fatalf("Expected a conversion error for %v, got %v", value, err)
+				}
+			}()
+			abs := absorb.New(&dst)
+			abs.Open("", 1, key)
+			abs.Absorb(value)
+		}()
+	}
+}
+
+func TestBools(t *testing.T) {
+	type Flags struct {
+		Active  bool
+		Deleted *bool
+	}
+	var dst []Flags
+	abs := absorb.New(&dst)
+	abs.Open("", 4, "Active", "Deleted")
+	abs.Absorb("yes", "N")
+	abs.Absorb(" 1 ", "0")
+	abs.Absorb("T", "false")
+	abs.Absorb("On", "off")
+	abs.Close()
+	for idx, row := range dst {
+		if !row.Active || row.Deleted == nil || *row.Deleted {
+			t.Fatalf("Row %d: unexpected values %+v", idx, row)
+		}
+	}
+
+	abs = absorb.New(&dst, absorb.BoolStrings([]string{"ja"}, []string{"nein"}))
+	abs.Open("", 1, "Active", "Deleted")
+	abs.Absorb("JA", "nein")
+	abs.Close()
+	if !dst[0].Active || *dst[0].Deleted {
+		t.Fatalf("Unexpected values %+v", dst[0])
+	}
+	subpanic(t, "Replaced Strings", func() {
+		abs := absorb.New(&dst, absorb.BoolStrings([]string{"ja"}, []string{"nein"}))
+		abs.Open("", 1, "Active")
+		abs.Absorb("yes")
+	})
+	subpanic(t, "Invalid", func() {
+		abs := absorb.New(&dst)
+		abs.Open("", 1, "Active")
+		abs.Absorb("maybe")
+	})
+}
+
+func TestNumberLocale(t *testing.T) {
+	type Amounts struct {
+		Total float64
+		Count int
+		Units *uint16
+	}
+	var dst []Amounts
+	abs := absorb.New(&dst, absorb.NumberLocale('.', ','))
+	abs.Open("", 2, "Total", "Count", "Units")
+	abs.Absorb("1.234,56", "1.000", "12,00")
+	abs.Absorb(" -0,5 ", "42", "7")
+	abs.Close()
+	if dst[0].Total != 1234.56 || dst[0].Count != 1000 || *dst[0].Units != 12 ||
+		dst[1].Total != -0.5 || dst[1].Count != 42 || *dst[1].Units != 7 {
+		t.Fatalf("Unexpected values %+v", dst)
+	}
+
+	abs = absorb.New(&dst, absorb.NumberLocale(' ', ','))
+	abs.Open("", 1, "Total", "Count")
+	abs.Absorb("1 234,5", "1 000")
+	abs.Close()
+	if dst[0].Total != 1234.5 || dst[0].Count != 1000 {
+		t.Fatalf("Unexpected values %+v", dst[0])
+	}
+
+	for key, value := range map[string]string{"Total": "1,234.56", "Count": "1,5", "Units": "70.000"} {
+		func() {
+			defer func() {
+				if err, _ := recover().(*absorb.ConversionError); err == nil || err.Key != key {
+					t.Fatalf("Expected a conversion error for %q, got %v", value, err)
+				}
+			}()
+			abs := absorb.New(&dst, absorb.NumberLocale('.', ','))
+			abs.Open("", 1, key)
+			abs.Absorb(value)
+		}()
+	}
+}
+
+func TestNumberText(t *testing.T) {
+	type Settings struct {
+		Port    uint16
+		Ratio   float32
+		Retries *int
+		Timeout time.Duration
+	}
+	var dst Settings
+	abs := absorb.New(&dst)
+	abs.Open("", 1, "Port", "Ratio", "Retries", "Timeout")
+	abs.Absorb("8080", " 0.25", "-3", "250ms")
+	abs.Close()
+
+	if dst.Port != 8080 || dst.Ratio != 0.25 || dst.Retries == nil || *dst.Retries != -3 || dst.Timeout != 250*time.Millisecond {
+		t.Fatalf("Unexpected settings %+v", dst)
+	}
+
+	subpanic(t, "Out of Range", func() {
+		abs := absorb.New(&dst)
+		abs.Open("", 1, "Port")
+		abs.Absorb("70000")
+	})
+	subpanic(t, "Malformed Duration", func() {
+		abs := absorb.New(&dst)
+		abs.Open("", 1, "Timeout")
+		abs.Absorb("soon")
+	})
+}
@@ -0,0 +1,45 @@
+package absorb
+
+import (
+	"runtime"
+	"runtime/debug"
+	"sync/atomic"
+)
+
+// leakHandler wraps the current leak reporting function so it can be stored atomically.
+type leakHandler struct {
+	report func(stack []byte)
+}
+
+var leakDetection atomic.Value
+
+// DetectLeaks enables a debug mode that reports Absorbers which were Opened but
+// never Closed. When such an Absorber is garbage collected, report is called with
+// the stack trace captured when the Absorber was created.
+//
+// Only Absorbers created after the call are tracked. Pass nil to disable detection.
+// Tracking captures a stack trace per Absorber, and is not intended for production use.
+//
+// Example:
+//
+//	absorb.DetectLeaks(func(stack []byte) {
+//		log.Printf("absorber was never closed; created at:\n%s", stack)
+//	})
+func DetectLeaks(report func(stack []byte)) {
+	leakDetection.Store(leakHandler{report: report})
+}
+
+// trackLeaks records the caller's stack and arranges for a finalizer to report a if it
+// is still open when collected. Does nothing unless DetectLeaks has been enabled.
+func trackLeaks(a *absorberImpl) {
+	handler, _ := leakDetection.Load().(leakHandler)
+	if handler.report == nil {
+		return
+	}
+	a.stack = debug.Stack()
+	runtime.SetFinalizer(a, func(a *absorberImpl) {
+		if a.state == lifecycleOpen {
+			handler.report(a.stack)
+		}
+	})
+}
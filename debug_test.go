@@ -0,0 +1,49 @@
+package absorb_test
+
+import (
+	"bytes"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/jyopp/absorb"
+)
+
+func TestDetectLeaks(t *testing.T) {
+	leaks := make(chan []byte, 2)
+	absorb.DetectLeaks(func(stack []byte) {
+		leaks <- stack
+	})
+	defer absorb.DetectLeaks(nil)
+
+	func() {
+		var closed, leaked []TestDst
+		abs := absorb.New(&closed)
+		abs.Open("test", 0, "Name")
+		abs.Close()
+
+		absorb.New(&leaked).Open("test", 0, "Name")
+	}()
+
+	deadline := time.After(5 * time.Second)
+	for {
+		runtime.GC()
+		select {
+		case stack := <-leaks:
+			if !bytes.Contains(stack, []byte("TestDetectLeaks")) {
+				t.Fatalf("Leak report does not contain creation stack:\n%s", stack)
+			}
+			// Only the unclosed absorber may be reported.
+			runtime.GC()
+			select {
+			case <-leaks:
+				t.Fatal("Closed absorber was reported as leaked")
+			case <-time.After(50 * time.Millisecond):
+			}
+			return
+		case <-deadline:
+			t.Fatal("Unclosed absorber was not reported")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package absorb
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// DecodeMap decodes src into dst, a pointer to a struct or map, in a single row. Struct
+// fields are matched to the keys of src by the given struct tag, then by name, exactly
+// as an Absorber matches them, and options such as Converter and Strict apply.
+//
+// Nested values are decoded too: maps with string keys into struct fields, and slices
+// and maps into slices and maps of other element types, such as a []interface{} of
+// maps into a []Address field. This makes DecodeMap a replacement for mapstructure's
+// Decode, sharing one mapping with every Absorber.
+func DecodeMap(dst interface{}, src map[string]interface{}, tag string, opts ...Option) (err error) {
+	defer recoverError(&err)
+	// Nested structs are mapped with the same tag as dst.
+	opts = append(opts[:len(opts):len(opts)], Tags(tag))
+	a := New(dst, opts...)
+	keys, values := sortedRow(reflect.ValueOf(src))
+	a.Open(tag, 1, keys...)
+	a.Absorb(values...)
+	a.Close()
+	return nil
+}
+
+// Strict panics with an error wrapping ErrUnknownKey when Open is called with a key that
+// does not map to a field of the struct element, or when a nested map decoded into a
+// struct has such a key. Columns named by GroupBy, IndexBy, and Operations are exempt.
+func Strict() Option {
+	return func(c *config) {
+		c.strict = true
+	}
+}
+
+// checkMapped panics if a key does not map to a field of a struct element.
+func (a *elementBuilder) checkMapped(cfg *config) {
+	if a.Type.Kind() != reflect.Struct {
+		return
+	}
+	for idx, key := range a.Keys {
+		if !a.mapped(idx, cfg) {
+			panic(fmt.Errorf("%w: %q in %s", ErrUnknownKey, key, a.Type))
+		}
+	}
+}
+
+// mapped reports whether the key at idx maps to a field of a struct element, or names a
+// column used by GroupBy, IndexBy, or Operations.
+func (a *elementBuilder) mapped(idx int, cfg *config) bool {
+	key := a.Keys[idx]
+	return a.Fields[idx].Index != nil || key == cfg.groupBy || key == cfg.indexBy || key == cfg.opColumn
+}
+
+// sortedRow returns the keys of m in order, and their values.
+func sortedRow(m reflect.Value) ([]string, []interface{}) {
+	keys := make([]string, 0, m.Len())
+	iter := m.MapRange()
+	for iter.Next() {
+		keys = append(keys, iter.Key().String())
+	}
+	sort.Strings(keys)
+	values := make([]interface{}, len(keys))
+	for idx, key := range keys {
+		values[idx] = valueOf(m.MapIndex(reflect.ValueOf(key).Convert(m.Type().Key())))
+	}
+	return keys, values
+}
+
+// convertNested decodes a map with string keys into a struct dst, using the tags given
+// with the Tags option, and converts slices, arrays, and maps element by element into
+// dst types with different elements. Returns false if it does not apply.
+func convertNested(dst, src reflect.Value, dstType reflect.Type, cfg *config) bool {
+	srcKind := src.Kind()
+	if src.Type().ConvertibleTo(dstType) {
+		return false
+	}
+	switch dstType.Kind() {
+	case reflect.Struct:
+		if srcKind != reflect.Map || src.Type().Key().Kind() != reflect.String {
+			return false
+		}
+		keys, values := sortedRow(src)
+		keys = cfg.normalize(keys)
+		override := cfg.overrides[dstType.String()]
+		builder := getBuilder(cfg.registry, dstType, cfg.tagChain(""), keys, override, cfg.matcher)
+		if cfg.strict {
+			builder.checkMapped(cfg)
+		}
+		builder.absorb(dst, values, cfg, nil)
+	case reflect.Slice, reflect.Array:
+		if srcKind != reflect.Slice && srcKind != reflect.Array || isBytes(src.Type()) || isBytes(dstType) {
+			return false
+		}
+		n := src.Len()
+		if dstType.Kind() == reflect.Slice {
+			dst.Set(reflect.MakeSlice(dstType, n, n))
+		} else if n > dstType.Len() {
+			panic(fmt.Errorf("%d elements do not fit in %s", n, dstType))
+		}
+		for i := 0; i < n; i++ {
+			if elem := src.Index(i); valueOf(elem) != nil {
+				_assign(dst.Index(i), reflect.ValueOf(valueOf(elem)), cfg)
+			}
+		}
+	case reflect.Map:
+		if srcKind != reflect.Map {
+			return false
+		}
+		m := reflect.MakeMapWithSize(dstType, src.Len())
+		key, value := reflect.New(dstType.Key()).Elem(), reflect.New(dstType.Elem()).Elem()
+		iter := src.MapRange()
+		for iter.Next() {
+			key.Set(reflect.Zero(key.Type()))
+			value.Set(reflect.Zero(value.Type()))
+			_assign(key, reflect.ValueOf(valueOf(iter.Key())), cfg)
+			if v := valueOf(iter.Value()); v != nil {
+				_assign(value, reflect.ValueOf(v), cfg)
+			}
+			m.SetMapIndex(key, value)
+		}
+		dst.Set(m)
+	default:
+		return false
+	}
+	return true
+}
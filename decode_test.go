@@ -0,0 +1,95 @@
+package absorb_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jyopp/absorb"
+)
+
+type address struct {
+	Street string `json:"street"`
+	City   string `json:"city"`
+}
+
+type customer struct {
+	Name      string            `json:"name"`
+	Age       int               `json:"age"`
+	Home      *address          `json:"home"`
+	Offices   []address         `json:"offices"`
+	Scores    []float64         `json:"scores"`
+	Labels    map[string]string `json:"labels"`
+	Joined    time.Time         `json:"joined"`
+	Untouched string
+}
+
+func TestDecodeMap(t *testing.T) {
+	src := map[string]interface{}{
+		"name": "ann",
+		"age":  int64(41),
+		"home": map[string]interface{}{"street": "1 Main St", "city": "Springfield"},
+		"offices": []interface{}{
+			map[string]interface{}{"city": "Shelbyville"},
+			map[string]interface{}{"street": "2 Elm St", "city": "Capital City"},
+		},
+		"scores": []interface{}{1, 2.5, int64(3)},
+		"labels": map[string]interface{}{"tier": "gold"},
+		"joined": "2024-05-01T00:00:00Z",
+	}
+	dst := customer{Untouched: "kept"}
+	if err := absorb.DecodeMap(&dst, src, "json"); err != nil {
+		t.Fatal(err)
+	}
+	expect := customer{
+		Name:      "ann",
+		Age:       41,
+		Home:      &address{"1 Main St", "Springfield"},
+		Offices:   []address{{City: "Shelbyville"}, {"2 Elm St", "Capital City"}},
+		Scores:    []float64{1, 2.5, 3},
+		Labels:    map[string]string{"tier": "gold"},
+		Joined:    time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		Untouched: "kept",
+	}
+	if !reflect.DeepEqual(dst, expect) {
+		t.Fatalf("Expected %+v, got %+v", expect, dst)
+	}
+
+	// Maps decode into maps of other value types.
+	var counts map[string]int
+	if err := absorb.DecodeMap(&counts, map[string]interface{}{"a": 1, "b": int64(2)}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(counts, map[string]int{"a": 1, "b": 2}) {
+		t.Fatalf("Unexpected map %v", counts)
+	}
+
+	var convErr *absorb.ConversionError
+	if err := absorb.DecodeMap(&dst, map[string]interface{}{"age": "old"}, "json"); !errors.As(err, &convErr) || convErr.Key != "age" {
+		t.Fatalf("Expected a conversion error, got %v", err)
+	}
+}
+
+func TestStrict(t *testing.T) {
+	var dst customer
+	src := map[string]interface{}{"name": "ann", "nickname": "annie"}
+	if err := absorb.DecodeMap(&dst, src, "json"); err != nil {
+		t.Fatal(err)
+	}
+	if err := absorb.DecodeMap(&dst, src, "json", absorb.Strict()); !errors.Is(err, absorb.ErrUnknownKey) {
+		t.Fatalf("Expected ErrUnknownKey, got %v", err)
+	}
+	nested := map[string]interface{}{"home": map[string]interface{}{"zip": "12345"}}
+	if err := absorb.DecodeMap(&dst, nested, "json", absorb.Strict()); !errors.Is(err, absorb.ErrUnknownKey) {
+		t.Fatalf("Expected ErrUnknownKey for a nested key, got %v", err)
+	}
+
+	var rows []TestDst
+	if err := absorb.Absorb(&rows, testSource{i: 1}, absorb.Strict()); err != nil {
+		t.Fatal(err)
+	}
+	subpanic(t, "Unknown Key", func() {
+		absorb.New(&rows, absorb.Strict()).Open("test", 1, "Name", "Missing")
+	})
+}
@@ -0,0 +1,122 @@
+package absorb
+
+import (
+	"container/list"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Dedupe returns an Absorber that drops rows whose values for the given keys were already
+// passed to next since it was opened. If no keys are given, all of a row's values are
+// compared. Open panics with ErrMissingKey if any of the keys is not present.
+//
+// Values are equal if they have the same type and are equal by ==, except that byte
+// slices are compared by content, times by the instant they represent, and other values
+// that are not comparable, such as slices, by their Go syntax. If limit is positive, only
+// the limit most recently seen rows are remembered, so that memory is bounded; A
+// duplicate of a row forgotten since is passed to next again.
+func Dedupe(next Absorber, limit int, keys ...string) Absorber {
+	return &dedupeAbsorber{middleware: middleware{next: next}, keys: keys, limit: limit}
+}
+
+type dedupeAbsorber struct {
+	middleware
+	keys  []string
+	limit int
+	// columns are the indexes of the compared values.
+	columns []int
+	// keyType is an array of one interface{} for each compared value, whose values are
+	// comparable keys for the rows.
+	keyType reflect.Type
+	seen    map[interface{}]*list.Element
+	// recent orders seen rows from most to least recently seen, when limited.
+	recent *list.List
+}
+
+func (d *dedupeAbsorber) Open(tag string, count int, keys ...string) {
+	if len(d.keys) > 0 {
+		d.columns = make([]int, len(d.keys))
+		for i, k := range d.keys {
+			d.columns[i] = -1
+			for idx, key := range keys {
+				if k == key {
+					d.columns[i] = idx
+					break
+				}
+			}
+			if d.columns[i] < 0 {
+				panic(fmt.Errorf("%w: cannot dedupe rows by %q", ErrMissingKey, k))
+			}
+		}
+	} else {
+		d.columns = make([]int, len(keys))
+		for idx := range keys {
+			d.columns[idx] = idx
+		}
+	}
+	d.keyType = reflect.ArrayOf(len(d.columns), interfaceType)
+	d.seen = make(map[interface{}]*list.Element)
+	d.recent = list.New()
+	d.middleware.Open(tag, count, keys...)
+}
+
+func (d *dedupeAbsorber) Absorb(values ...interface{}) {
+	if len(values) != len(d.middleware.keys) {
+		panic(fmt.Errorf("%w: %d values for %d keys", ErrArity, len(values), len(d.middleware.keys)))
+	}
+	key := reflect.New(d.keyType).Elem()
+	for i, idx := range d.columns {
+		if value := dedupeValue(values[idx]); value != nil {
+			key.Index(i).Set(reflect.ValueOf(value))
+		}
+	}
+	id := key.Interface()
+
+	if elem, ok := d.seen[id]; ok {
+		if d.limit > 0 {
+			d.recent.MoveToFront(elem)
+		}
+		return
+	}
+	var elem *list.Element
+	if d.limit > 0 {
+		elem = d.recent.PushFront(id)
+		if d.recent.Len() > d.limit {
+			delete(d.seen, d.recent.Remove(d.recent.Back()))
+		}
+	}
+	d.seen[id] = elem
+	d.next.Absorb(values...)
+}
+
+func (d *dedupeAbsorber) Close() {
+	d.seen, d.recent = nil, nil
+	d.middleware.Close()
+}
+
+var interfaceType = reflect.TypeOf((*interface{})(nil)).Elem()
+
+// dedupeBytes holds the content of a byte slice, distinct from a string with the same text.
+type dedupeBytes string
+
+// dedupeSyntax holds the Go syntax of a value that is not comparable.
+type dedupeSyntax string
+
+// dedupeValue returns a comparable value that is equal for values that Dedupe considers
+// equal. Byte slices are copied, since sources may reuse their buffers.
+func dedupeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return dedupeBytes(v)
+	case time.Time:
+		// Round(0) strips the monotonic reading.
+		return v.UTC().Round(0)
+	}
+	if !reflect.TypeOf(value).Comparable() {
+		return dedupeSyntax(fmt.Sprintf("%#v", value))
+	}
+	return value
+}
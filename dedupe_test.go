@@ -0,0 +1,83 @@
+package absorb_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jyopp/absorb"
+)
+
+// repeatSource emits the given Aliased values, all named "test".
+type repeatSource []int
+
+func (rs repeatSource) Emit(into absorb.Absorber) error {
+	into.Open("test", len(rs), "Name", "Aliased")
+	defer into.Close()
+	for _, i := range rs {
+		into.Absorb("test", i)
+	}
+	return nil
+}
+
+func TestDedupe(t *testing.T) {
+	src := repeatSource{1, 2, 1, 3, 2, 1, 4}
+	for _, test := range []struct {
+		name   string
+		limit  int
+		keys   []string
+		expect []int
+	}{
+		{"All Values", 0, nil, []int{1, 2, 3, 4}},
+		{"Key Column", 0, []string{"Name"}, []int{1}},
+		{"Limited", 2, []string{"Aliased"}, []int{1, 2, 3, 2, 1, 4}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var dst []TestDst
+			if err := absorb.Pipe(src).Dedupe(test.limit, test.keys...).Into(&dst); err != nil {
+				t.Fatal(err)
+			}
+			var got []int
+			for _, d := range dst {
+				got = append(got, d.Actual)
+			}
+			if !reflect.DeepEqual(got, test.expect) {
+				t.Fatalf("Expected %v, got %v", test.expect, got)
+			}
+		})
+	}
+}
+
+func TestDedupeValues(t *testing.T) {
+	// Rows that would format identically if their values were joined are distinct.
+	src := messySource{{"a\x00string:b", "c"}, {"a", "b\x00string:c"}, {"a", "b\x00string:c"}}
+	var dst []map[string]interface{}
+	if err := absorb.Pipe(src).Dedupe(0).Into(&dst); err != nil {
+		t.Fatal(err)
+	}
+	if len(dst) != 2 {
+		t.Fatalf("Expected 2 rows, got %v", dst)
+	}
+
+	// Times are compared by instant, regardless of location or monotonic reading.
+	now := time.Now()
+	times := messySource{{"a", now}, {"a", now.Round(0).In(time.FixedZone("X", 3600))}, {"a", now.Add(1)}}
+	var rows []map[string]interface{}
+	if err := absorb.Pipe(times).Dedupe(0, "Aliased").Into(&rows); err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("Expected 2 rows, got %v", rows)
+	}
+
+	subpanic(t, "Short Row", func() {
+		var dst []TestDst
+		d := absorb.Dedupe(absorb.New(&dst), 0, "Aliased")
+		d.Open("test", -1, "Name", "Aliased")
+		d.Absorb("test")
+	})
+	subpanic(t, "Missing Key", func() {
+		var dst []TestDst
+		absorb.Dedupe(absorb.New(&dst), 0, "ID").Open("test", -1, "Name", "Aliased")
+	})
+}
@@ -0,0 +1,96 @@
+package absorb
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// DuplicatePolicy determines how an Absorber handles a key passed to Open more than once,
+// such as a query selecting two columns named "id".
+type DuplicatePolicy int
+
+const (
+	// DuplicatesLast absorbs the value of the last column with a duplicated key, and
+	// ignores the others. This is the default.
+	DuplicatesLast DuplicatePolicy = iota
+	// DuplicatesFirst absorbs the value of the first column with a duplicated key.
+	DuplicatesFirst
+	// DuplicatesRename keeps every column, suffixing the second and later occurrences of
+	// a key with their number, such as "id_2". Suffixed keys that are already in use are
+	// skipped.
+	DuplicatesRename
+	// DuplicatesError panics with an error wrapping ErrDuplicateKey.
+	DuplicatesError
+)
+
+// DuplicateKeys sets the policy for keys passed to Open more than once.
+func DuplicateKeys(policy DuplicatePolicy) Option {
+	return func(c *config) {
+		c.duplicates = policy
+	}
+}
+
+// resolveDuplicates returns keys without duplicates, according to the policy. If columns
+// are dropped, it also returns the indexes of the columns to keep, in order.
+func (c *config) resolveDuplicates(keys []string) ([]string, []int) {
+	seen := make(map[string]int, len(keys))
+	dupes := false
+	for _, key := range keys {
+		seen[key]++
+		dupes = dupes || seen[key] > 1
+	}
+	if !dupes {
+		return keys, nil
+	}
+
+	switch c.duplicates {
+	case DuplicatesRename:
+		renamed := make([]string, len(keys))
+		count := make(map[string]int, len(keys))
+		for idx, key := range keys {
+			count[key]++
+			for n := count[key]; n > 1; n++ {
+				suffixed := key + "_" + strconv.Itoa(n)
+				if _, ok := seen[suffixed]; !ok {
+					seen[suffixed], count[key] = 1, n
+					key = suffixed
+					break
+				}
+			}
+			renamed[idx] = key
+		}
+		return renamed, nil
+	case DuplicatesError:
+		for _, key := range keys {
+			if seen[key] > 1 {
+				panic(fmt.Errorf("%w: %q appears %d times", ErrDuplicateKey, key, seen[key]))
+			}
+		}
+	}
+
+	// Keep one column for each key, the first or last of its occurrences.
+	chosen := make(map[string]int, len(seen))
+	for idx, key := range keys {
+		if _, ok := chosen[key]; !ok || c.duplicates != DuplicatesFirst {
+			chosen[key] = idx
+		}
+	}
+	kept := make([]string, 0, len(chosen))
+	keep := make([]int, 0, len(chosen))
+	for idx, key := range keys {
+		if chosen[key] == idx {
+			kept = append(kept, key)
+			keep = append(keep, idx)
+		}
+	}
+	return kept, keep
+}
+
+// keepColumns returns the values of the columns at the given indexes.
+func keepColumns(values []interface{}, keep []int) []interface{} {
+	kept := make([]interface{}, len(keep))
+	for idx, col := range keep {
+		kept[idx] = values[col]
+	}
+	return kept
+}
@@ -0,0 +1,52 @@
+package absorb_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/jyopp/absorb"
+)
+
+func TestDuplicateKeys(t *testing.T) {
+	keys := []string{"id", "Name", "id", "id_2", "id"}
+	row := []interface{}{1, "ann", 2, 3, nil}
+
+	type Row struct {
+		ID   interface{} `test:"id"`
+		ID2  interface{} `test:"id_2"`
+		ID3  interface{} `test:"id_3"`
+		Name string
+	}
+	for policy, expect := range map[absorb.DuplicatePolicy]Row{
+		absorb.DuplicatesLast:   {ID: nil, ID2: 3, Name: "ann"},
+		absorb.DuplicatesFirst:  {ID: 1, ID2: 3, Name: "ann"},
+		absorb.DuplicatesRename: {ID: 1, ID2: 3, ID3: 2, Name: "ann"},
+	} {
+		var dst Row
+		abs := absorb.New(&dst, absorb.DuplicateKeys(policy))
+		abs.Open("test", 1, keys...)
+		abs.Absorb(row...)
+		abs.Close()
+		if dst != expect {
+			t.Errorf("Policy %d: expected %+v, got %+v", policy, expect, dst)
+		}
+	}
+
+	// Maps are keyed the same way; The last "id" is renamed past the existing "id_2".
+	var m map[string]interface{}
+	abs := absorb.New(&m, absorb.DuplicateKeys(absorb.DuplicatesRename))
+	abs.Open("", 1, keys...)
+	abs.Absorb(1, "ann", 2, 3, 4)
+	abs.Close()
+	if expect := map[string]interface{}{"id": 1, "Name": "ann", "id_3": 2, "id_2": 3, "id_4": 4}; !reflect.DeepEqual(m, expect) {
+		t.Fatalf("Expected %v, got %v", expect, m)
+	}
+
+	defer func() {
+		if err, _ := recover().(error); !errors.Is(err, absorb.ErrDuplicateKey) {
+			t.Fatalf("Expected ErrDuplicateKey, got %v", err)
+		}
+	}()
+	absorb.New(&m, absorb.DuplicateKeys(absorb.DuplicatesError)).Open("", 1, keys...)
+}
@@ -1,9 +1,12 @@
 package absorb
 
 import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"reflect"
+	"sort"
 	"strings"
-	"sync"
 )
 
 type elementBuilder struct {
@@ -11,78 +14,146 @@ type elementBuilder struct {
 	// Keys contains the array of keys, used to get key names for map[string] types.
 	Keys []string
 	// Field indexes are a *set* of integer indices used to reach a struct field.
+	// Keys that do not map to any field have a nil Index.
 	Fields []reflect.StructField
+	// Setters holds a setter compiled for each key's field, or nil for unmapped keys.
+	Setters []fieldSetter
+	// Nullable is true for keys whose fields are reset by nil values, such as
+	// sql.NullString fields. Other setters are only called with non-nil values.
+	Nullable []bool
+	// Plain is true for keys whose setters convert values without a complex part, unit,
+	// or codec.
+	Plain []bool
 }
 
-var cachedAbsorbers sync.Map
+// fieldSetter assigns a non-nil value to one field of a struct element.
+// Each setter is specialized for its field when the builder is created, so that per-row
+// work is limited to the conversion of the value itself.
+type fieldSetter func(elem reflect.Value, value interface{}, cfg *config)
 
-func getBuildersForType(t reflect.Type) *sync.Map {
-	i, ok := cachedAbsorbers.Load(t)
-	if !ok {
-		i, _ = cachedAbsorbers.LoadOrStore(t, &sync.Map{})
+// getBuilder returns the builder for elements of type elemTyp, with the codecs of reg,
+// which is the registry snapshot of the Absorber's config.
+func getBuilder(reg *registry, elemTyp reflect.Type, tags []string, keys []string, override *Override, matcher *keyMatcher) *elementBuilder {
+	compoundKey := strings.Join(tags, ",") + ":" + strings.Join(keys, "+")
+	if remap := override.fingerprint(); remap != "" {
+		compoundKey += ":" + remap
+	}
+	match, cacheable := matcher.fingerprint()
+	if !cacheable {
+		return newBuilder(reg, elemTyp, tags, keys, override, matcher)
+	} else if match != "" {
+		compoundKey += ":" + match
 	}
-	return i.(*sync.Map)
+	return cachedBuilders.get(builderKey{elemTyp, reg.codecVersion, compoundKey}, func() *elementBuilder {
+		return newBuilder(reg, elemTyp, tags, keys, override, matcher)
+	})
 }
 
-func getBuilder(elemTyp reflect.Type, tag string, keys []string) *elementBuilder {
-	absorbers := getBuildersForType(elemTyp)
-
-	compoundKey := tag + ":" + strings.Join(keys, "+")
-	i, ok := absorbers.Load(compoundKey)
-	if !ok {
-		toPut := newBuilder(elemTyp, tag, keys)
-		i, _ = absorbers.LoadOrStore(compoundKey, toPut)
+// lookupTag returns the value of the first tag in tags that is present on field.
+func lookupTag(field reflect.StructField, tags []string) (string, bool) {
+	for _, tag := range tags {
+		if tagVal, ok := field.Tag.Lookup(tag); ok {
+			return tagVal, true
+		}
 	}
-	return i.(*elementBuilder)
+	return "", false
 }
 
-func newBuilder(elemTyp reflect.Type, tag string, keys []string) *elementBuilder {
+func newBuilder(reg *registry, elemTyp reflect.Type, tags []string, keys []string, override *Override, matcher *keyMatcher) *elementBuilder {
 	a := &elementBuilder{
 		Type: elemTyp,
 		Keys: keys,
 	}
 
 	if elemTyp.Kind() == reflect.Struct {
-		mappedFields := make(map[string]reflect.StructField)
-		for i := 0; i < elemTyp.NumField(); i++ {
-			field := elemTyp.Field(i)
-			if tagVal, ok := field.Tag.Lookup(tag); ok {
+		mappedFields := &fieldNames{fields: make(map[string]reflect.StructField)}
+		fieldOpts := make(map[string]tagOptions)
+		for _, field := range structFields(elemTyp, tags) {
+			add := mappedFields.add
+			if len(field.Index) > 1 {
+				// Promoted fields never displace the names of shallower fields.
+				add = mappedFields.addNew
+			}
+			if tagVal, ok := lookupTag(field, tags); ok {
 				// If a field has a matching struct tag, ONLY the tag is used.
 				// If the tag is explicitly empty, the field is excluded.
+				tagVal, opts := parseTag(tagVal)
 				if tagVal != "" {
-					mappedFields[tagVal] = field
+					add(tagVal, field)
+				}
+				if opts != "" {
+					fieldOpts[field.Name] = opts
 				}
 			} else {
 				// Use the field's name and its lowercased name for matching.
-				mappedFields[field.Name] = field
+				add(field.Name, field)
 				lowered := strings.ToLower(field.Name)
 				// Lowercased names are set conditionally, to avoid clobbering tags & other fields
-				if _, ok := mappedFields[lowered]; !ok {
-					mappedFields[lowered] = field
+				if _, ok := mappedFields.fields[lowered]; !ok && matcher.matching() == MatchDefault {
+					mappedFields.add(lowered, field)
 				}
 			}
 		}
 
 		fields := make([]reflect.StructField, len(keys))
+		parts := make([]complexPart, len(keys))
 		for idx, key := range keys {
-			if field, ok := mappedFields[key]; ok {
+			if override != nil && override.Fields[key] != "" {
+				// Overrides name the field directly, taking precedence over tags.
+				fields[idx], _ = elemTyp.FieldByName(override.Fields[key])
+			} else if field, ok := matcher.lookup(mappedFields, key, elemTyp); ok {
 				fields[idx] = field
-			} else {
-				// Fall back to case-insensitive match
-				fields[idx] = mappedFields[strings.ToLower(key)]
+			} else if field, part := complexField(key, func(name string) (reflect.StructField, bool) {
+				return matcher.lookup(mappedFields, name, elemTyp)
+			}); part != noPart {
+				// Paired columns such as "z_re" and "z_im" fill in one complex field.
+				fields[idx], parts[idx] = field, part
 			}
 		}
 		a.Fields = fields
+
+		generated := generatedSetters(elemTyp, fields)
+		a.Setters = make([]fieldSetter, len(keys))
+		a.Nullable = make([]bool, len(keys))
+		a.Plain = make([]bool, len(keys))
+		for idx, field := range fields {
+			if field.Index == nil {
+				continue
+			}
+			var unit *unitSpec
+			var codec fieldCodec
+			if opts, ok := fieldOpts[field.Name]; ok {
+				unit = newUnitSpec(field, opts)
+				codec.name, codec.decode = reg.codec(opts)
+			}
+			var gen FieldSetter
+			if generated != nil {
+				gen = generated[idx]
+			}
+			a.Setters[idx] = compileSetter(field, parts[idx], unit, codec, gen)
+			a.Plain[idx] = parts[idx] == noPart && unit == nil && codec.decode == nil
+			a.Nullable[idx] = a.Plain[idx] && reflect.PtrTo(field.Type).Implements(scannerType)
+		}
 	}
 
 	return a
 }
 
-// absorb assigns the given values into the given element value.
+// absorb assigns the given values into the given element value. If onError is set, it
+// is consulted when the value of a struct field or map entry cannot be assigned.
 //
 // NOTE: For both efficiency and correctness, the returned value is of type
 // reflect.PointerTo(a.Type) when possible.
-func (a *elementBuilder) absorb(elem reflect.Value, values []interface{}) {
+func (a *elementBuilder) absorb(elem reflect.Value, values []interface{}, cfg *config, onError fieldErrorHandler) {
+	// key is the index of the value being assigned, to report in a ConversionError.
+	key := -1
+	defer a.recoverKey(&key, values)
+	a.fill(elem, values, cfg, onError, &key)
+}
+
+// fill does the work of absorb, setting key to the index of each value as it is
+// assigned. It recovers nothing, so callers must defer recoverKey or an equivalent.
+func (a *elementBuilder) fill(elem reflect.Value, values []interface{}, cfg *config, onError fieldErrorHandler, key *int) {
 	if elem.Kind() == reflect.Ptr && elem.IsZero() {
 		elem.Set(reflect.New(elem.Type().Elem()))
 	}
@@ -90,46 +161,249 @@ func (a *elementBuilder) absorb(elem reflect.Value, values []interface{}) {
 	switch a.Type.Kind() {
 	case reflect.Map:
 		// Use the field names directly to make a map[string]T
-		_assign(elem, reflect.MakeMapWithSize(a.Type, len(values)))
+		if !cfg.partial || reflect.Indirect(elem).IsNil() {
+			_assign(elem, reflect.MakeMapWithSize(a.Type, len(values)), cfg)
+		}
 		elem = reflect.Indirect(elem)
 		// Values are homogeneous, so just reuse one Value
 		mapVal := reflect.Indirect(reflect.New(a.Type.Elem()))
 		for idx, value := range values {
-			key := reflect.ValueOf(a.Keys[idx])
 			val := reflect.ValueOf(value)
 			if val.IsValid() {
-				_assign(mapVal, val)
-				elem.SetMapIndex(key, mapVal)
+				*key = idx
+				if onError == nil {
+					_assign(mapVal, val, cfg)
+				} else if a.guard(onError, idx, value, func() { _assign(mapVal, val, cfg) }) {
+					mapVal.Set(reflect.Zero(mapVal.Type()))
+				}
+				elem.SetMapIndex(reflect.ValueOf(a.Keys[idx]), mapVal)
 			}
 		}
 	case reflect.Struct:
 		// Ensure we are working with struct val when passed *struct
 		elem = reflect.Indirect(elem)
-		for idx, field := range a.Fields {
-			val := reflect.ValueOf(values[idx])
-			if val.IsValid() {
-				f := elem.FieldByIndex(field.Index)
-				_assign(f, val)
+		if len(values) == 1 {
+			// A single value of the element's own type is assigned whole.
+			if val := reflect.Indirect(reflect.ValueOf(values[0])); val.IsValid() && val.Type() == a.Type {
+				elem.Set(val)
+				return
+			}
+		}
+		for idx, set := range a.Setters {
+			if set != nil && (values[idx] != nil || a.Nullable[idx] && !cfg.partial) {
+				*key = idx
+				if onError == nil {
+					set(elem, values[idx], cfg)
+				} else if a.guard(onError, idx, values[idx], func() { set(elem, values[idx], cfg) }) {
+					a.zeroField(elem, idx)
+				}
 			}
 		}
 	default:
 		switch len(values) {
 		case 1:
 			val := reflect.ValueOf(values[0])
+			if !val.IsValid() {
+				// nil leaves the element's zero value in place.
+				return
+			}
 			if t := val.Type(); t == elem.Type() {
 				elem.Set(val)
 			} else if t == reflect.PtrTo(a.Type) {
 				elem.Set(reflect.Indirect(val))
 			}
-			_assign(elem, val)
+			_assign(elem, val, cfg)
 		default:
 			panic("cannot assign multiple columns to element of type " + a.Type.String())
 		}
 	}
 }
 
-func _assign(dst, src reflect.Value) {
+// recoverKey converts a panic raised while assigning the value of the key at *key into
+// a ConversionError. It must be deferred directly, to recover the panic.
+func (a *elementBuilder) recoverKey(key *int, values []interface{}) {
+	if *key < 0 {
+		return
+	}
+	if p := recover(); p != nil {
+		if p == errSkipRow {
+			panic(p)
+		}
+		panic(a.conversionError(p, *key, values[*key]))
+	}
+}
+
+// conversionError returns a ConversionError for a panic raised while assigning value, the
+// value of the key at idx. Panics that are not already ConversionErrors are wrapped, and
+// the key and field are filled in.
+func (a *elementBuilder) conversionError(p interface{}, idx int, value interface{}) *ConversionError {
+	err, ok := p.(*ConversionError)
+	if !ok {
+		err = &ConversionError{Src: reflect.TypeOf(value)}
+		if msg, ok := p.(string); ok {
+			err.Err = errors.New(msg)
+		} else {
+			err.Err = panicError(p)
+		}
+		if a.Type.Kind() == reflect.Map {
+			err.Dst = a.Type.Elem()
+		} else {
+			err.Dst = a.Fields[idx].Type
+		}
+	}
+	if err.Key == "" {
+		err.Key = a.Keys[idx]
+	}
+	if err.Field == "" && a.Type.Kind() == reflect.Struct {
+		err.Field = fieldPath(a.Type, a.Fields[idx].Index)
+	}
+	return err
+}
+
+// structFields returns the fields of t that keys may be mapped to, ordered by depth: its
+// own fields, then the fields promoted from its embedded structs, as Go promotes them.
+// The fields of an embedded struct that has a tag in the chain are not promoted; the
+// embedded struct is mapped as a whole instead, like any other field.
+func structFields(t reflect.Type, tags []string) []reflect.StructField {
+	var fields, tagged []reflect.StructField
+	for _, field := range reflect.VisibleFields(t) {
+		if promotedThrough(field, tagged) {
+			continue
+		}
+		fields = append(fields, field)
+		if _, ok := lookupTag(field, tags); ok && field.Anonymous {
+			tagged = append(tagged, field)
+		}
+	}
+	sort.SliceStable(fields, func(i, j int) bool {
+		return len(fields[i].Index) < len(fields[j].Index)
+	})
+	return fields
+}
+
+// promotedThrough reports whether field is promoted through any of the embedded fields.
+func promotedThrough(field reflect.StructField, embedded []reflect.StructField) bool {
+	for _, e := range embedded {
+		if len(field.Index) > len(e.Index) && reflect.DeepEqual(field.Index[:len(e.Index)], e.Index) {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldByIndex returns the field of v at index, like FieldByIndex, but allocates the nil
+// pointers to embedded structs that it passes through rather than panicking.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					panic("cannot absorb into field of nil pointer to unexported embedded struct " + v.Type().Elem().String())
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// fieldPath returns the name of the field of t at index, qualified by the type's name and
+// the names of any embedded structs it is promoted through.
+func fieldPath(t reflect.Type, index []int) string {
+	path := t.Name()
+	for _, i := range index {
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		field := t.Field(i)
+		if path != "" {
+			path += "."
+		}
+		path += field.Name
+		t = field.Type
+	}
+	return path
+}
+
+// fieldCodec is the Codec selected by a field's tag options, if any.
+type fieldCodec struct {
+	name   string
+	decode Codec
+}
+
+// compileSetter returns a setter for field, which converts values to a complex part, from
+// a declared unit, or through a codec when needed, and tries a generated setter before
+// reflection.
+func compileSetter(field reflect.StructField, part complexPart, unit *unitSpec, codec fieldCodec, generated FieldSetter) fieldSetter {
+	index := field.Index
+	fieldOf := func(elem reflect.Value) reflect.Value {
+		return fieldByIndex(elem, index)
+	}
+	if len(index) == 1 {
+		// Fields that aren't promoted from embedded structs are reached directly.
+		i := index[0]
+		fieldOf = func(elem reflect.Value) reflect.Value {
+			return elem.Field(i)
+		}
+	}
+
+	switch {
+	case part != noPart:
+		return func(elem reflect.Value, value interface{}, cfg *config) {
+			assignComplexPart(fieldOf(elem), reflect.ValueOf(value), part, cfg)
+		}
+	case unit != nil:
+		return func(elem reflect.Value, value interface{}, cfg *config) {
+			assignUnit(fieldOf(elem), reflect.ValueOf(value), unit, cfg)
+		}
+	case codec.decode != nil:
+		return func(elem reflect.Value, value interface{}, cfg *config) {
+			assignCodec(fieldOf(elem), reflect.ValueOf(value), codec.name, codec.decode, cfg)
+		}
+	}
+
+	fieldType := field.Type
+	assign := func(elem reflect.Value, value interface{}, cfg *config) {
+		f, val := fieldOf(elem), reflect.ValueOf(value)
+		if val.Type() == fieldType {
+			// Values of the field's own type need no conversion, whatever the options.
+			f.Set(val)
+			return
+		}
+		_assign(f, val, cfg)
+	}
+	if reflect.PtrTo(fieldType).Implements(scannerType) {
+		// Nil values are scanned too, so that fields such as sql.NullString become invalid.
+		return func(elem reflect.Value, value interface{}, cfg *config) {
+			if value == nil {
+				scan(fieldOf(elem), reflect.Value{})
+			} else {
+				assign(elem, value, cfg)
+			}
+		}
+	}
+	if generated == nil {
+		return assign
+	}
+	return func(elem reflect.Value, value interface{}, cfg *config) {
+		// Generated code assigns values of the field's own type without reflection.
+		if !generated(elem.Addr().Interface(), value) {
+			assign(elem, value, cfg)
+		}
+	}
+}
+
+func _assign(dst, src reflect.Value, cfg *config) {
 	dstType, srcType := dst.Type(), src.Type()
+	if srcType == reflectValueType && dstType != reflectValueType {
+		// Adapters over reflection pass values through without boxing them again.
+		if src = src.Interface().(reflect.Value); src.IsValid() {
+			_assign(dst, src, cfg)
+		}
+		return
+	}
 
 	if dstType == srcType || srcType.AssignableTo(dstType) {
 		// Happy Path
@@ -158,6 +432,83 @@ func _assign(dst, src reflect.Value) {
 		}
 	}
 
-	// Convert without checking convertability; We want panic on failure.
+	if fn := cfg.converter(dstType); fn != nil && !srcType.AssignableTo(dstType) {
+		converted, err := fn(src.Interface())
+		if err != nil {
+			panic(&ConversionError{Src: srcType, Dst: dstType, Err: err})
+		}
+		dst.Set(reflect.ValueOf(converted))
+		return
+	}
+
+	if valuer, ok := valueOf(src).(driver.Valuer); ok {
+		// Values from database drivers are converted through their driver.Value.
+		value, err := valuer.Value()
+		if err != nil {
+			panic(&ConversionError{Src: srcType, Dst: dstType, Err: err})
+		}
+		if value != nil {
+			_assign(dst, reflect.ValueOf(value), cfg)
+		}
+		return
+	}
+	if reflect.PtrTo(dstType).Implements(scannerType) {
+		scan(dst, src)
+		return
+	}
+	if convertBig(dst, src, dstType) || convertIP(dst, src, dstType) || convertText(dst, src, dstType) || convertBool(dst, src, dstType, cfg) || convertLocaleNumber(dst, src, dstType, cfg) || convertBuiltin(dst, src, dstType) || convertNumberText(dst, src, dstType) || convertByteArray(dst, src, dstType) {
+		return
+	}
+	if convertNested(dst, src, dstType, cfg) {
+		return
+	}
+	if cfg.logger != nil {
+		cfg.logger.logConversion(srcType, dstType)
+	}
+	if cfg.checkedNumbers && convertNumber(dst, src, dstType) {
+		return
+	}
+	if !convertible(src, dstType) {
+		panic(&ConversionError{Src: srcType, Dst: dstType})
+	}
 	dst.Set(src.Convert(dstType))
 }
+
+var (
+	reflectValueType = reflect.TypeOf(reflect.Value{})
+	scannerType      = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+)
+
+// scan assigns src to dst, whose address implements sql.Scanner, such as the fields of
+// sql.NullString and sql.NullTime. An invalid src is scanned as nil.
+func scan(dst, src reflect.Value) {
+	if err := dst.Addr().Interface().(sql.Scanner).Scan(valueOf(src)); err != nil {
+		panic(&ConversionError{Src: reflect.TypeOf(valueOf(src)), Dst: dst.Type(), Err: err})
+	}
+}
+
+// valueOf returns the value held by v, or nil if it holds none, such as when v is the
+// target of a nil pointer.
+func valueOf(v reflect.Value) interface{} {
+	if !v.IsValid() || !v.CanInterface() {
+		return nil
+	}
+	return v.Interface()
+}
+
+// convertible reports whether src.Convert(dstType) would succeed. Slices are convertible
+// to arrays, and pointers to arrays, only if they are long enough.
+func convertible(src reflect.Value, dstType reflect.Type) bool {
+	if !src.Type().ConvertibleTo(dstType) {
+		return false
+	}
+	if src.Kind() == reflect.Slice {
+		switch dstType.Kind() {
+		case reflect.Array:
+			return src.Len() >= dstType.Len()
+		case reflect.Ptr:
+			return dstType.Elem().Kind() != reflect.Array || src.Len() >= dstType.Elem().Len()
+		}
+	}
+	return true
+}
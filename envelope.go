@@ -0,0 +1,72 @@
+package absorb
+
+import (
+	"reflect"
+	"time"
+)
+
+// Envelope wraps an absorbed element with metadata about the row it was built from,
+// so consumers can implement tracing and latency measurement without changing their
+// element types.
+//
+// Use Envelope[T] or *Envelope[T] as the element type of any destination; The row's
+// values are absorbed into Value exactly as they would be into a plain T.
+//
+// Example:
+//
+//	ch := make(chan *absorb.Envelope[MyStruct])
+//	go absorb.Absorb(ch, source, absorb.SourceID("orders-db"))
+//	for env := range ch {
+//		log.Printf("row %d from %s took %v", env.Index, env.Source, time.Since(env.Received))
+//	}
+type Envelope[T any] struct {
+	Value T
+	// Index is the zero-based index of the row since the Absorber was opened.
+	Index int
+	// Source identifies the source, as set with the SourceID option.
+	Source string
+	// Received is the time that the row was absorbed.
+	Received time.Time
+	// Present holds the fields of Value assigned from the row, when the TrackPresence
+	// option is set.
+	Present Presence
+}
+
+func (e *Envelope[T]) setEnvelope(index int, source string, received time.Time) {
+	e.Index = index
+	e.Source = source
+	e.Received = received
+}
+
+func (e *Envelope[T]) setPresence(p Presence) {
+	e.Present = p
+}
+
+// enveloper is implemented by pointers to every Envelope type.
+type enveloper interface {
+	setEnvelope(index int, source string, received time.Time)
+	setPresence(p Presence)
+}
+
+var enveloperType = reflect.TypeOf((*enveloper)(nil)).Elem()
+
+// SourceID sets the Source of every Envelope built by the Absorber.
+func SourceID(id string) Option {
+	return func(c *config) {
+		c.sourceID = id
+	}
+}
+
+// openEnvelope fills in the metadata of the Envelope at elem, allocating it if needed,
+// and returns its Value field for absorbing the row into, with a pointer to the Envelope.
+func (a *absorberImpl) openEnvelope(elem reflect.Value, idx int) (value, env reflect.Value) {
+	if elem.Kind() == reflect.Ptr && elem.IsZero() {
+		elem.Set(reflect.New(elem.Type().Elem()))
+	}
+	env = elem
+	if env.Kind() != reflect.Ptr {
+		env = env.Addr()
+	}
+	env.Interface().(enveloper).setEnvelope(idx, a.cfg.sourceID, time.Now())
+	return env.Elem().Field(0), env
+}
@@ -0,0 +1,67 @@
+package absorb_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jyopp/absorb"
+)
+
+func TestEnvelopeChannel(t *testing.T) {
+	start := time.Now()
+	ch := make(chan *absorb.Envelope[TestDst], 3)
+	if err := absorb.Absorb(ch, testSource{i: 3}, absorb.SourceID("test-source")); err != nil {
+		t.Fatal(err)
+	}
+	close(ch)
+
+	idx := 0
+	for env := range ch {
+		expect := TestDst{Name: "test", Actual: idx + 1}
+		if env.Value != expect || env.Index != idx || env.Source != "test-source" {
+			t.Fatalf("Unexpected envelope %+v at %d", env, idx)
+		}
+		if env.Received.Before(start) {
+			t.Fatal("Envelope received time is before absorption began")
+		}
+		idx++
+	}
+}
+
+func TestEnvelopeSlice(t *testing.T) {
+	var dst []absorb.Envelope[*map[string]interface{}]
+	if err := absorb.Absorb(&dst, testSource{i: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if len(dst) != 2 || dst[1].Index != 1 || (*dst[1].Value)["Aliased"] != 2 {
+		t.Fatalf("Unexpected envelopes %+v", dst)
+	}
+}
+
+func TestCallback(t *testing.T) {
+	var received []TestDst
+	err := absorb.Absorb(func(row *TestDst) {
+		received = append(received, *row)
+	}, testSource{i: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(received) != 3 || received[2].Actual != 3 {
+		t.Fatalf("Unexpected callback rows %+v", received)
+	}
+
+	var envelopes []int
+	err = absorb.Absorb(func(env absorb.Envelope[TestDst]) {
+		envelopes = append(envelopes, env.Index)
+	}, testSource{i: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(envelopes) != 2 || envelopes[1] != 1 {
+		t.Fatalf("Unexpected envelope indexes %v", envelopes)
+	}
+
+	subpanic(t, "Callback Signature", func() {
+		absorb.New(func(a, b TestDst) {})
+	})
+}
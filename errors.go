@@ -0,0 +1,82 @@
+package absorb
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+)
+
+// Lifecycle errors are the panic values reported when an Absorber's methods are
+// called out of order. Each Open must be paired with exactly one Close, and Absorb
+// may only be called between them.
+var (
+	// ErrNotOpen reports a call to Absorb or Close before Open.
+	ErrNotOpen = errors.New("absorb: absorber is not open")
+	// ErrAlreadyOpen reports a call to Open before the previous Open was closed.
+	ErrAlreadyOpen = errors.New("absorb: absorber is already open")
+	// ErrClosed reports a call to Absorb or Close after Close.
+	ErrClosed = errors.New("absorb: absorber is closed")
+)
+
+// ErrMissingKey is wrapped by the panic value reported when Open is called without a
+// key that an Override marks as required.
+var ErrMissingKey = errors.New("absorb: missing required key")
+
+// ErrNumericRange is wrapped by the panic value reported when CheckedNumbers is set, and
+// a value does not fit in its destination's numeric type.
+var ErrNumericRange = errors.New("absorb: numeric value out of range")
+
+// ErrArity is wrapped by the panic value reported when a row has more or fewer values than
+// the keys passed to Open, unless the Arity option allows it.
+var ErrArity = errors.New("absorb: wrong number of values")
+
+// ErrUnknownKey is wrapped by the panic value reported when the Strict option is set, and
+// a key does not map to any field.
+var ErrUnknownKey = errors.New("absorb: key does not match any field")
+
+// ErrDuplicateKey is wrapped by the panic value reported when Open is called with a key
+// more than once, and the DuplicateKeys option is set to DuplicatesError.
+var ErrDuplicateKey = errors.New("absorb: duplicate key")
+
+// ConversionError describes a value that cannot be converted to its destination's type.
+// An Absorber panics with it, as it always has for impossible conversions, while an
+// AbsorberV2 returns it as an error.
+type ConversionError struct {
+	// Row is the number of the row in the source, counting from 1, or 0 if it is unknown.
+	Row int
+	// Key is the key of the value's column, or empty if the value was not keyed.
+	Key string
+	// Field is the path of the destination struct field, such as "User.Address.City", or
+	// empty if the destination is not a struct field.
+	Field string
+	// Src and Dst are the types of the value and its destination.
+	Src, Dst reflect.Type
+	// Err is the underlying failure, such as an error returned by a converter, if any.
+	Err error
+}
+
+func (e *ConversionError) Error() string {
+	msg := "absorb: "
+	if e.Row > 0 {
+		msg += "row " + strconv.Itoa(e.Row) + ": "
+	}
+	src := "nil"
+	if e.Src != nil {
+		src = e.Src.String()
+	}
+	msg += "cannot convert " + src + " to " + e.Dst.String()
+	if e.Key != "" {
+		msg += " for key " + strconv.Quote(e.Key)
+	}
+	if e.Field != "" {
+		msg += " (field " + e.Field + ")"
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *ConversionError) Unwrap() error {
+	return e.Err
+}
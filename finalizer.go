@@ -0,0 +1,33 @@
+package absorb
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// FinalizerFunc post-processes an element after the row's values have been assigned to it.
+// Elem is a pointer to the element, or the element itself for maps. Keys and values are
+// the row that built it, after any defaults are applied.
+type FinalizerFunc func(elem interface{}, keys []string, values []interface{}) error
+
+// WithFinalizer calls fn for each element after assignment, but before it is stored, sent,
+// or passed to a callback. Use it to compute derived fields, such as splitting a FullName
+// column into First and Last fields, or to normalize values using both the raw row and the
+// built element. A returned error causes a panic, like an impossible conversion.
+//
+// With the Parallel option, fn may be called concurrently from several goroutines.
+func WithFinalizer(fn FinalizerFunc) Option {
+	return func(c *config) {
+		c.finalizer = fn
+	}
+}
+
+// finalize calls the finalizer for the element built in target.
+func (a *absorberImpl) finalize(target reflect.Value, values []interface{}) {
+	if target.Kind() != reflect.Ptr && target.Kind() != reflect.Map && target.CanAddr() {
+		target = target.Addr()
+	}
+	if err := a.cfg.finalizer(target.Interface(), a.builder.Keys, values); err != nil {
+		panic(fmt.Errorf("absorb: finalizer failed for %s: %w", a.elemType, err))
+	}
+}
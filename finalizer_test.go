@@ -0,0 +1,62 @@
+package absorb_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jyopp/absorb"
+)
+
+type fullName struct {
+	Name        string
+	First, Last string
+	Seen        int `test:"Aliased"`
+}
+
+func splitName(elem interface{}, keys []string, values []interface{}) error {
+	d := elem.(*fullName)
+	d.First, d.Last, _ = strings.Cut(d.Name, " ")
+	if keys[1] != "Aliased" || values[1] != d.Seen {
+		return errors.New("unexpected row")
+	}
+	return nil
+}
+
+// nameSource emits the given names, each with its index as Aliased.
+type nameSource []string
+
+func (ns nameSource) Emit(into absorb.Absorber) error {
+	into.Open("test", len(ns), "Name", "Aliased")
+	defer into.Close()
+	for idx, name := range ns {
+		into.Absorb(name, idx)
+	}
+	return nil
+}
+
+func TestFinalizer(t *testing.T) {
+	src := nameSource{"Jean-Luc Picard", "Data"}
+	var dst []fullName
+	if err := absorb.Absorb(&dst, src, absorb.WithFinalizer(splitName)); err != nil {
+		t.Fatal(err)
+	}
+	if dst[0].First != "Jean-Luc" || dst[0].Last != "Picard" || dst[1].First != "Data" {
+		t.Fatalf("Unexpected elements %+v", dst)
+	}
+
+	// Elements are finalized before they are sent.
+	ch := make(chan *fullName, 2)
+	if err := absorb.Absorb(ch, src, absorb.WithFinalizer(splitName)); err != nil {
+		t.Fatal(err)
+	}
+	if d := <-ch; d.Last != "Picard" {
+		t.Fatalf("Unexpected element %+v", d)
+	}
+
+	subpanic(t, "Error", func() {
+		absorb.Absorb(&dst, src, absorb.WithFinalizer(func(interface{}, []string, []interface{}) error {
+			return errors.New("rejected")
+		}))
+	})
+}
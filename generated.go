@@ -0,0 +1,35 @@
+package absorb
+
+import "reflect"
+
+// FieldSetter assigns value to one field of the struct that elem points to, without
+// reflection. It returns false if it does not handle the value's type, in which case the
+// value is assigned with reflection as usual.
+type FieldSetter func(elem interface{}, value interface{}) bool
+
+// GeneratedSetters is implemented by pointers to struct types with code generated by
+// absorbgen (see cmd/absorbgen). Keys are still mapped to fields as usual, but values of
+// each field's exact type are assigned by the generated setters.
+type GeneratedSetters interface {
+	// AbsorbFieldSetters returns a setter for each field, keyed by the field's name.
+	AbsorbFieldSetters() map[string]FieldSetter
+}
+
+var generatedSettersType = reflect.TypeOf((*GeneratedSetters)(nil)).Elem()
+
+// generatedSetters returns the generated setter for each of fields, or nil if typ has
+// no generated code.
+func generatedSetters(typ reflect.Type, fields []reflect.StructField) []FieldSetter {
+	ptrType := reflect.PtrTo(typ)
+	if !ptrType.Implements(generatedSettersType) {
+		return nil
+	}
+	byName := reflect.Zero(ptrType).Interface().(GeneratedSetters).AbsorbFieldSetters()
+	setters := make([]FieldSetter, len(fields))
+	for idx, field := range fields {
+		if len(field.Index) == 1 {
+			setters[idx] = byName[field.Name]
+		}
+	}
+	return setters
+}
@@ -0,0 +1,51 @@
+package absorb_test
+
+//go:generate go run ./cmd/absorbgen -output generated_absorb_test.go generated_test.go
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jyopp/absorb"
+)
+
+//absorb:generate
+type genDst struct {
+	Name   string
+	Actual int64 `test:"Aliased"`
+	Seen   *time.Time
+	hidden int
+}
+
+func TestGeneratedSetters(t *testing.T) {
+	if _, ok := interface{}(&genDst{}).(absorb.GeneratedSetters); !ok {
+		t.Fatal("genDst has no generated setters; run go generate")
+	}
+
+	when := time.Now()
+	var dst []genDst
+	abs := absorb.New(&dst)
+	abs.Open("test", 2, "Name", "Aliased", "Seen")
+	abs.Absorb("fast", int64(1), when)
+	// Values of other types fall back to reflection.
+	abs.Absorb([]byte("slow"), int32(2), &when)
+	abs.Close()
+
+	for idx, name := range []string{"fast", "slow"} {
+		if d := dst[idx]; d.Name != name || d.Actual != int64(idx+1) || d.Seen == nil || !d.Seen.Equal(when) {
+			t.Fatalf("Unexpected element %+v", d)
+		}
+	}
+}
+
+func BenchmarkGeneratedSetters(b *testing.B) {
+	var dst []genDst
+	for i := 0; i < b.N; i++ {
+		abs := absorb.New(&dst)
+		abs.Open("test", 100, "Name", "Aliased")
+		for j := 0; j < 100; j++ {
+			abs.Absorb("name", int64(j))
+		}
+		abs.Close()
+	}
+}
@@ -0,0 +1,66 @@
+// Package geoconv provides absorb converters for geospatial columns, decoding points from
+// WKB or WKT values, or from paired latitude and longitude columns.
+//
+// Points are decoded into the Point type, which shares its layout with the point types of
+// common geometry libraries, such as orb.Point. Use Converter to produce any other type.
+package geoconv
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/jyopp/absorb"
+)
+
+// Point is a position in (X, Y) order, which is (longitude, latitude) for geographic
+// coordinates. Values of type Point convert directly to other [2]float64 types.
+type Point [2]float64
+
+// Lon returns the point's longitude, or X coordinate.
+func (p Point) Lon() float64 { return p[0] }
+
+// Lat returns the point's latitude, or Y coordinate.
+func (p Point) Lat() float64 { return p[1] }
+
+var pointType = reflect.TypeOf(Point{})
+
+// Options returns a converter that decodes Point destinations from WKB values ([]byte, or
+// a hex-encoded string, as returned by PostGIS), or from WKT strings.
+func Options() []absorb.Option {
+	return []absorb.Option{absorb.Converter(pointType, DecodePoint)}
+}
+
+// Converter returns an option that decodes points into destinations of type T, using fn
+// to create each value. This adapts geoconv to any geometry library.
+//
+// Example:
+//
+//	opt := geoconv.Converter(func(p geoconv.Point) geom.Coord {
+//		return geom.Coord{p.Lon(), p.Lat()}
+//	})
+func Converter[T any](fn func(Point) T) absorb.Option {
+	return absorb.Converter(reflect.TypeOf((*T)(nil)).Elem(), func(value interface{}) (interface{}, error) {
+		p, err := DecodePoint(value)
+		if err != nil {
+			return nil, err
+		}
+		return fn(p.(Point)), nil
+	})
+}
+
+// DecodePoint is an absorb.ConverterFunc that decodes a Point from WKB or WKT.
+// Point values, such as those produced by LatLon, are returned unmodified.
+func DecodePoint(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case Point:
+		return v, nil
+	case []byte:
+		return parseWKB(v)
+	case string:
+		if wkb, ok := decodeHex(v); ok {
+			return parseWKB(wkb)
+		}
+		return parseWKT(v)
+	}
+	return nil, fmt.Errorf("cannot decode point from %T", value)
+}
@@ -0,0 +1,93 @@
+package geoconv_test
+
+import (
+	"testing"
+
+	"github.com/jyopp/absorb"
+	"github.com/jyopp/absorb/geoconv"
+)
+
+// rowSource emits a single row.
+type rowSource struct {
+	keys   []string
+	values []interface{}
+}
+
+func (rs rowSource) Emit(into absorb.Absorber) error {
+	into.Open("test", 1, rs.keys...)
+	defer into.Close()
+	into.Absorb(rs.values...)
+	return nil
+}
+
+// libPoint stands in for a geometry library's point type.
+type libPoint [2]float64
+
+type coord struct{ X, Y float64 }
+
+func TestDecodePoint(t *testing.T) {
+	for _, value := range []interface{}{
+		"POINT (30 10)",
+		"SRID=4326;point z(30 10 5)",
+		// Little-endian WKB, as bytes and as hex.
+		[]byte{1, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x3e, 0x40, 0, 0, 0, 0, 0, 0, 0x24, 0x40},
+		"01010000000000000000003e400000000000002440",
+		// Big-endian EWKB with an SRID.
+		"0020000001000010e6403e0000000000004024000000000000",
+	} {
+		p, err := geoconv.DecodePoint(value)
+		if err != nil {
+			t.Fatalf("Decoding %v: %v", value, err)
+		}
+		if p != (geoconv.Point{30, 10}) {
+			t.Fatalf("Decoding %v: unexpected point %v", value, p)
+		}
+	}
+	for _, value := range []interface{}{"LINESTRING (30 10, 10 30)", "POINT (30)", []byte{1, 1, 0}, 3.0} {
+		if _, err := geoconv.DecodePoint(value); err == nil {
+			t.Fatalf("Expected error decoding %v", value)
+		}
+	}
+}
+
+func TestConverter(t *testing.T) {
+	var dst struct {
+		Point geoconv.Point
+		Lib   *libPoint
+		Coord coord
+	}
+	opts := append(geoconv.Options(), geoconv.Converter(func(p geoconv.Point) coord {
+		return coord{p.Lon(), p.Lat()}
+	}))
+	src := rowSource{
+		keys:   []string{"Point", "Lib", "Coord"},
+		values: []interface{}{"POINT (1 2)", geoconv.Point{3, 4}, "POINT (5 6)"},
+	}
+	if err := absorb.Absorb(&dst, src, opts...); err != nil {
+		t.Fatal(err)
+	}
+	if dst.Point != (geoconv.Point{1, 2}) || *dst.Lib != (libPoint{3, 4}) || dst.Coord != (coord{5, 6}) {
+		t.Fatalf("Unexpected points %+v", dst)
+	}
+}
+
+func TestLatLon(t *testing.T) {
+	type Place struct {
+		Name     string
+		Location geoconv.Point
+	}
+	var dst []Place
+	src := rowSource{
+		keys:   []string{"lon", "Name", "lat"},
+		values: []interface{}{-122.5, "Golden Gate", "37.8"},
+	}
+	err := absorb.Pipe(src).Use(func(next absorb.Absorber) absorb.Absorber {
+		return geoconv.LatLon(next, "lat", "lon", "Location")
+	}).Into(&dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := (Place{"Golden Gate", geoconv.Point{-122.5, 37.8}}); len(dst) != 1 || dst[0] != expect {
+		t.Fatalf("Expected %+v, got %+v", expect, dst)
+	}
+}
@@ -0,0 +1,89 @@
+package geoconv
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/jyopp/absorb"
+)
+
+// LatLon returns an Absorber that combines the values of the lat and lon columns into a
+// single Point, passed to next under the given key in place of the lat column.
+// The lon column is removed. Panics on Open if either column is missing.
+//
+// Use LatLon as a pipeline stage:
+//
+//	err := absorb.Pipe(src).Use(func(next absorb.Absorber) absorb.Absorber {
+//		return geoconv.LatLon(next, "latitude", "longitude", "Location")
+//	}).Into(&places)
+func LatLon(next absorb.Absorber, lat, lon, key string) absorb.Absorber {
+	return &latLonAbsorber{next: next, lat: lat, lon: lon, key: key}
+}
+
+type latLonAbsorber struct {
+	next           absorb.Absorber
+	lat, lon, key  string
+	latIdx, lonIdx int
+	values         []interface{}
+}
+
+func (a *latLonAbsorber) Open(tag string, count int, keys ...string) {
+	a.latIdx, a.lonIdx = -1, -1
+	for idx, k := range keys {
+		switch k {
+		case a.lat:
+			a.latIdx = idx
+		case a.lon:
+			a.lonIdx = idx
+		}
+	}
+	if a.latIdx < 0 || a.lonIdx < 0 {
+		panic(fmt.Errorf("%w: cannot pair %q and %q into a point", absorb.ErrMissingKey, a.lat, a.lon))
+	}
+
+	paired := make([]string, 0, len(keys)-1)
+	for idx, k := range keys {
+		if idx == a.latIdx {
+			k = a.key
+		}
+		if idx != a.lonIdx {
+			paired = append(paired, k)
+		}
+	}
+	a.values = make([]interface{}, len(paired))
+	a.next.Open(tag, count, paired...)
+}
+
+func (a *latLonAbsorber) Absorb(values ...interface{}) {
+	out := a.values[:0]
+	for idx, v := range values {
+		if idx == a.latIdx {
+			v = Point{coordinate(values[a.lonIdx]), coordinate(v)}
+		}
+		if idx != a.lonIdx {
+			out = append(out, v)
+		}
+	}
+	a.next.Absorb(out...)
+}
+
+func (a *latLonAbsorber) Close() {
+	a.next.Close()
+}
+
+var float64Type = reflect.TypeOf(float64(0))
+
+// coordinate converts a numeric column value to float64, panicking like absorb does for
+// impossible conversions.
+func coordinate(value interface{}) float64 {
+	if s, ok := value.(string); ok {
+		f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil {
+			panic("cannot convert coordinate: " + err.Error())
+		}
+		return f
+	}
+	return reflect.Indirect(reflect.ValueOf(value)).Convert(float64Type).Float()
+}
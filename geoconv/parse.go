@@ -0,0 +1,100 @@
+package geoconv
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// wkbPoint is the geometry type of a point, in the low digits of a WKB type code.
+const wkbPoint = 1
+
+// EWKB, as written by PostGIS, sets flags in the high bits of the type code.
+const (
+	ewkbZ    = 0x80000000
+	ewkbM    = 0x40000000
+	ewkbSRID = 0x20000000
+)
+
+var errShortWKB = errors.New("truncated WKB point")
+
+// parseWKB decodes a point from ISO WKB or PostGIS EWKB, ignoring Z and M coordinates.
+func parseWKB(data []byte) (Point, error) {
+	if len(data) < 5 {
+		return Point{}, errShortWKB
+	}
+	var order binary.ByteOrder
+	switch data[0] {
+	case 0:
+		order = binary.BigEndian
+	case 1:
+		order = binary.LittleEndian
+	default:
+		return Point{}, fmt.Errorf("invalid WKB byte order %d", data[0])
+	}
+	code := order.Uint32(data[1:])
+	data = data[5:]
+	if code&ewkbSRID != 0 {
+		if len(data) < 4 {
+			return Point{}, errShortWKB
+		}
+		data = data[4:]
+	}
+	// ISO WKB adds 1000, 2000, or 3000 to the type for Z, M, and ZM coordinates.
+	if geomType := (code &^ (ewkbZ | ewkbM | ewkbSRID)) % 1000; geomType != wkbPoint {
+		return Point{}, fmt.Errorf("cannot decode WKB geometry type %d as a point", geomType)
+	}
+	if len(data) < 16 {
+		return Point{}, errShortWKB
+	}
+	return Point{
+		math.Float64frombits(order.Uint64(data)),
+		math.Float64frombits(order.Uint64(data[8:])),
+	}, nil
+}
+
+// parseWKT decodes a point from WKT or PostGIS EWKT, such as "POINT (30 10)" or
+// "SRID=4326;POINT Z (30 10 5)", ignoring Z and M coordinates.
+func parseWKT(text string) (Point, error) {
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(strings.ToUpper(text), "SRID=") {
+		if semi := strings.IndexByte(text, ';'); semi >= 0 {
+			text = text[semi+1:]
+		}
+	}
+	open, end := strings.IndexByte(text, '('), len(text)-1
+	if open < 0 || end <= open || text[end] != ')' {
+		return Point{}, fmt.Errorf("invalid WKT %q", text)
+	}
+	switch tag := strings.ToUpper(strings.TrimSpace(text[:open])); tag {
+	case "POINT", "POINT Z", "POINT M", "POINT ZM":
+	default:
+		return Point{}, fmt.Errorf("cannot decode WKT %q as a point", tag)
+	}
+	coords := strings.Fields(text[open+1 : end])
+	if len(coords) < 2 {
+		return Point{}, fmt.Errorf("invalid WKT point %q", text)
+	}
+	var p Point
+	for idx := range p {
+		f, err := strconv.ParseFloat(coords[idx], 64)
+		if err != nil {
+			return Point{}, err
+		}
+		p[idx] = f
+	}
+	return p, nil
+}
+
+// decodeHex decodes text as hex-encoded WKB, returning false if it isn't.
+func decodeHex(text string) ([]byte, bool) {
+	if len(text) < 10 || !(strings.HasPrefix(text, "00") || strings.HasPrefix(text, "01")) {
+		return nil, false
+	}
+	data, err := hex.DecodeString(text)
+	return data, err == nil
+}
@@ -0,0 +1,63 @@
+package absorb
+
+// OnOpen calls fn with the keys passed to Open, once the Absorber is ready to absorb rows.
+func OnOpen(fn func(keys []string)) Option {
+	return func(c *config) {
+		c.onOpen = fn
+	}
+}
+
+// OnRow calls fn with each row passed to Absorb, before it is converted, along with its
+// index among the rows absorbed since Open, counting from 0. Rows discarded before a
+// Resume point are not included. Values must not be modified or retained.
+func OnRow(fn func(idx int, values []interface{})) Option {
+	return func(c *config) {
+		c.onRow = fn
+	}
+}
+
+// OnClose calls fn when the Absorber is closed, with the number of rows absorbed and the
+// error that ended the run, if any. Err is the panic that aborted a call to Absorb, such
+// as an impossible conversion, or else the errors collected by CollectErrors.
+//
+// Together with OnOpen and OnRow, it lets cross-cutting concerns such as auditing,
+// logging, and metrics observe a run without wrapping each source.
+//
+// Example:
+//
+//	opt := absorb.OnClose(func(count int, err error) {
+//		rowsAbsorbed.Add(float64(count))
+//		if err != nil {
+//			log.Printf("absorb failed after %d rows: %v", count, err)
+//		}
+//	})
+func OnClose(fn func(count int, err error)) Option {
+	return func(c *config) {
+		c.onClose = fn
+	}
+}
+
+// notePanic records the panic that aborts a call to Absorb, for OnClose.
+// It must be deferred directly, to recover the panic.
+func (a *absorberImpl) notePanic() {
+	if p := recover(); p != nil {
+		a.failure = panicError(p)
+		panic(p)
+	}
+}
+
+// closed calls the OnClose hook, reporting p, a panic raised while closing, if there is
+// no earlier failure. It must be deferred directly, to recover the panic.
+func (a *absorberImpl) closed() {
+	p := recover()
+	err := a.failure
+	if err == nil && p != nil {
+		err = panicError(p)
+	} else if err == nil {
+		err = a.Errors()
+	}
+	a.cfg.onClose(a.idx, err)
+	if p != nil {
+		panic(p)
+	}
+}
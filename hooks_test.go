@@ -0,0 +1,57 @@
+package absorb_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jyopp/absorb"
+)
+
+func TestLifecycleHooks(t *testing.T) {
+	var events []string
+	var count int
+	var closeErr error
+	opts := []absorb.Option{
+		absorb.OnOpen(func(keys []string) {
+			events = append(events, "open "+strings.Join(keys, ","))
+		}),
+		absorb.OnRow(func(idx int, values []interface{}) {
+			events = append(events, "row "+string(rune('0'+idx)))
+		}),
+		absorb.OnClose(func(n int, err error) {
+			events = append(events, "close")
+			count, closeErr = n, err
+		}),
+	}
+
+	var dst []TestDst
+	src := messySource{{"a", 1}, {"b", 2}}
+	if err := absorb.Absorb(&dst, src, opts...); err != nil {
+		t.Fatal(err)
+	}
+	expect := "open Name,Aliased|row 0|row 1|close"
+	if got := strings.Join(events, "|"); got != expect || count != 2 || closeErr != nil {
+		t.Fatalf("Expected %q with 2 rows, got %q with %d rows, err %v", expect, got, count, closeErr)
+	}
+
+	// A failed conversion is reported to OnClose, and still aborts the run.
+	events = nil
+	subpanic(t, "failed conversion", func() {
+		absorb.Absorb(&dst, messySource{{"a", 1}, {"b", "two"}, {"c", 3}}, opts...)
+	})
+	var convErr *absorb.ConversionError
+	if count != 2 || !errors.As(closeErr, &convErr) || convErr.Row != 2 {
+		t.Fatalf("Expected a conversion error after 2 rows, got %d rows, err %v", count, closeErr)
+	}
+
+	// Collected errors are reported as RowErrors.
+	opts = append(opts, absorb.CollectErrors())
+	if err := absorb.Absorb(&dst, messySource{{"a", 1}, {"b", "two"}, {"c", 3}}, opts...); err == nil {
+		t.Fatal("Expected collected errors")
+	}
+	var rowErrs absorb.RowErrors
+	if count != 3 || !errors.As(closeErr, &rowErrs) || len(rowErrs) != 1 {
+		t.Fatalf("Expected 1 collected error after 3 rows, got %d rows, err %v", count, closeErr)
+	}
+}
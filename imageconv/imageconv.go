@@ -0,0 +1,103 @@
+// Package imageconv provides absorb converters that decode image blobs, such as PNG,
+// JPEG, and GIF columns of an asset table.
+//
+// Its converters are kept out of the absorb package, so that programs which do not
+// absorb images do not link the image decoders.
+package imageconv
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	// Register the decoders used by image.Decode and image.DecodeConfig.
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"reflect"
+
+	"github.com/jyopp/absorb"
+)
+
+// Info describes an encoded image without decoding its pixels.
+type Info struct {
+	// Format is the name of the image's format, such as "png" or "jpeg".
+	Format string
+	Width  int
+	Height int
+	// Size is the length of the encoded image, in bytes.
+	Size int
+}
+
+var (
+	imageType  = reflect.TypeOf((*image.Image)(nil)).Elem()
+	configType = reflect.TypeOf(image.Config{})
+	infoType   = reflect.TypeOf(Info{})
+)
+
+// Options returns converters that decode []byte or string values into image.Image,
+// image.Config, and Info destinations.
+//
+// Example:
+//
+//	type Asset struct {
+//		Name      string
+//		Thumbnail image.Image `db:"data"`
+//	}
+//	err := absorb.Absorb(&assets, src, imageconv.Options()...)
+func Options() []absorb.Option {
+	return []absorb.Option{
+		absorb.Converter(imageType, DecodeImage),
+		absorb.Converter(configType, DecodeConfig),
+		absorb.Converter(infoType, DecodeInfo),
+	}
+}
+
+// Register installs the converters from Options in absorb's global registry.
+func Register() {
+	absorb.RegisterConverter(imageType, DecodeImage)
+	absorb.RegisterConverter(configType, DecodeConfig)
+	absorb.RegisterConverter(infoType, DecodeInfo)
+}
+
+// DecodeImage is an absorb.ConverterFunc that decodes an image.Image.
+func DecodeImage(value interface{}) (interface{}, error) {
+	data, err := blob(value)
+	if err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	return img, err
+}
+
+// DecodeConfig is an absorb.ConverterFunc that decodes an image.Config from an image's header.
+func DecodeConfig(value interface{}) (interface{}, error) {
+	data, err := blob(value)
+	if err != nil {
+		return nil, err
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	return config, err
+}
+
+// DecodeInfo is an absorb.ConverterFunc that decodes an Info from an image's header.
+func DecodeInfo(value interface{}) (interface{}, error) {
+	data, err := blob(value)
+	if err != nil {
+		return nil, err
+	}
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return Info{Format: format, Width: config.Width, Height: config.Height, Size: len(data)}, nil
+}
+
+func blob(value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	}
+	return nil, fmt.Errorf("cannot decode image from %T", value)
+}
@@ -0,0 +1,64 @@
+package imageconv_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/jyopp/absorb"
+	"github.com/jyopp/absorb/imageconv"
+)
+
+func testPNG(t *testing.T, w, h int) []byte {
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	img.Set(1, 1, color.White)
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestImage(t *testing.T) {
+	type Asset struct {
+		Name   string
+		Image  image.Image `test:"data"`
+		Config *image.Config
+		Info   imageconv.Info
+	}
+
+	data := testPNG(t, 3, 2)
+	var dst []Asset
+	abs := absorb.New(&dst, imageconv.Options()...)
+	abs.Open("test", 1, "Name", "data", "Config", "Info")
+	abs.Absorb("dot", data, data, data)
+	abs.Close()
+
+	asset := dst[0]
+	if asset.Image == nil || asset.Image.Bounds().Dx() != 3 {
+		t.Fatalf("Image was not decoded: %+v", asset.Image)
+	}
+	if r, _, _, _ := asset.Image.At(1, 1).RGBA(); r != 0xffff {
+		t.Fatal("Decoded image has the wrong pixels")
+	}
+	if asset.Config.Height != 2 {
+		t.Fatalf("Unexpected config %+v", asset.Config)
+	}
+	if expect := (imageconv.Info{Format: "png", Width: 3, Height: 2, Size: len(data)}); asset.Info != expect {
+		t.Fatalf("Expected %+v, got %+v", expect, asset.Info)
+	}
+}
+
+func TestInvalidImage(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Expected invalid image data to panic")
+		}
+	}()
+	var dst image.Image
+	abs := absorb.New(&dst, imageconv.Options()...)
+	abs.Open("", 1)
+	abs.Absorb([]byte("not an image"))
+}
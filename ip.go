@@ -0,0 +1,99 @@
+package absorb
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"reflect"
+	"strings"
+)
+
+var (
+	ipType          = reflect.TypeOf(net.IP{})
+	ipNetType       = reflect.TypeOf(net.IPNet{})
+	netipAddrType   = reflect.TypeOf(netip.Addr{})
+	netipPrefixType = reflect.TypeOf(netip.Prefix{})
+)
+
+// convertIP sets a net.IP, net.IPNet, netip.Addr, or netip.Prefix dst from text such as
+// "192.0.2.1" or "2001:db8::/32", from 4 or 16 raw address bytes, or from another of
+// these types. Returns false if it does not apply.
+func convertIP(dst, src reflect.Value, dstType reflect.Type) bool {
+	if dstType != ipType && dstType != ipNetType && dstType != netipAddrType && dstType != netipPrefixType {
+		return false
+	}
+	prefix, err := ipPrefix(valueOf(src))
+	if errors.Is(err, errNotAddress) {
+		return false
+	} else if err == nil && dstType != netipPrefixType && dstType != ipNetType && prefix.Bits() != prefix.Addr().BitLen() {
+		err = fmt.Errorf("%s is a network, not an address", prefix)
+	}
+	if err != nil {
+		panic(&ConversionError{Src: src.Type(), Dst: dstType, Err: err})
+	}
+
+	var value interface{}
+	switch addr := prefix.Addr(); dstType {
+	case ipType:
+		value = net.IP(addr.AsSlice())
+	case netipAddrType:
+		value = addr
+	case ipNetType:
+		ones := prefix.Bits()
+		value = net.IPNet{
+			IP:   net.IP(prefix.Masked().Addr().AsSlice()),
+			Mask: net.CIDRMask(ones, addr.BitLen()),
+		}
+	case netipPrefixType:
+		value = prefix
+	}
+	dst.Set(reflect.ValueOf(value).Convert(dstType))
+	return true
+}
+
+var errNotAddress = errors.New("not an IP address")
+
+// ipPrefix returns the address or network held by v. An address is returned as a prefix
+// of its full length, and an IPv4 address in IPv6 form is unmapped. Byte slices of 4 or
+// 16 bytes are raw addresses, and other byte slices are text.
+func ipPrefix(v interface{}) (netip.Prefix, error) {
+	var addr netip.Addr
+	switch v := v.(type) {
+	case netip.Prefix:
+		return v, nil
+	case netip.Addr:
+		addr = v
+	case net.IPNet:
+		ones, _ := v.Mask.Size()
+		a, ok := netip.AddrFromSlice(v.IP)
+		if !ok {
+			return netip.Prefix{}, fmt.Errorf("invalid network %v", v)
+		}
+		if len(v.Mask) == net.IPv4len {
+			a = a.Unmap()
+		}
+		return netip.PrefixFrom(a, ones), nil
+	case net.IP:
+		return ipPrefix([]byte(v))
+	case []byte:
+		if len(v) == net.IPv4len || len(v) == net.IPv6len {
+			addr, _ = netip.AddrFromSlice(v)
+		} else {
+			return ipPrefix(string(v))
+		}
+	case string:
+		text := strings.TrimSpace(v)
+		if strings.Contains(text, "/") {
+			return netip.ParsePrefix(text)
+		}
+		var err error
+		if addr, err = netip.ParseAddr(text); err != nil {
+			return netip.Prefix{}, err
+		}
+	default:
+		return netip.Prefix{}, errNotAddress
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
@@ -0,0 +1,48 @@
+package absorb_test
+
+import (
+	"net"
+	"net/netip"
+	"reflect"
+	"testing"
+
+	"github.com/jyopp/absorb"
+)
+
+func TestIPAddresses(t *testing.T) {
+	type Rule struct {
+		Source  net.IP
+		Target  netip.Addr
+		Network *net.IPNet
+		Prefix  netip.Prefix
+	}
+	var dst []Rule
+	abs := absorb.New(&dst)
+	abs.Open("", 2, "Source", "Target", "Network", "Prefix")
+	abs.Absorb("192.0.2.1", []byte("2001:db8::1"), "10.1.2.3/8", "2001:db8::/32")
+	abs.Absorb([]byte{192, 0, 2, 2}, net.ParseIP("192.0.2.3"), netip.MustParsePrefix("192.168.0.0/16"), "::ffff:10.0.0.1")
+	abs.Close()
+
+	_, network, _ := net.ParseCIDR("10.0.0.0/8")
+	_, private, _ := net.ParseCIDR("192.168.0.0/16")
+	expect := []Rule{
+		{net.IP{192, 0, 2, 1}, netip.MustParseAddr("2001:db8::1"), network, netip.MustParsePrefix("2001:db8::/32")},
+		{net.IP{192, 0, 2, 2}, netip.MustParseAddr("192.0.2.3"), private, netip.MustParsePrefix("10.0.0.1/32")},
+	}
+	if !reflect.DeepEqual(dst, expect) {
+		t.Fatalf("Expected %+v, got %+v", expect, dst)
+	}
+
+	for key, value := range map[string]interface{}{"Source": "192.0.2.300", "Target": "10.0.0.0/8", "Prefix": "10.0.0.1/40"} {
+		func() {
+			defer func() {
+				if err, _ := recover().(*absorb.ConversionError); err == nil || err.Key != key || err.Err == nil {
+					t.Fatalf("Expected a conversion error for %v, got %v", value, err)
+				}
+			}()
+			abs := absorb.New(&dst)
+			abs.Open("", 1, key)
+			abs.Absorb(value)
+		}()
+	}
+}
@@ -0,0 +1,203 @@
+package absorb
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// GroupBy absorbs rows into a map[K][]T destination, keyed by the value of the named column
+// converted to K. Each element is appended to the slice for its key.
+//
+// Alternatively, a struct field of T tagged `absorb:"group"` selects the key, so that
+// GroupBy is not needed:
+//
+//	type Player struct {
+//		Name string
+//		Team string `absorb:"group"`
+//	}
+//	var teams map[string][]Player
+//	err := absorb.Absorb(&teams, rows)
+//
+// Like a slice, the map is replaced when the Absorber is opened.
+func GroupBy(key string) Option {
+	return func(c *config) {
+		c.groupBy = key
+	}
+}
+
+// IndexBy absorbs rows into a map[K]T destination, keyed by the value of the named column
+// converted to K. When several rows have the same key, the last one is kept.
+//
+// Alternatively, a struct field of T tagged `absorb:"key"` selects the key:
+//
+//	type User struct {
+//		ID   int `absorb:"key"`
+//		Name string
+//	}
+//	var users map[int]User
+//	err := absorb.Absorb(&users, rows)
+//
+// For a set, such as a map[K]struct{} or Set[K], IndexBy names the column holding each
+// member; Otherwise each row builds a member of type K. Similarly, when rows have two
+// columns and T is neither a struct nor a map, each element is built from the column
+// beside the key.
+//
+// Without either, a map destination holds a single row, keyed by column name.
+// Like a slice, the map is replaced when the Absorber is opened.
+func IndexBy(key string) Option {
+	return func(c *config) {
+		c.indexBy = key
+	}
+}
+
+// Lookup absorbs src into a map of V, keyed by the value of the column named keyColumn
+// converted to K, as with IndexBy. When several rows have the same key, the last one is
+// kept.
+//
+// V may be a struct built from the whole row, or for a source of two columns, a value
+// built from the column beside the key:
+//
+//	names, err := absorb.Lookup[int, string](rows, "id")
+func Lookup[K comparable, V any](src Absorbable, keyColumn string, opts ...Option) (map[K]V, error) {
+	var m map[K]V
+	opts = append(opts[:len(opts):len(opts)], IndexBy(keyColumn))
+	err := Absorb(&m, src, opts...)
+	return m, err
+}
+
+// keyedPlan locates the map key for each element of a grouped or indexed destination.
+type keyedPlan struct {
+	keyType reflect.Type
+	// group is true if elements are appended to slices, rather than set directly.
+	group bool
+	// set is true if each row builds a key of a map[K]struct{}.
+	set bool
+	// column is the index of the key's column, or -1 if the key is read from field.
+	column int
+	field  []int
+	// value is the index of the only column that builds each element, or -1 if elements
+	// are built from the whole row.
+	value int
+}
+
+// planKeyed returns a plan if mapType should be absorbed as groups or as an index of
+// elements, or nil if it holds a single row.
+// Panics if an option names a key column that is not among keys.
+func (c *config) planKeyed(mapType reflect.Type, keys []string) *keyedPlan {
+	plan := &keyedPlan{keyType: mapType.Key(), column: -1, value: -1}
+	elemType := mapType.Elem()
+	if elemType.Kind() == reflect.Struct && elemType.NumField() == 0 {
+		// Sets are built from a row, or from the column named by IndexBy.
+		plan.set = true
+		if c.indexBy != "" {
+			plan.column = keyColumn(keys, c.indexBy)
+			plan.value = plan.column
+		}
+		return plan
+	}
+	var column, tag string
+	if plan.group = elemType.Kind() == reflect.Slice; plan.group {
+		column, tag = c.groupBy, "group"
+		elemType = elemType.Elem()
+	} else {
+		column, tag = c.indexBy, "key"
+	}
+
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	if column != "" {
+		plan.column = keyColumn(keys, column)
+		if kind := elemType.Kind(); kind != reflect.Struct && kind != reflect.Map && len(keys) == 2 {
+			// Scalar elements are built from the column beside the key, as in a lookup table.
+			plan.value = 1 - plan.column
+		}
+		return plan
+	}
+
+	if elemType.Kind() != reflect.Struct {
+		return nil
+	}
+	for i := 0; i < elemType.NumField(); i++ {
+		field := elemType.Field(i)
+		if name, _ := parseTag(field.Tag.Get("absorb")); name == tag {
+			plan.field = field.Index
+			return plan
+		}
+	}
+	return nil
+}
+
+// keyColumn returns the index of column in keys. Panics if it is missing.
+func keyColumn(keys []string, column string) int {
+	for idx, key := range keys {
+		if key == column {
+			return idx
+		}
+	}
+	panic(fmt.Errorf("%w: cannot key map by %q", ErrMissingKey, column))
+}
+
+// elemType returns the type of the elements built for a map of type mapType.
+func (k *keyedPlan) elemType(mapType reflect.Type) reflect.Type {
+	if k.set {
+		return mapType.Key()
+	}
+	if k.group {
+		return mapType.Elem().Elem()
+	}
+	return mapType.Elem()
+}
+
+// project returns the values to build an element from: all of them, unless elements are
+// built from a single column.
+func (k *keyedPlan) project(values []interface{}) []interface{} {
+	if k.value >= 0 {
+		return values[k.value : k.value+1]
+	}
+	return values
+}
+
+// insert stores elem under its key in the map m. values holds the whole row.
+func (k *keyedPlan) insert(m, elem reflect.Value, values []interface{}, cfg *config) {
+	if k.set {
+		m.SetMapIndex(reflect.Indirect(elem), reflect.Zero(m.Type().Elem()))
+		return
+	}
+	key := k.key(elem, values, cfg)
+	if k.elemType(m.Type()).Kind() != reflect.Ptr {
+		elem = reflect.Indirect(elem)
+	}
+	if k.group {
+		group := m.MapIndex(key)
+		if !group.IsValid() {
+			group = reflect.Zero(m.Type().Elem())
+		}
+		elem = reflect.Append(group, elem)
+	}
+	m.SetMapIndex(key, elem)
+}
+
+// remove deletes the entry with elem's key from the map m. values holds the whole row.
+func (k *keyedPlan) remove(m, elem reflect.Value, values []interface{}, cfg *config) {
+	if k.set {
+		m.SetMapIndex(reflect.Indirect(elem), reflect.Value{})
+		return
+	}
+	m.SetMapIndex(k.key(elem, values, cfg), reflect.Value{})
+}
+
+// key returns the map key of elem, built from values.
+func (k *keyedPlan) key(elem reflect.Value, values []interface{}, cfg *config) reflect.Value {
+	key := reflect.New(k.keyType).Elem()
+	var src reflect.Value
+	if k.column >= 0 {
+		src = reflect.ValueOf(values[k.column])
+	} else {
+		src, _ = reflect.Indirect(elem).FieldByIndexErr(k.field)
+	}
+	if src.IsValid() {
+		_assign(key, src, cfg)
+	}
+	return key
+}
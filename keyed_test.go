@@ -0,0 +1,115 @@
+package absorb_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jyopp/absorb"
+)
+
+type player struct {
+	Name string
+	Team string `absorb:"group"`
+}
+
+type playerSource [][2]string
+
+func (ps playerSource) Emit(into absorb.Absorber) error {
+	into.Open("test", len(ps), "Name", "Team")
+	defer into.Close()
+	for _, p := range ps {
+		into.Absorb(p[0], p[1])
+	}
+	return nil
+}
+
+func TestGroupBy(t *testing.T) {
+	src := playerSource{{"ann", "red"}, {"bob", "blue"}, {"cat", "red"}}
+
+	var teams map[string][]player
+	if err := absorb.Absorb(&teams, src); err != nil {
+		t.Fatal(err)
+	}
+	expect := map[string][]player{
+		"red":  {{"ann", "red"}, {"cat", "red"}},
+		"blue": {{"bob", "blue"}},
+	}
+	if !reflect.DeepEqual(teams, expect) {
+		t.Fatalf("Expected %+v, got %+v", expect, teams)
+	}
+
+	// The option groups by any column, into slices of pointers.
+	var byName map[string][]*TestDst
+	if err := absorb.Absorb(&byName, src, absorb.GroupBy("Team")); err != nil {
+		t.Fatal(err)
+	}
+	if len(byName["red"]) != 2 || byName["red"][1].Name != "cat" {
+		t.Fatalf("Unexpected groups %+v", byName)
+	}
+
+	subpanic(t, "Missing Key", func() {
+		absorb.Absorb(&byName, src, absorb.GroupBy("Coach"))
+	})
+}
+
+func TestIndexBy(t *testing.T) {
+	type user struct {
+		ID   int `absorb:"key"`
+		Name string
+	}
+	src := repeatSource{3, 1, 3}
+
+	var byID map[int64]user
+	if err := absorb.Absorb(&byID, src, absorb.NormalizeKeys(func(key string) string {
+		if key == "Aliased" {
+			return "ID"
+		}
+		return key
+	})); err != nil {
+		t.Fatal(err)
+	}
+	if expect := map[int64]user{1: {1, "test"}, 3: {3, "test"}}; !reflect.DeepEqual(byID, expect) {
+		t.Fatalf("Expected %+v, got %+v", expect, byID)
+	}
+
+	var byAlias map[int]*TestDst
+	if err := absorb.Absorb(&byAlias, src, absorb.IndexBy("Aliased")); err != nil {
+		t.Fatal(err)
+	}
+	if len(byAlias) != 2 || byAlias[3].Actual != 3 {
+		t.Fatalf("Unexpected index %+v", byAlias)
+	}
+
+	// Without a key, a map still holds a single row.
+	var row map[string]interface{}
+	if err := absorb.Absorb(&row, testSource{i: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if row["Name"] != "test" {
+		t.Fatalf("Unexpected row %+v", row)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	src := playerSource{{"ann", "red"}, {"bob", "blue"}}
+
+	teams, err := absorb.Lookup[string, string](src, "Name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := map[string]string{"ann": "red", "bob": "blue"}; !reflect.DeepEqual(teams, expect) {
+		t.Fatalf("Expected %+v, got %+v", expect, teams)
+	}
+
+	players, err := absorb.Lookup[string, *player](src, "Name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(players) != 2 || players["bob"].Team != "blue" {
+		t.Fatalf("Unexpected lookup %+v", players)
+	}
+
+	subpanic(t, "Missing Key", func() {
+		absorb.Lookup[string, string](src, "Coach")
+	})
+}
@@ -0,0 +1,58 @@
+package absorb
+
+import (
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Logger receives debug-level events describing an absorption run. Args are alternating
+// keys and values. The method matches (*slog.Logger).Debug, so a *slog.Logger can be
+// passed to WithLogger directly.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+}
+
+// WithLogger reports events to l: the keys and resolved mapping on Open, each distinct
+// conversion that falls back to reflect's Convert, rows that could not be sent to a
+// channel, and row counts on Close.
+func WithLogger(l Logger) Option {
+	return func(c *config) {
+		c.logger = &logState{Logger: l}
+	}
+}
+
+// logState holds a Logger along with the state needed to avoid repeating events.
+type logState struct {
+	Logger
+	// mu guards conversions, which may be logged from Parallel workers.
+	mu          sync.Mutex
+	conversions map[[2]reflect.Type]bool
+	opened      time.Time
+}
+
+// logConversion logs the first conversion between each pair of types.
+func (l *logState) logConversion(from, to reflect.Type) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.conversions == nil {
+		l.conversions = make(map[[2]reflect.Type]bool)
+	}
+	if pair := [2]reflect.Type{from, to}; !l.conversions[pair] {
+		l.conversions[pair] = true
+		l.Debug("absorb: converting with reflect", "from", from.String(), "to", to.String())
+	}
+}
+
+// mapping describes how each key is mapped, as "key=Field" for struct fields, or
+// "key=" for keys that do not map to any field.
+func (a *elementBuilder) mapping() []string {
+	if a.Fields == nil {
+		return nil
+	}
+	mapping := make([]string, len(a.Keys))
+	for idx, key := range a.Keys {
+		mapping[idx] = key + "=" + a.Fields[idx].Name
+	}
+	return mapping
+}
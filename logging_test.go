@@ -0,0 +1,39 @@
+package absorb_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jyopp/absorb"
+)
+
+type testLogger []string
+
+func (l *testLogger) Debug(msg string, args ...interface{}) {
+	*l = append(*l, fmt.Sprintln(append([]interface{}{msg}, args...)...))
+}
+
+func TestLogger(t *testing.T) {
+	var log testLogger
+	var dst []struct {
+		Name   string
+		Actual int64 `test:"Aliased"`
+	}
+	if err := absorb.Absorb(&dst, testSource{i: 3}, absorb.WithLogger(&log)); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(log) != 3 {
+		t.Fatalf("Expected open, one conversion, and close events, got %q", log)
+	}
+	if !strings.Contains(log[0], "Aliased=Actual") {
+		t.Fatalf("Open event does not describe mapping: %q", log[0])
+	}
+	if !strings.Contains(log[1], "int64") {
+		t.Fatalf("Conversion event does not name types: %q", log[1])
+	}
+	if !strings.Contains(log[2], "rows 3") {
+		t.Fatalf("Close event does not count rows: %q", log[2])
+	}
+}
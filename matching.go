@@ -0,0 +1,120 @@
+package absorb
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// KeyMatching selects how keys passed to Open are matched to struct fields that are not
+// named by a tag, and to the keys of tags themselves.
+type KeyMatching int
+
+const (
+	// MatchDefault matches a key to a tag or field name exactly, or else matches its
+	// lowercased form to a tag or lowercased field name. If two fields lowercase to the
+	// same name, the first field declared wins.
+	MatchDefault KeyMatching = iota
+	// MatchExact matches a key only to a tag or field name of the same case.
+	MatchExact
+	// MatchFold matches a key exactly if possible, or else to the only tag or field name
+	// equal under Unicode case folding. Open panics if the key folds to several fields.
+	MatchFold
+)
+
+// MatchKeys sets how keys are matched to struct fields. See KeyMatching.
+func MatchKeys(mode KeyMatching) Option {
+	return func(c *config) {
+		c.matcher = &keyMatcher{mode: mode}
+	}
+}
+
+// MatchKeysWith matches keys to struct fields with fn, which reports whether a key passed
+// to Open refers to a field with the given tag key or field name. A key with an exact
+// match is matched to it; Otherwise, Open panics if fn matches the key to several fields.
+//
+// Mappings made with fn are not cached, as fn cannot be compared with other functions.
+func MatchKeysWith(fn func(key, name string) bool) Option {
+	return func(c *config) {
+		c.matcher = &keyMatcher{mode: MatchFold, equal: fn}
+	}
+}
+
+// keyMatcher matches keys to the names of fields. A nil keyMatcher uses MatchDefault.
+type keyMatcher struct {
+	mode  KeyMatching
+	equal func(key, name string) bool
+}
+
+// matching returns the matcher's mode.
+func (m *keyMatcher) matching() KeyMatching {
+	if m == nil {
+		return MatchDefault
+	}
+	return m.mode
+}
+
+// fingerprint identifies the matcher for use in builder cache keys. Returns false if
+// mappings made with it cannot be cached.
+func (m *keyMatcher) fingerprint() (string, bool) {
+	switch {
+	case m.matching() == MatchDefault:
+		return "", true
+	case m.equal != nil:
+		return "", false
+	}
+	return "match=" + strconv.Itoa(int(m.mode)), true
+}
+
+// fieldNames maps the tag keys and names of a struct's fields, in declaration order.
+type fieldNames struct {
+	names  []string
+	fields map[string]reflect.StructField
+}
+
+func (f *fieldNames) add(name string, field reflect.StructField) {
+	if _, ok := f.fields[name]; !ok {
+		f.names = append(f.names, name)
+	}
+	f.fields[name] = field
+}
+
+// addNew adds name only if no field has it already.
+func (f *fieldNames) addNew(name string, field reflect.StructField) {
+	if _, ok := f.fields[name]; !ok {
+		f.add(name, field)
+	}
+}
+
+// lookup returns the field that key refers to, according to m.
+func (m *keyMatcher) lookup(f *fieldNames, key string, structType reflect.Type) (reflect.StructField, bool) {
+	if field, ok := f.fields[key]; ok {
+		return field, true
+	}
+	switch m.matching() {
+	case MatchDefault:
+		// Untagged fields are also mapped by their lowercased names.
+		field, ok := f.fields[strings.ToLower(key)]
+		return field, ok
+	case MatchFold:
+		equal := strings.EqualFold
+		if m.equal != nil {
+			equal = m.equal
+		}
+		var match reflect.StructField
+		var found bool
+		for _, name := range f.names {
+			field := f.fields[name]
+			if !equal(key, name) || found && reflect.DeepEqual(field.Index, match.Index) {
+				continue
+			}
+			if found {
+				panic("cannot absorb ambiguous key " + strconv.Quote(key) + " into " + structType.String() +
+					"; it matches fields " + match.Name + " and " + field.Name)
+			}
+			match, found = field, true
+		}
+		return match, found
+	}
+	return reflect.StructField{}, false
+}
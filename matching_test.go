@@ -0,0 +1,56 @@
+package absorb_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jyopp/absorb"
+)
+
+type casedDst struct {
+	URL    string
+	Url    string
+	Name   string
+	Tagged int `test:"Count"`
+}
+
+func absorbCased(opts []absorb.Option, keys []string, values ...interface{}) casedDst {
+	var dst casedDst
+	abs := absorb.New(&dst, opts...)
+	abs.Open("test", 1, keys...)
+	abs.Absorb(values...)
+	abs.Close()
+	return dst
+}
+
+func TestMatchKeys(t *testing.T) {
+	keys := []string{"url", "NAME", "count"}
+
+	// By default, lowercased keys match the first field that lowercases to them.
+	if dst := absorbCased(nil, keys, "a", "b", 1); dst.URL != "a" || dst.Name != "b" || dst.Tagged != 0 {
+		t.Fatalf("Unexpected default mapping %+v", dst)
+	}
+
+	exact := []absorb.Option{absorb.MatchKeys(absorb.MatchExact)}
+	if dst := absorbCased(exact, keys, "a", "b", 1); dst != (casedDst{}) {
+		t.Fatalf("Expected no exact matches, got %+v", dst)
+	}
+	if dst := absorbCased(exact, []string{"Url", "Count"}, "a", 1); dst.Url != "a" || dst.URL != "" || dst.Tagged != 1 {
+		t.Fatalf("Unexpected exact mapping %+v", dst)
+	}
+
+	fold := []absorb.Option{absorb.MatchKeys(absorb.MatchFold)}
+	if dst := absorbCased(fold, []string{"NAME", "count", "URL"}, "b", 1, "a"); dst.Name != "b" || dst.Tagged != 1 || dst.URL != "a" {
+		t.Fatalf("Unexpected folded mapping %+v", dst)
+	}
+	subpanic(t, "Ambiguous", func() {
+		absorbCased(fold, []string{"uRl"}, "a")
+	})
+
+	snake := absorb.MatchKeysWith(func(key, name string) bool {
+		return strings.EqualFold(strings.ReplaceAll(key, "_", ""), name)
+	})
+	if dst := absorbCased([]absorb.Option{snake}, []string{"na_me", "Url"}, "b", "a"); dst.Name != "b" || dst.Url != "a" {
+		t.Fatalf("Unexpected custom mapping %+v", dst)
+	}
+}
@@ -0,0 +1,90 @@
+package absorb
+
+// Row is a single row of values passing through a middleware Absorber.
+type Row struct {
+	// Keys holds the keys given to Open. It is shared by every row, and must not be modified.
+	Keys   []string
+	Values []interface{}
+}
+
+// Value returns the row's value for key, or nil if the row has no such key.
+func (r Row) Value(key string) interface{} {
+	for idx, k := range r.Keys {
+		if k == key {
+			return r.Values[idx]
+		}
+	}
+	return nil
+}
+
+// Filter returns an Absorber that passes only the rows for which keep returns true to next.
+func Filter(next Absorber, keep func(Row) bool) Absorber {
+	return &filterAbsorber{middleware: middleware{next: next}, keep: keep}
+}
+
+// Map returns an Absorber that passes the values returned by fn for each row to next.
+// fn may modify and return the row's Values, but must return one value for each key.
+func Map(next Absorber, fn func(Row) []interface{}) Absorber {
+	return &mapAbsorber{middleware: middleware{next: next}, fn: fn}
+}
+
+// Limit returns an Absorber that passes at most n rows to next, and discards the rest.
+// The source is still read to its end.
+func Limit(next Absorber, n int) Absorber {
+	return &limitAbsorber{middleware: middleware{next: next}, limit: n}
+}
+
+// middleware holds the state shared by Absorbers that wrap another Absorber.
+type middleware struct {
+	next Absorber
+	keys []string
+}
+
+func (m *middleware) Open(tag string, count int, keys ...string) {
+	m.keys = keys
+	m.next.Open(tag, count, keys...)
+}
+
+func (m *middleware) Close() {
+	m.next.Close()
+}
+
+type filterAbsorber struct {
+	middleware
+	keep func(Row) bool
+}
+
+func (f *filterAbsorber) Absorb(values ...interface{}) {
+	if f.keep(Row{Keys: f.keys, Values: values}) {
+		f.next.Absorb(values...)
+	}
+}
+
+type mapAbsorber struct {
+	middleware
+	fn func(Row) []interface{}
+}
+
+func (m *mapAbsorber) Absorb(values ...interface{}) {
+	m.next.Absorb(m.fn(Row{Keys: m.keys, Values: values})...)
+}
+
+type limitAbsorber struct {
+	middleware
+	limit, seen int
+}
+
+func (l *limitAbsorber) Open(tag string, count int, keys ...string) {
+	l.seen = 0
+	if count < 0 || count > l.limit {
+		count = l.limit
+	}
+	l.middleware.Open(tag, count, keys...)
+}
+
+func (l *limitAbsorber) Absorb(values ...interface{}) {
+	if l.seen < l.limit {
+		l.seen++
+		l.next.Absorb(values...)
+	}
+}
@@ -0,0 +1,178 @@
+package absorb
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// NumericConversion classifies the conversion between two numeric kinds.
+type NumericConversion int
+
+const (
+	// NotNumeric means that either kind is not numeric, or the kinds cannot be converted;
+	// Complex numbers only convert to other complex numbers.
+	NotNumeric NumericConversion = iota
+	// Lossless conversions represent every value of the source kind exactly.
+	Lossless
+	// Narrowing conversions may overflow, change sign, or lose precision. By default they
+	// follow Go's conversion rules, silently wrapping or truncating. With CheckedNumbers,
+	// values that do not survive the conversion cause a panic instead.
+	Narrowing
+)
+
+func (c NumericConversion) String() string {
+	switch c {
+	case Lossless:
+		return "Lossless"
+	case Narrowing:
+		return "Narrowing"
+	}
+	return "NotNumeric"
+}
+
+type numericClass int
+
+const (
+	classNone numericClass = iota
+	classInt
+	classUint
+	classFloat
+	classComplex
+)
+
+// numericInfo returns the class of kind, and its size in bits.
+// Platform-dependent kinds use the platform's size.
+func numericInfo(kind reflect.Kind) (numericClass, int) {
+	switch kind {
+	case reflect.Int8:
+		return classInt, 8
+	case reflect.Int16:
+		return classInt, 16
+	case reflect.Int32:
+		return classInt, 32
+	case reflect.Int64:
+		return classInt, 64
+	case reflect.Int:
+		return classInt, strconv.IntSize
+	case reflect.Uint8:
+		return classUint, 8
+	case reflect.Uint16:
+		return classUint, 16
+	case reflect.Uint32:
+		return classUint, 32
+	case reflect.Uint64:
+		return classUint, 64
+	case reflect.Uint, reflect.Uintptr:
+		return classUint, strconv.IntSize
+	case reflect.Float32:
+		return classFloat, 32
+	case reflect.Float64:
+		return classFloat, 64
+	case reflect.Complex64:
+		return classComplex, 64
+	case reflect.Complex128:
+		return classComplex, 128
+	}
+	return classNone, 0
+}
+
+// NumericConversionFor reports how absorbing a value of kind from into a field of kind to
+// behaves. For example, int64 database columns convert losslessly into int64 and float64
+// fields' integer range, but are Narrowing for int32, uint, and float32 fields.
+func NumericConversionFor(from, to reflect.Kind) NumericConversion {
+	fromClass, fromBits := numericInfo(from)
+	toClass, toBits := numericInfo(to)
+	if fromClass == classNone || toClass == classNone || (fromClass == classComplex) != (toClass == classComplex) {
+		return NotNumeric
+	}
+
+	lossless := false
+	switch fromClass {
+	case classInt:
+		switch toClass {
+		case classInt:
+			lossless = toBits >= fromBits
+		case classFloat:
+			// Floats hold integers exactly up to the width of their mantissa.
+			lossless = fromBits <= mantissaBits(toBits)
+		}
+	case classUint:
+		switch toClass {
+		case classInt:
+			lossless = toBits > fromBits
+		case classUint:
+			lossless = toBits >= fromBits
+		case classFloat:
+			lossless = fromBits <= mantissaBits(toBits)
+		}
+	case classFloat, classComplex:
+		lossless = toClass == fromClass && toBits >= fromBits
+	}
+	if lossless {
+		return Lossless
+	}
+	return Narrowing
+}
+
+// mantissaBits returns the number of integer bits a float of the given size holds exactly.
+func mantissaBits(floatBits int) int {
+	if floatBits == 32 {
+		return 24
+	}
+	return 53
+}
+
+// CheckedNumbers makes Narrowing numeric conversions panic with an error wrapping
+// ErrNumericRange when a value overflows, changes sign, or loses its fractional part.
+// Floating point values may still be rounded to the nearest representable value, but
+// finite values may not become infinite.
+func CheckedNumbers() Option {
+	return func(c *config) {
+		c.checkedNumbers = true
+	}
+}
+
+// convertNumber converts src to dstType, panicking if the conversion is Narrowing and
+// the value does not survive it. Returns false if the types are not both numeric.
+func convertNumber(dst, src reflect.Value, dstType reflect.Type) bool {
+	if NumericConversionFor(src.Kind(), dstType.Kind()) != Narrowing {
+		return false
+	}
+	converted := src.Convert(dstType)
+
+	fromClass, _ := numericInfo(src.Kind())
+	toClass, _ := numericInfo(dstType.Kind())
+	fits := true
+	switch {
+	case fromClass == classInt && toClass == classUint:
+		fits = src.Int() >= 0 && converted.Convert(src.Type()).Int() == src.Int()
+	case fromClass == classUint && toClass == classInt:
+		fits = converted.Int() >= 0 && converted.Convert(src.Type()).Uint() == src.Uint()
+	case toClass == classFloat:
+		if fromClass == classFloat {
+			f := src.Float()
+			fits = math.IsInf(f, 0) || math.IsNaN(f) || !math.IsInf(converted.Float(), 0)
+		} else {
+			// Integers are rounded to the nearest float, but must remain in range.
+			fits = !math.IsInf(converted.Float(), 0)
+		}
+	case toClass == classComplex:
+		c := src.Complex()
+		fits = cmplxIsInf(c) || !cmplxIsInf(converted.Complex())
+	default:
+		// Integer and float to integer conversions must round-trip exactly.
+		back := converted.Convert(src.Type())
+		fits = back.Interface() == src.Interface()
+	}
+	if !fits {
+		panic(fmt.Errorf("%w: %v does not fit in %s", ErrNumericRange, src.Interface(), dstType))
+	}
+	dst.Set(converted)
+	return true
+}
+
+func cmplxIsInf(c complex128) bool {
+	return math.IsInf(real(c), 0) || math.IsInf(imag(c), 0)
+}
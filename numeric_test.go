@@ -0,0 +1,150 @@
+package absorb_test
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/jyopp/absorb"
+)
+
+// numericExtremes holds values at the edges of each numeric kind's range.
+var numericExtremes = map[reflect.Kind][]interface{}{
+	reflect.Int:        {math.MinInt, math.MaxInt},
+	reflect.Int8:       {int8(math.MinInt8), int8(math.MaxInt8)},
+	reflect.Int16:      {int16(math.MinInt16), int16(math.MaxInt16)},
+	reflect.Int32:      {int32(math.MinInt32), int32(math.MaxInt32)},
+	reflect.Int64:      {int64(math.MinInt64), int64(math.MaxInt64)},
+	reflect.Uint:       {uint(0), uint(math.MaxUint)},
+	reflect.Uint8:      {uint8(0), uint8(math.MaxUint8)},
+	reflect.Uint16:     {uint16(0), uint16(math.MaxUint16)},
+	reflect.Uint32:     {uint32(0), uint32(math.MaxUint32)},
+	reflect.Uint64:     {uint64(0), uint64(math.MaxUint64)},
+	reflect.Uintptr:    {uintptr(0), ^uintptr(0)},
+	reflect.Float32:    {float32(-math.MaxFloat32), float32(math.SmallestNonzeroFloat32), float32(math.MaxFloat32)},
+	reflect.Float64:    {-math.MaxFloat64, math.SmallestNonzeroFloat64, math.MaxFloat64},
+	reflect.Complex64:  {complex(float32(math.MaxFloat32), float32(-math.MaxFloat32))},
+	reflect.Complex128: {complex(math.MaxFloat64, -math.MaxFloat64)},
+}
+
+// Odd integers just beyond a float's mantissa are the smallest integers it cannot hold.
+var mantissaEdges = map[reflect.Kind]interface{}{
+	reflect.Int32:  int32(1<<24 + 1),
+	reflect.Uint32: uint32(1<<24 + 1),
+	reflect.Int64:  int64(1<<53 + 1),
+	reflect.Uint64: uint64(1<<53 + 1),
+	reflect.Int:    int(1<<53 + 1),
+	reflect.Uint:   uint(1<<53 + 1),
+}
+
+// exact returns the mathematical value of a real or complex number.
+func exact(v reflect.Value) [2]*big.Float {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return [2]*big.Float{new(big.Float).SetInt64(v.Int()), new(big.Float)}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return [2]*big.Float{new(big.Float).SetUint64(v.Uint()), new(big.Float)}
+	case reflect.Float32, reflect.Float64:
+		return [2]*big.Float{big.NewFloat(v.Float()), new(big.Float)}
+	}
+	c := v.Complex()
+	return [2]*big.Float{big.NewFloat(real(c)), big.NewFloat(imag(c))}
+}
+
+// preserves reports whether converting value to the given type keeps its exact value.
+func preserves(value interface{}, to reflect.Type) bool {
+	src := reflect.ValueOf(value)
+	before, after := exact(src), exact(src.Convert(to))
+	return before[0].Cmp(after[0]) == 0 && before[1].Cmp(after[1]) == 0
+}
+
+func TestNumericConversionTable(t *testing.T) {
+	for from, extremes := range numericExtremes {
+		for to := range numericExtremes {
+			conversion := absorb.NumericConversionFor(from, to)
+			toType := reflect.TypeOf(numericExtremes[to][0])
+			fromType := reflect.TypeOf(extremes[0])
+
+			if !fromType.ConvertibleTo(toType) {
+				if conversion != absorb.NotNumeric {
+					t.Errorf("%v -> %v is %v, but not convertible", from, to, conversion)
+				}
+				continue
+			}
+
+			values := extremes
+			if edge, ok := mantissaEdges[from]; ok {
+				values = append(values[:len(values):len(values)], edge)
+			}
+			lossless := true
+			for _, value := range values {
+				lossless = lossless && preserves(value, toType)
+			}
+
+			switch conversion {
+			case absorb.Lossless:
+				if !lossless {
+					t.Errorf("%v -> %v is Lossless, but extremes are not preserved", from, to)
+				}
+			case absorb.Narrowing:
+				if lossless {
+					t.Errorf("%v -> %v is Narrowing, but extremes are preserved", from, to)
+				}
+			default:
+				t.Errorf("%v -> %v is %v, but convertible", from, to, conversion)
+			}
+		}
+	}
+
+	if c := absorb.NumericConversionFor(reflect.String, reflect.Int); c != absorb.NotNumeric {
+		t.Error("Expected string -> int to be NotNumeric, got", c)
+	}
+}
+
+func TestCheckedNumbers(t *testing.T) {
+	absorbInto := func(dst interface{}, value interface{}, opts ...absorb.Option) {
+		abs := absorb.New(dst, opts...)
+		abs.Open("", 1)
+		abs.Absorb(value)
+		abs.Close()
+	}
+
+	// By default, narrowing follows Go's conversion rules.
+	var i8 int8
+	absorbInto(&i8, int64(300))
+	if i8 != 44 {
+		t.Fatal("Expected int64(300) to wrap to 44, got", i8)
+	}
+
+	var u uint
+	var i int
+	var f32 float32
+	for name, tc := range map[string]struct {
+		dst, value interface{}
+		ok         bool
+	}{
+		"Overflow":          {&i8, int64(300), false},
+		"In Range":          {&i8, int64(-128), true},
+		"Sign Change":       {&u, -1, false},
+		"Unsigned Overflow": {&i, uint64(math.MaxUint64), false},
+		"Fraction":          {&i, 1.5, false},
+		"Whole Float":       {&i, 2.0, true},
+		"NaN":               {&i, math.NaN(), false},
+		"Float Overflow":    {&f32, math.MaxFloat64, false},
+		"Float Rounding":    {&f32, 0.1, true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				err, _ := recover().(error)
+				if tc.ok && err != nil {
+					t.Fatal("Unexpected panic", err)
+				} else if !tc.ok && !errors.Is(err, absorb.ErrNumericRange) {
+					t.Fatal("Expected ErrNumericRange, got", err)
+				}
+			}()
+			absorbInto(tc.dst, tc.value, absorb.CheckedNumbers())
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package absorb
+
+import (
+	"errors"
+	"reflect"
+)
+
+// ErrorAction is returned by an ErrorHandler to decide how a failed assignment is handled.
+type ErrorAction int
+
+const (
+	// Abort panics with the error, as an Absorber does without an ErrorHandler.
+	Abort ErrorAction = iota
+	// Skip discards the row, leaving no element for it in the destination.
+	Skip
+	// Zero leaves the field, or the map entry, at its zero value, and absorbs the rest
+	// of the row.
+	Zero
+)
+
+// ErrorHandler decides how to handle a value that cannot be assigned to its field. Row
+// is the number of the row in the source, counting from 1, key is the value's key, and
+// err is the *ConversionError describing the failure.
+type ErrorHandler func(row int, key string, err error) ErrorAction
+
+// OnError consults fn whenever a value cannot be assigned to a field of a struct or map
+// element, so that callers can tolerate bad data field by field. Like CollectErrors, a
+// skipped row may be partly applied to an element updated with Partial or Operations.
+//
+// Example:
+//
+//	absorb.OnError(func(row int, key string, err error) absorb.ErrorAction {
+//		if key == "middle_name" {
+//			return absorb.Zero
+//		}
+//		return absorb.Abort
+//	})
+func OnError(fn ErrorHandler) Option {
+	return func(c *config) {
+		c.onError = fn
+	}
+}
+
+// errSkipRow is the panic value that unwinds a row skipped by an ErrorHandler.
+var errSkipRow = errors.New("absorb: row skipped")
+
+// fieldErrorHandler handles the failure to assign the value of the key at index key,
+// returning Zero or Abort. It panics with errSkipRow to skip the row.
+type fieldErrorHandler func(key int, err *ConversionError) ErrorAction
+
+// errorHandler returns the handler for failures in the row at idx, or nil if there is no
+// ErrorHandler.
+func (a *absorberImpl) errorHandler(idx int) fieldErrorHandler {
+	fn := a.cfg.onError
+	if fn == nil {
+		return nil
+	}
+	row := a.cfg.resume + idx + 1
+	return func(key int, err *ConversionError) ErrorAction {
+		err.Row = row
+		switch action := fn(row, err.Key, err); action {
+		case Skip:
+			panic(errSkipRow)
+		default:
+			return action
+		}
+	}
+}
+
+// guard calls assign, which assigns the value of the key at index key, and consults
+// onError if it panics. Returns true if the value should be zeroed instead.
+func (a *elementBuilder) guard(onError fieldErrorHandler, key int, value interface{}, assign func()) (zero bool) {
+	defer func() {
+		if p := recover(); p != nil {
+			if p == errSkipRow {
+				panic(p)
+			}
+			err := a.conversionError(p, key, value)
+			if onError(key, err) != Zero {
+				panic(err)
+			}
+			zero = true
+		}
+	}()
+	assign()
+	return false
+}
+
+// zeroField sets the field for the key at index key to its zero value.
+func (a *elementBuilder) zeroField(elem reflect.Value, key int) {
+	f := fieldByIndex(elem, a.Fields[key].Index)
+	f.Set(reflect.Zero(f.Type()))
+}
@@ -0,0 +1,61 @@
+package absorb_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jyopp/absorb"
+)
+
+func TestOnError(t *testing.T) {
+	src := messySource{
+		{"a", 1},
+		{"b", "two"},
+		{[]int{3}, 3},
+		{"d", 4},
+	}
+	type call struct {
+		row int
+		key string
+	}
+	var calls []call
+	handler := absorb.OnError(func(row int, key string, err error) absorb.ErrorAction {
+		var convErr *absorb.ConversionError
+		if !errors.As(err, &convErr) || convErr.Row != row || convErr.Key != key {
+			t.Errorf("Unexpected error %v for row %d, key %q", err, row, key)
+		}
+		calls = append(calls, call{row, key})
+		if key == "Aliased" {
+			return absorb.Zero
+		}
+		return absorb.Skip
+	})
+
+	var dst []TestDst
+	if err := absorb.Absorb(&dst, src, handler); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 2 || calls[0] != (call{2, "Aliased"}) || calls[1] != (call{3, "Name"}) {
+		t.Fatalf("Unexpected calls %v", calls)
+	}
+	expect := []TestDst{{Name: "a", Actual: 1}, {Name: "b"}, {Name: "d", Actual: 4}}
+	if len(dst) != len(expect) || dst[0] != expect[0] || dst[1] != expect[1] || dst[2] != expect[2] {
+		t.Fatalf("Expected %+v, got %+v", expect, dst)
+	}
+
+	// Map entries are zeroed the same way.
+	var rows []map[string]int
+	zero := absorb.OnError(func(int, string, error) absorb.ErrorAction { return absorb.Zero })
+	if err := absorb.Absorb(&rows, src, zero); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := rows[1]["Aliased"]; len(rows) != 4 || !ok || rows[1]["Aliased"] != 0 || rows[3]["Aliased"] != 4 {
+		t.Fatalf("Unexpected maps %v", rows)
+	}
+
+	subpanic(t, "Abort", func() {
+		absorb.Absorb(&dst, src, absorb.OnError(func(int, string, error) absorb.ErrorAction {
+			return absorb.Abort
+		}))
+	})
+}
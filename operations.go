@@ -0,0 +1,88 @@
+package absorb
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Operations applies each row to the destination according to the operation in the
+// named column, keeping an in-memory view consistent with a change stream, such as
+// those emitted by the source package's change-data-capture adapters.
+//
+// Inserts and updates replace the element with the row's key, or add one if there is
+// none; With the Partial option, updates assign only the row's non-nil values instead.
+// Deletes remove the element with the row's key.
+//
+// The destination must be a slice, a map indexed by key, or a set. Slices are matched to
+// rows by key as with Partial, and neither slices nor maps are replaced when the Absorber
+// is opened. Operations are read with ParseChangeOp. Panics on Open if the column is
+// missing, or if the destination has no key.
+func Operations(column string) Option {
+	return func(c *config) {
+		c.opColumn = column
+	}
+}
+
+// ParseChangeOp parses the name of an operation, as used by change-data-capture formats:
+// "insert", "update", or "delete", their initials, "create", "c", and "r" for snapshot
+// reads, which are inserts. Case is ignored.
+func ParseChangeOp(text string) (ChangeOp, error) {
+	switch strings.ToLower(strings.TrimSpace(text)) {
+	case "insert", "i", "create", "c", "r":
+		return Insert, nil
+	case "update", "u":
+		return Update, nil
+	case "delete", "d":
+		return Delete, nil
+	}
+	return 0, fmt.Errorf("absorb: unknown operation %q", text)
+}
+
+// planOperations locates the operation column among keys, and checks that the
+// destination can apply operations.
+func (a *absorberImpl) planOperations(keys []string) {
+	a.opIdx = -1
+	if a.cfg.opColumn == "" {
+		return
+	}
+	for idx, key := range keys {
+		if key == a.cfg.opColumn {
+			a.opIdx = idx
+			break
+		}
+	}
+	if a.opIdx < 0 {
+		panic(fmt.Errorf("%w: cannot apply operations from %q", ErrMissingKey, a.cfg.opColumn))
+	}
+	if a.upsert == nil && (a.keyed == nil || a.keyed.group) {
+		panic("cannot apply operations to " + a.setVal.Type().String() + " without a key")
+	}
+}
+
+// operation returns the operation of the row values.
+func (a *absorberImpl) operation(values []interface{}) ChangeOp {
+	switch op := values[a.opIdx].(type) {
+	case ChangeOp:
+		return op
+	case string:
+		if parsed, err := ParseChangeOp(op); err == nil {
+			return parsed
+		}
+	case []byte:
+		if parsed, err := ParseChangeOp(string(op)); err == nil {
+			return parsed
+		}
+	}
+	panic(&ConversionError{Key: a.cfg.opColumn, Src: reflect.TypeOf(values[a.opIdx]), Dst: reflect.TypeOf(Insert)})
+}
+
+// remove deletes the element with the key of the row at idx from the destination.
+func (a *absorberImpl) remove(idx int, values, row []interface{}) {
+	defer a.annotate(idx)
+	if a.upsert != nil {
+		a.upsert.remove(a.setVal, row, a.cfg)
+	} else {
+		a.keyed.remove(a.setVal, a.build(idx, values), row, a.cfg)
+	}
+}
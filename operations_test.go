@@ -0,0 +1,102 @@
+package absorb_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jyopp/absorb"
+)
+
+// changeSource emits rows of operation, ID, and name.
+type changeSource [][3]interface{}
+
+func (cs changeSource) Emit(into absorb.Absorber) error {
+	into.Open("", len(cs), "op", "ID", "Name")
+	defer into.Close()
+	for _, row := range cs {
+		into.Absorb(row[:]...)
+	}
+	return nil
+}
+
+type account struct {
+	ID    int `absorb:"key"`
+	Name  string
+	Email string
+}
+
+func TestOperationsSlice(t *testing.T) {
+	view := []account{{1, "ann", "ann@example.com"}, {2, "bob", "bob@example.com"}, {3, "cat", ""}}
+	src := changeSource{
+		{"u", 2, "rob"},
+		{"delete", 1, nil},
+		{"INSERT", 4, "dee"},
+		{absorb.Delete, 9, nil},
+	}
+	if err := absorb.Absorb(&view, src, absorb.Operations("op")); err != nil {
+		t.Fatal(err)
+	}
+	// Updates replace the element, so Email is cleared.
+	expect := []account{{2, "rob", ""}, {3, "cat", ""}, {4, "dee", ""}}
+	if !reflect.DeepEqual(view, expect) {
+		t.Fatalf("Expected %+v, got %+v", expect, view)
+	}
+
+	// With Partial, updates keep unassigned fields.
+	view = []account{{1, "ann", "ann@example.com"}}
+	if err := absorb.Absorb(&view, changeSource{{"update", 1, "anne"}}, absorb.Operations("op"), absorb.Partial()); err != nil {
+		t.Fatal(err)
+	}
+	if expect := []account{{1, "anne", "ann@example.com"}}; !reflect.DeepEqual(view, expect) {
+		t.Fatalf("Expected %+v, got %+v", expect, view)
+	}
+
+	// Deleted keys may be inserted again, after the elements that remain.
+	view = []account{{1, "ann", ""}, {2, "bob", ""}, {3, "cat", ""}}
+	src = changeSource{{"d", 1, nil}, {"i", 1, "anne"}, {"d", 3, nil}, {"u", 2, "rob"}, {"d", 1, nil}, {"i", 5, "eve"}}
+	if err := absorb.Absorb(&view, src, absorb.Operations("op")); err != nil {
+		t.Fatal(err)
+	}
+	if expect := []account{{2, "rob", ""}, {5, "eve", ""}}; !reflect.DeepEqual(view, expect) {
+		t.Fatalf("Expected %+v, got %+v", expect, view)
+	}
+
+	subpanic(t, "Pointer key", func() {
+		type pointerKey struct {
+			ID   *int `absorb:"key"`
+			Name string
+		}
+		var view []pointerKey
+		absorb.Absorb(&view, src, absorb.Operations("op"))
+	})
+}
+
+func TestOperationsMap(t *testing.T) {
+	view := map[int]*account{1: {ID: 1, Name: "ann"}}
+	src := changeSource{{"c", 2, "bob"}, {"d", 1, nil}}
+	if err := absorb.Absorb(&view, src, absorb.Operations("op")); err != nil {
+		t.Fatal(err)
+	}
+	if len(view) != 1 || view[2].Name != "bob" {
+		t.Fatalf("Unexpected view %+v", view)
+	}
+
+	names := absorb.Set[string]{"ann": {}, "bob": {}}
+	if err := absorb.Absorb(&names, src, absorb.Operations("op"), absorb.IndexBy("Name")); err != nil {
+		t.Fatal(err)
+	}
+	if names.Len() != 2 || !names.Has("bob") {
+		t.Fatalf("Unexpected set %v", names.Values())
+	}
+
+	subpanic(t, "Unknown operation", func() {
+		absorb.Absorb(&view, changeSource{{"merge", 1, "ann"}}, absorb.Operations("op"))
+	})
+	subpanic(t, "Missing column", func() {
+		absorb.Absorb(&view, testSource{i: 1}, absorb.Operations("op"))
+	})
+	subpanic(t, "No key", func() {
+		var rows []TestDst
+		absorb.Absorb(&rows, src, absorb.Operations("op"))
+	})
+}
@@ -0,0 +1,213 @@
+package absorb
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// An Option configures how an Absorber maps keys to fields and converts values.
+// Options are applied in order, so later options may override earlier ones.
+type Option func(*config)
+
+// ConverterFunc converts a source value into a value assignable to a destination type.
+// It is consulted only when the value cannot be assigned to the destination directly.
+// Returned errors are treated like any other impossible conversion, and cause a panic.
+type ConverterFunc func(value interface{}) (interface{}, error)
+
+type config struct {
+	// registry is a snapshot of the global registry, taken when the Absorber is created.
+	registry *registry
+	// tags are struct tag namespaces preferred over the tag passed to Open, in order.
+	tags []string
+	// normalizers transform each key passed to Open before it is matched to a field.
+	normalizers []func(string) string
+	// converters are keyed by destination type.
+	converters map[reflect.Type]ConverterFunc
+	// overrides are keyed by the destination element type's name.
+	overrides map[string]*Override
+	// copyValues clones byte slices before they are assigned.
+	copyValues bool
+	// checkedNumbers panics on lossy Narrowing numeric conversions.
+	checkedNumbers bool
+	// sendCtx and sendTimeout bound channel sends; Unsent rows are kept as overflow.
+	sendCtx     context.Context
+	sendTimeout time.Duration
+	// sourceID is recorded in each Envelope.
+	sourceID string
+	logger   *logState
+	// quota, if set, is spent for each row according to quotaCost.
+	quota     *Quota
+	quotaCost func(values []interface{}) float64
+	// workers, if greater than 1, build and deliver elements concurrently.
+	workers   int
+	unordered bool
+	// checkpointer records progress, and resume is the source offset of the first row.
+	checkpointer Checkpointer
+	resume       int
+	// keepBuckets limits the number of buckets kept by a Buckets destination.
+	keepBuckets int
+	// groupBy and indexBy name the column that keys a map[K][]T or map[K]T destination.
+	groupBy string
+	indexBy string
+	// finalizer is called with each element once its values are assigned.
+	finalizer FinalizerFunc
+	// pool, if set, supplies elements for channel and callback destinations.
+	pool elementPool
+	// arity determines how rows with the wrong number of values are absorbed.
+	arity ArityPolicy
+	// trackPresence records assigned fields in each Envelope.
+	trackPresence bool
+	// partial updates existing elements, assigning only non-nil values.
+	partial bool
+	// opColumn names the column holding each row's operation.
+	opColumn string
+	// flatten absorbs each value of a keyless row as its own element.
+	flatten bool
+	// bools maps lowercased strings to bool values, replacing defaultBools if set.
+	bools map[string]bool
+	// strict panics on keys that do not map to a field.
+	strict bool
+	// locale, if set, parses strings into numeric fields.
+	locale *numberLocale
+	// trimSpace and emptyAsNil clean string values before they are assigned.
+	trimSpace  bool
+	emptyAsNil bool
+	// matcher matches keys to struct fields, or is nil for MatchDefault.
+	matcher *keyMatcher
+	// duplicates determines how keys passed to Open more than once are absorbed.
+	duplicates DuplicatePolicy
+	// collectErrors skips rows that fail to convert, collecting their errors.
+	collectErrors bool
+	// onError decides how to handle values that cannot be assigned.
+	onError ErrorHandler
+	// onOpen, onRow, and onClose observe the lifecycle of an Absorber.
+	onOpen  func(keys []string)
+	onRow   func(idx int, values []interface{})
+	onClose func(count int, err error)
+}
+
+func newConfig(opts []Option) *config {
+	cfg := &config{registry: loadRegistry()}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// Tags prefers the given struct tag namespaces, in order, over the tag passed to Open.
+// A field is mapped by the first tag in the chain that it declares.
+func Tags(tags ...string) Option {
+	return func(c *config) {
+		c.tags = append(c.tags, tags...)
+	}
+}
+
+// NormalizeKeys transforms every key passed to Open before it is mapped, such as
+// strings.ToLower or a function translating snake_case to CamelCase.
+// Multiple normalizers are applied in the order they were given.
+func NormalizeKeys(fn func(key string) string) Option {
+	return func(c *config) {
+		c.normalizers = append(c.normalizers, fn)
+	}
+}
+
+// Converter uses fn to produce values for destinations of type dstType, such as
+// time.Time fields populated from strings. A later Converter for the same type replaces
+// an earlier one, and takes precedence over converters added with RegisterConverter.
+func Converter(dstType reflect.Type, fn ConverterFunc) Option {
+	return func(c *config) {
+		converters := make(map[reflect.Type]ConverterFunc, len(c.converters)+1)
+		for t, f := range c.converters {
+			converters[t] = f
+		}
+		converters[dstType] = fn
+		c.converters = converters
+	}
+}
+
+// converter returns the converter for dstType, preferring options over the registry.
+func (c *config) converter(dstType reflect.Type) ConverterFunc {
+	if fn := c.converters[dstType]; fn != nil {
+		return fn
+	}
+	return c.registry.converters[dstType]
+}
+
+// CopyOnSend detaches absorbed elements from the source's buffers, by copying every
+// []byte value before it is assigned.
+//
+// By default, absorb hands off elements without copying: a chan *T receives a newly
+// allocated element per row, which the absorber never touches again, and a chan T
+// receives a copy of it. Byte slices are assigned as-is, however, so a source that
+// reuses a buffer between rows would alias the elements it has already sent. Use
+// CopyOnSend when a source's documentation does not promise fresh byte slices.
+func CopyOnSend() Option {
+	return func(c *config) {
+		c.copyValues = true
+	}
+}
+
+// SendContext stops sending to a channel destination once ctx is done. The row being sent,
+// and every row absorbed afterward, are kept in an overflow slice instead of blocking
+// forever on a consumer that has gone away. See Overflow.
+func SendContext(ctx context.Context) Option {
+	return func(c *config) {
+		c.sendCtx = ctx
+	}
+}
+
+// SendTimeout stops sending to a channel destination once any single send blocks for
+// longer than d. Like SendContext, unsent rows are kept in an overflow slice.
+func SendTimeout(d time.Duration) Option {
+	return func(c *config) {
+		c.sendTimeout = d
+	}
+}
+
+// Flatten absorbs each value of a row as its own element, when Open is called without
+// keys and the destination is a slice, array, channel, or callback. This suits sources
+// whose rows are lists of values, such as a single-column UNION read as one row.
+// Each value counts as a row for Resume and checkpoints. Other destinations, and
+// Absorbers opened with keys, are unaffected.
+//
+// Example:
+//
+//	var ids []int64
+//	abs := absorb.New(&ids, absorb.Flatten())
+//	abs.Open("", -1)
+//	abs.Absorb(int64(1), int64(2), int64(3))
+//	abs.Close() // ids is [1 2 3]
+func Flatten() Option {
+	return func(c *config) {
+		c.flatten = true
+	}
+}
+
+// tagChain returns the tag namespaces to consult for a call to Open with tag, which may
+// itself be a comma-separated list of namespaces.
+func (c *config) tagChain(tag string) []string {
+	chain := c.tags[:len(c.tags):len(c.tags)]
+	for _, t := range strings.Split(tag, ",") {
+		if t = strings.TrimSpace(t); t != "" || tag == "" {
+			chain = append(chain, t)
+		}
+	}
+	return chain
+}
+
+// normalize returns keys after applying any normalizers. The given slice is not modified.
+func (c *config) normalize(keys []string) []string {
+	if len(c.normalizers) == 0 {
+		return keys
+	}
+	normalized := make([]string, len(keys))
+	for idx, key := range keys {
+		for _, fn := range c.normalizers {
+			key = fn(key)
+		}
+		normalized[idx] = key
+	}
+	return normalized
+}
@@ -0,0 +1,161 @@
+package absorb_test
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jyopp/absorb"
+)
+
+type taggedDst struct {
+	ID    int    `wh2:"ident" test:"Name"`
+	Label string `test:"label"`
+	Count int
+}
+
+func TestTags(t *testing.T) {
+	var dst taggedDst
+	abs := absorb.New(&dst, absorb.Tags("wh2"))
+	abs.Open("test", 1, "ident", "label")
+	abs.Absorb(5, "five")
+	abs.Close()
+
+	if expect := (taggedDst{ID: 5, Label: "five"}); dst != expect {
+		t.Fatalf("Expected %+v, got %+v", expect, dst)
+	}
+}
+
+func TestNormalizeKeys(t *testing.T) {
+	var dst map[string]interface{}
+	abs := absorb.New(&dst, absorb.NormalizeKeys(strings.TrimSpace), absorb.NormalizeKeys(strings.ToLower))
+	abs.Open("", 1, " ID ", "Label")
+	abs.Absorb(5, "five")
+	abs.Close()
+
+	if expect := map[string]interface{}{"id": 5, "label": "five"}; !reflect.DeepEqual(dst, expect) {
+		t.Fatalf("Expected %+v, got %+v", expect, dst)
+	}
+}
+
+func TestConverter(t *testing.T) {
+	atoi := absorb.Converter(reflect.TypeOf(0), func(value interface{}) (interface{}, error) {
+		return strconv.Atoi(value.(string))
+	})
+
+	var dst struct {
+		Count    int
+		Optional *int
+	}
+	abs := absorb.New(&dst, atoi)
+	abs.Open("", 1, "Count", "Optional")
+	abs.Absorb("12", "34")
+	abs.Close()
+
+	if dst.Count != 12 || dst.Optional == nil || *dst.Optional != 34 {
+		t.Fatalf("Converter was not applied: %+v", dst)
+	}
+
+	subpanic(t, "Converter Error", func() {
+		abs := absorb.New(&dst, atoi)
+		abs.Open("", 1, "Count")
+		abs.Absorb("twelve")
+	})
+}
+
+func TestProfile(t *testing.T) {
+	absorb.RegisterProfile("test-profile", absorb.Tags("wh2"), absorb.NormalizeKeys(strings.ToLower))
+
+	var dst taggedDst
+	abs := absorb.New(&dst, absorb.Profile("test-profile"))
+	abs.Open("test", 1, "IDENT", "Count")
+	abs.Absorb(5, 3)
+	abs.Close()
+
+	if expect := (taggedDst{ID: 5, Count: 3}); dst != expect {
+		t.Fatalf("Expected %+v, got %+v", expect, dst)
+	}
+
+	subpanic(t, "Unregistered Profile", func() {
+		absorb.New(&dst, absorb.Profile("no-such-profile"))
+	})
+}
+
+func TestRegisterConverter(t *testing.T) {
+	intType := reflect.TypeOf(0)
+	defer absorb.RegisterConverter(intType, nil)
+
+	constant := func(n int) absorb.ConverterFunc {
+		return func(interface{}) (interface{}, error) { return n, nil }
+	}
+	absorb.RegisterConverter(intType, constant(1))
+
+	var before, after int
+	inFlight := absorb.New(&before)
+	inFlight.Open("", 1)
+
+	// Absorbers snapshot the registry when created, so this only affects later ones.
+	absorb.RegisterConverter(intType, constant(2))
+	inFlight.Absorb("x")
+	inFlight.Close()
+
+	abs := absorb.New(&after)
+	abs.Open("", 1)
+	abs.Absorb("x")
+	abs.Close()
+
+	if before != 1 || after != 2 {
+		t.Fatalf("Expected converted values 1 and 2, got %d and %d", before, after)
+	}
+
+	// Converter options take precedence over the registry.
+	abs = absorb.New(&after, absorb.Converter(intType, constant(3)))
+	abs.Open("", 1)
+	abs.Absorb("x")
+	abs.Close()
+	if after != 3 {
+		t.Fatalf("Expected converter option to take precedence, got %d", after)
+	}
+}
+
+func TestRegistryConcurrency(t *testing.T) {
+	intType := reflect.TypeOf(0)
+	defer absorb.RegisterConverter(intType, nil)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			absorb.RegisterConverter(intType, func(value interface{}) (interface{}, error) {
+				return strconv.Atoi(value.(string))
+			})
+			absorb.RegisterProfile("test-concurrent", absorb.Tags("wh2"))
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		var dst []int
+		abs := absorb.New(&dst)
+		abs.Open("", 1, "int")
+		abs.Absorb(i)
+		abs.Close()
+	}
+	<-done
+}
+
+func TestTagList(t *testing.T) {
+	type multiTagDst struct {
+		ID    int    `db:"id"`
+		Label string `sqlite:"value" db:"label"`
+	}
+	var dst multiTagDst
+	abs := absorb.New(&dst)
+	abs.Open("sqlite, db", 1, "id", "value")
+	abs.Absorb(5, "five")
+	abs.Close()
+
+	if expect := (multiTagDst{ID: 5, Label: "five"}); dst != expect {
+		t.Fatalf("Expected %+v, got %+v", expect, dst)
+	}
+}
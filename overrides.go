@@ -0,0 +1,151 @@
+package absorb
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// An Override adjusts the mapping for one destination element type at runtime, so that
+// operators can adapt to upstream schema changes without recompiling.
+// Overrides are typically loaded from a file with ParseOverrides, or decoded from YAML
+// by the caller, and applied with WithOverrides.
+type Override struct {
+	// Type is the name of the destination element type, as reported by reflect.Type's
+	// String method, such as "main.PersonRecord".
+	Type string `json:"type" yaml:"type"`
+	// Fields maps source keys to the names of struct fields, taking precedence over tags.
+	Fields map[string]string `json:"fields,omitempty" yaml:"fields,omitempty"`
+	// Defaults are absorbed for keys that are nil in a row, or not passed to Open at all.
+	Defaults map[string]interface{} `json:"defaults,omitempty" yaml:"defaults,omitempty"`
+	// Required keys must be passed to Open, or Open panics with ErrMissingKey.
+	Required []string `json:"required,omitempty" yaml:"required,omitempty"`
+}
+
+// ParseOverrides decodes a JSON array of Overrides.
+//
+// Example file:
+//
+//	[{
+//		"type": "main.PersonRecord",
+//		"fields": {"surname": "Last"},
+//		"defaults": {"Last-Seen": "Unknown"},
+//		"required": ["First"]
+//	}]
+func ParseOverrides(data []byte) ([]Override, error) {
+	var overrides []Override
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return nil, err
+	}
+	return overrides, nil
+}
+
+// WithOverrides applies overrides to destinations of the matching element types.
+// A later Override for the same type replaces an earlier one.
+func WithOverrides(overrides ...Override) Option {
+	return func(c *config) {
+		merged := make(map[string]*Override, len(c.overrides)+len(overrides))
+		for name, o := range c.overrides {
+			merged[name] = o
+		}
+		for idx := range overrides {
+			o := overrides[idx]
+			merged[o.Type] = &o
+		}
+		c.overrides = merged
+	}
+}
+
+// fingerprint identifies the override's field mapping, for use in builder cache keys.
+func (o *Override) fingerprint() string {
+	if o == nil || len(o.Fields) == 0 {
+		return ""
+	}
+	pairs := make([]string, 0, len(o.Fields))
+	for key, field := range o.Fields {
+		pairs = append(pairs, key+"="+field)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// requires reports whether key is one of the override's Required keys.
+func (o *Override) requires(key string) bool {
+	if o == nil {
+		return false
+	}
+	for _, required := range o.Required {
+		if key == required {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRequired panics if any required key is missing from keys.
+func (o *Override) checkRequired(keys []string) {
+	if o == nil {
+		return
+	}
+	for _, required := range o.Required {
+		found := false
+		for _, key := range keys {
+			if key == required {
+				found = true
+				break
+			}
+		}
+		if !found {
+			panic(fmt.Errorf("%w %q for %s", ErrMissingKey, required, o.Type))
+		}
+	}
+}
+
+// defaultsPlan records how to apply an Override's defaults to each absorbed row.
+type defaultsPlan struct {
+	// values holds the default for each key passed to Open, or nil if there is none,
+	// followed by the defaults for keys that were not passed to Open.
+	values []interface{}
+	row    []interface{}
+}
+
+// planDefaults returns nil if the override has no defaults. Otherwise, it returns the
+// plan and the keys to open the builder with, which include any defaulted keys missing
+// from keys.
+func (o *Override) planDefaults(keys []string) (*defaultsPlan, []string) {
+	if o == nil || len(o.Defaults) == 0 {
+		return nil, keys
+	}
+	plan := &defaultsPlan{values: make([]interface{}, len(keys))}
+	present := make(map[string]bool, len(keys))
+	for idx, key := range keys {
+		present[key] = true
+		plan.values[idx] = o.Defaults[key]
+	}
+
+	var missing []string
+	for key := range o.Defaults {
+		if !present[key] {
+			missing = append(missing, key)
+		}
+	}
+	sort.Strings(missing)
+	for _, key := range missing {
+		plan.values = append(plan.values, o.Defaults[key])
+	}
+	plan.row = make([]interface{}, len(plan.values))
+	return plan, append(keys[:len(keys):len(keys)], missing...)
+}
+
+// apply returns values with nil and missing entries replaced by their defaults.
+// The returned slice is reused by subsequent calls.
+func (p *defaultsPlan) apply(values []interface{}) []interface{} {
+	copy(p.row, p.values)
+	for idx, value := range values {
+		if value != nil && idx < len(p.row) {
+			p.row[idx] = value
+		}
+	}
+	return p.row
+}
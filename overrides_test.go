@@ -0,0 +1,53 @@
+package absorb_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jyopp/absorb"
+)
+
+type overrideDst struct {
+	First    string
+	Last     string `test:"surname"`
+	Location string
+	Visits   int
+}
+
+const testOverrides = `[{
+	"type": "absorb_test.overrideDst",
+	"fields": {"family_name": "Last"},
+	"defaults": {"Location": "Unknown", "Visits": 1},
+	"required": ["First"]
+}]`
+
+func TestOverrides(t *testing.T) {
+	overrides, err := absorb.ParseOverrides([]byte(testOverrides))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var dst []overrideDst
+	abs := absorb.New(&dst, absorb.WithOverrides(overrides...))
+	abs.Open("test", 2, "First", "family_name", "Location")
+	abs.Absorb("Jean-Luc", "Picard", "The Bridge")
+	abs.Absorb("Data", nil, nil)
+	abs.Close()
+
+	expect := []overrideDst{
+		{First: "Jean-Luc", Last: "Picard", Location: "The Bridge", Visits: 1},
+		{First: "Data", Location: "Unknown", Visits: 1},
+	}
+	if len(dst) != len(expect) || dst[0] != expect[0] || dst[1] != expect[1] {
+		t.Fatalf("Expected %+v, got %+v", expect, dst)
+	}
+
+	t.Run("Required", func(t *testing.T) {
+		defer func() {
+			if err, _ := recover().(error); !errors.Is(err, absorb.ErrMissingKey) {
+				t.Fatal("Expected ErrMissingKey, got", err)
+			}
+		}()
+		absorb.New(&dst, absorb.WithOverrides(overrides...)).Open("test", 1, "surname")
+	})
+}
@@ -0,0 +1,158 @@
+package absorb
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Parallel builds and delivers elements for channel and callback destinations on a pool
+// of n worker goroutines. For very wide structs, the reflection work per row dominates
+// absorption, and can be spread across cores.
+//
+// If ordered is true, elements are delivered in the order they were absorbed; Otherwise
+// each is delivered as soon as it is built, in any order. Either way, elements are
+// delivered one at a time, so callbacks are never called concurrently, and Close waits
+// until every element has been delivered. Panics from workers are re-raised by the next
+// call to Absorb, or by Close.
+//
+// Other destinations are written in place, and ignore this option.
+func Parallel(n int, ordered bool) Option {
+	return func(c *config) {
+		c.workers = n
+		c.unordered = !ordered
+	}
+}
+
+type parallelJob struct {
+	idx    int
+	values []interface{}
+	// result receives the built element in ordered mode, and is closed without a value
+	// if building it panicked.
+	result chan reflect.Value
+}
+
+type parallelRun struct {
+	a       *absorberImpl
+	jobs    chan parallelJob
+	order   chan chan reflect.Value
+	workers sync.WaitGroup
+	// delivered is closed by the ordered delivery goroutine once it has finished.
+	delivered chan struct{}
+	// deliverMu serializes unordered delivery, which shares the absorber's overflow state.
+	deliverMu sync.Mutex
+
+	panicMu  sync.Mutex
+	panicked interface{}
+	raised   bool
+}
+
+func (a *absorberImpl) startParallel() *parallelRun {
+	n := a.cfg.workers
+	run := &parallelRun{a: a, jobs: make(chan parallelJob, n)}
+	if !a.cfg.unordered {
+		run.order = make(chan chan reflect.Value, 2*n)
+		run.delivered = make(chan struct{})
+		go run.deliverInOrder()
+	}
+	run.workers.Add(n)
+	for i := 0; i < n; i++ {
+		go run.work()
+	}
+	return run
+}
+
+// submit queues a row for building. Values are copied, since callers may reuse them.
+func (r *parallelRun) submit(idx int, values []interface{}) {
+	r.checkPanic()
+	job := parallelJob{idx: idx, values: append([]interface{}(nil), values...)}
+	if r.order != nil {
+		job.result = make(chan reflect.Value, 1)
+		r.order <- job.result
+	}
+	r.jobs <- job
+}
+
+func (r *parallelRun) work() {
+	defer r.workers.Done()
+	for job := range r.jobs {
+		r.run(job)
+	}
+}
+
+func (r *parallelRun) run(job parallelJob) {
+	defer func() {
+		if p := recover(); p != nil {
+			if p != errSkipRow {
+				r.setPanic(p)
+			}
+			if job.result != nil {
+				close(job.result)
+			}
+		}
+	}()
+
+	elem := r.a.build(job.idx, job.values)
+	if job.result != nil {
+		job.result <- elem
+		return
+	}
+	r.deliverMu.Lock()
+	defer r.deliverMu.Unlock()
+	r.a.deliver(job.idx, elem)
+}
+
+func (r *parallelRun) deliverInOrder() {
+	defer close(r.delivered)
+	idx := 0
+	for result := range r.order {
+		if elem, ok := <-result; ok {
+			r.deliver(idx, elem)
+		}
+		idx++
+	}
+}
+
+// deliver delivers elem in order, recording any panic for the producer.
+func (r *parallelRun) deliver(idx int, elem reflect.Value) {
+	defer func() {
+		if p := recover(); p != nil {
+			r.setPanic(p)
+		}
+	}()
+	r.a.deliver(idx, elem)
+	r.a.checkpoint(idx)
+}
+
+// setPanic records the first panic raised by a worker or by delivery.
+func (r *parallelRun) setPanic(p interface{}) {
+	r.panicMu.Lock()
+	defer r.panicMu.Unlock()
+	if r.panicked == nil {
+		r.panicked = p
+	}
+}
+
+// checkPanic re-raises a recorded panic on the calling goroutine, once.
+func (r *parallelRun) checkPanic() {
+	r.panicMu.Lock()
+	p := r.panicked
+	raise := p != nil && !r.raised
+	r.raised = r.raised || raise
+	r.panicMu.Unlock()
+	if raise {
+		panic(p)
+	}
+}
+
+// finish waits for all queued rows to be delivered, then re-raises any worker panic.
+func (r *parallelRun) finish() {
+	close(r.jobs)
+	if r.order != nil {
+		close(r.order)
+	}
+	r.workers.Wait()
+	if r.delivered != nil {
+		<-r.delivered
+	}
+	r.checkPanic()
+}
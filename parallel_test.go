@@ -0,0 +1,61 @@
+package absorb_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/jyopp/absorb"
+)
+
+func TestParallelOrdered(t *testing.T) {
+	ch := make(chan *TestDst, 10)
+	go func() {
+		defer close(ch)
+		if err := absorb.Absorb(ch, testSource{i: 200}, absorb.Parallel(4, true)); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	idx := 0
+	for received := range ch {
+		idx++
+		if received.Actual != idx {
+			t.Fatalf("Expected row %d, got %+v", idx, received)
+		}
+	}
+	if idx != 200 {
+		t.Fatal("Expected 200 rows, got", idx)
+	}
+}
+
+func TestParallelUnordered(t *testing.T) {
+	var mu sync.Mutex
+	seen := make(map[int]bool)
+	err := absorb.Absorb(func(row TestDst) {
+		mu.Lock()
+		defer mu.Unlock()
+		seen[row.Actual] = true
+	}, testSource{i: 200}, absorb.Parallel(4, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Close waits for every row to be delivered.
+	if len(seen) != 200 {
+		t.Fatal("Expected 200 distinct rows, got", len(seen))
+	}
+}
+
+func TestParallelPanic(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("Expected worker panic to be re-raised")
+		}
+	}()
+
+	abs := absorb.New(func(row struct{ Value int }) {}, absorb.Parallel(2, true))
+	abs.Open("", -1, "Value")
+	defer abs.Close()
+	for i := 0; i < 100; i++ {
+		abs.Absorb("not an int")
+	}
+}
@@ -0,0 +1,130 @@
+package absorb
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Partial absorbs rows as updates to the destination's existing contents, for change
+// feeds that carry only the columns that changed. Only fields whose keys are present and
+// whose values are not nil are overwritten; Other fields keep their values.
+//
+// A struct, or a map holding a single row, is updated in place. A slice is kept when the
+// Absorber is opened, and each row updates the element with the same key, or is appended
+// if there is none. Keys are read from the column named by IndexBy, or from the struct
+// field tagged `absorb:"key"`, which must be comparable and not a pointer, since pointers
+// compare by address. Without either, rows update elements by position.
+func Partial() Option {
+	return func(c *config) {
+		c.partial = true
+	}
+}
+
+// upsertPlan matches rows to the elements of a slice destination by key.
+type upsertPlan struct {
+	column  int
+	keyType reflect.Type
+	// index holds the position of each element in the slice, by key.
+	index map[interface{}]int
+	// removed holds the positions of deleted elements, which compact removes.
+	removed map[int]bool
+}
+
+// planUpsert returns a plan for matching rows with the given keys to the elements of a
+// slice destination, or nil if rows update elements by position.
+// Panics if the key column is not mapped to a comparable field, or is a pointer.
+func (a *absorberImpl) planUpsert(keys []string) *upsertPlan {
+	if a.elemType.Kind() != reflect.Struct || a.envelope {
+		return nil
+	}
+	column := -1
+	if a.cfg.indexBy != "" {
+		column = keyColumn(keys, a.cfg.indexBy)
+	} else {
+		for idx, field := range a.builder.Fields {
+			if name, _ := parseTag(field.Tag.Get("absorb")); field.Index != nil && name == "key" {
+				column = idx
+			}
+		}
+	}
+	if column < 0 {
+		return nil
+	}
+	field := a.builder.Fields[column]
+	if field.Index == nil || !field.Type.Comparable() {
+		panic(fmt.Sprintf("cannot update %s by key %q, which is not a comparable field", a.elemType, keys[column]))
+	}
+	switch field.Type.Kind() {
+	case reflect.Ptr, reflect.UnsafePointer, reflect.Chan:
+		panic(fmt.Sprintf("cannot update %s by key %q, which compares by address", a.elemType, keys[column]))
+	}
+
+	plan := &upsertPlan{column: column, keyType: field.Type, index: make(map[interface{}]int, a.setVal.Len()), removed: make(map[int]bool)}
+	for i := 0; i < a.setVal.Len(); i++ {
+		if elem := reflect.Indirect(a.setVal.Index(i)); elem.IsValid() {
+			// Elements without the embedded struct holding the key can't be updated.
+			if key, err := elem.FieldByIndexErr(field.Index); err == nil {
+				plan.index[key.Interface()] = i
+			}
+		}
+	}
+	return plan
+}
+
+// remove deletes the element of the slice into with the same key as values, if any. The
+// element is zeroed, and removed by compact, so that each delete takes constant time.
+func (p *upsertPlan) remove(into reflect.Value, values []interface{}, cfg *config) {
+	key := p.key(values, cfg)
+	i, ok := p.index[key]
+	if !ok {
+		return
+	}
+	delete(p.index, key)
+	into.Index(i).Set(reflect.Zero(into.Type().Elem()))
+	p.removed[i] = true
+}
+
+// compact removes deleted elements from the slice into, keeping the order of the
+// remaining elements. The plan's index is not updated, and must not be used after.
+func (p *upsertPlan) compact(into reflect.Value) {
+	if len(p.removed) == 0 {
+		return
+	}
+	n, kept := into.Len(), 0
+	for i := 0; i < n; i++ {
+		if p.removed[i] {
+			continue
+		}
+		if kept != i {
+			into.Index(kept).Set(into.Index(i))
+		}
+		kept++
+	}
+	zero := reflect.Zero(into.Type().Elem())
+	for i := kept; i < n; i++ {
+		into.Index(i).Set(zero)
+	}
+	into.SetLen(kept)
+	p.removed = nil
+}
+
+// locate returns the element of the slice into with the same key as values, appending a
+// new element if there is none.
+func (p *upsertPlan) locate(into reflect.Value, eType reflect.Type, values []interface{}, cfg *config) reflect.Value {
+	key := p.key(values, cfg)
+	if i, ok := p.index[key]; ok {
+		return into.Index(i)
+	}
+	i := into.Len()
+	p.index[key] = i
+	return getDst(into, eType, i)
+}
+
+// key returns the key of the row values, converted to the key field's type.
+func (p *upsertPlan) key(values []interface{}, cfg *config) interface{} {
+	key := reflect.New(p.keyType).Elem()
+	if val := reflect.ValueOf(values[p.column]); val.IsValid() {
+		_assign(key, val, cfg)
+	}
+	return key.Interface()
+}
@@ -0,0 +1,65 @@
+package absorb_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jyopp/absorb"
+)
+
+func TestPartialStruct(t *testing.T) {
+	dst := TestDst{Name: "kept", Actual: 1, Unused: 7}
+	abs := absorb.New(&dst, absorb.Partial())
+	abs.Open("test", 1, "Name", "Aliased")
+	abs.Absorb(nil, 2)
+	abs.Close()
+	if expect := (TestDst{Name: "kept", Actual: 2, Unused: 7}); dst != expect {
+		t.Fatalf("Expected %+v, got %+v", expect, dst)
+	}
+
+	row := map[string]interface{}{"a": 1, "b": 2}
+	abs = absorb.New(&row, absorb.Partial())
+	abs.Open("", 1, "b", "c")
+	abs.Absorb(3, nil)
+	abs.Close()
+	if expect := map[string]interface{}{"a": 1, "b": 3}; !reflect.DeepEqual(row, expect) {
+		t.Fatalf("Expected %+v, got %+v", expect, row)
+	}
+}
+
+func TestPartialSlice(t *testing.T) {
+	type user struct {
+		ID    int `absorb:"key"`
+		Name  string
+		Email string
+	}
+	dst := []user{{1, "ann", "ann@example.com"}, {2, "bob", "bob@example.com"}}
+	abs := absorb.New(&dst, absorb.Partial())
+	abs.Open("", 2, "ID", "Email")
+	abs.Absorb(int64(2), "robert@example.com")
+	abs.Absorb(3, "cat@example.com")
+	abs.Absorb(1, nil)
+	abs.Close()
+	expect := []user{{1, "ann", "ann@example.com"}, {2, "bob", "robert@example.com"}, {3, "", "cat@example.com"}}
+	if !reflect.DeepEqual(dst, expect) {
+		t.Fatalf("Expected %+v, got %+v", expect, dst)
+	}
+
+	// IndexBy names the key column, and elements may be pointers.
+	ptrs := []*TestDst{{Name: "a", Actual: 1, Unused: 5}}
+	if err := absorb.Absorb(&ptrs, repeatSource{1, 2}, absorb.Partial(), absorb.IndexBy("Aliased")); err != nil {
+		t.Fatal(err)
+	}
+	if len(ptrs) != 2 || *ptrs[0] != (TestDst{"test", 1, 5}) || *ptrs[1] != (TestDst{"test", 2, 0}) {
+		t.Fatalf("Unexpected elements %+v, %+v", ptrs[0], ptrs[1])
+	}
+
+	// Without a key, rows update elements by position.
+	plain := []TestDst{{Unused: 1}, {Unused: 2}}
+	if err := absorb.Absorb(&plain, testSource{i: 3}, absorb.Partial()); err != nil {
+		t.Fatal(err)
+	}
+	if len(plain) != 3 || plain[1] != (TestDst{"test", 2, 2}) {
+		t.Fatalf("Unexpected elements %+v", plain)
+	}
+}
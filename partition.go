@@ -0,0 +1,108 @@
+package absorb
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// AbsorbPartitions absorbs the sources returned by partition for each of partitions
+// partitions into dst, running at most parallelism of them at once. This suits parallel
+// table scans and sharded files, where each partition can be read independently.
+//
+// A channel destination receives the rows of every partition as they are absorbed, and a
+// callback may be called concurrently from several partitions. A slice destination is
+// filled with each partition's rows in partition order, once every partition has
+// succeeded. Panics for other destinations.
+//
+// If a partition returns an error, no more partitions are started, and the first error is
+// returned once the running partitions finish. Panics from partitions are re-raised.
+// A parallelism of zero or less runs every partition at once.
+func AbsorbPartitions(dst interface{}, partitions, parallelism int, partition func(p int) Absorbable, opts ...Option) error {
+	setVal := destination(dst)
+	var parts []reflect.Value
+	switch setVal.Kind() {
+	case reflect.Slice:
+		// Each partition fills its own slice, so that rows are merged in order.
+		parts = make([]reflect.Value, partitions)
+	case reflect.Chan, reflect.Func:
+	default:
+		panic("cannot absorb partitions into " + setVal.Type().String())
+	}
+	if parallelism <= 0 || parallelism > partitions {
+		parallelism = partitions
+	}
+
+	run := partitionRun{next: make(chan int)}
+	run.workers.Add(parallelism)
+	for i := 0; i < parallelism; i++ {
+		go func() {
+			defer run.workers.Done()
+			for p := range run.next {
+				target := dst
+				if parts != nil {
+					parts[p] = reflect.New(setVal.Type())
+					target = parts[p].Interface()
+				}
+				run.absorb(p, target, partition, opts)
+			}
+		}()
+	}
+	for p := 0; p < partitions && !run.failed(); p++ {
+		run.next <- p
+	}
+	close(run.next)
+	run.workers.Wait()
+
+	if run.panicked != nil {
+		panic(run.panicked)
+	}
+	if run.err != nil || parts == nil {
+		return run.err
+	}
+	total := 0
+	for _, part := range parts {
+		total += part.Elem().Len()
+	}
+	merged := reflect.MakeSlice(setVal.Type(), 0, total)
+	for _, part := range parts {
+		merged = reflect.AppendSlice(merged, part.Elem())
+	}
+	setVal.Set(merged)
+	return nil
+}
+
+// partitionRun collects the first error or panic from the partitions of AbsorbPartitions.
+type partitionRun struct {
+	next    chan int
+	workers sync.WaitGroup
+
+	mu       sync.Mutex
+	err      error
+	panicked interface{}
+}
+
+func (r *partitionRun) absorb(p int, dst interface{}, partition func(p int) Absorbable, opts []Option) {
+	defer func() {
+		if v := recover(); v != nil {
+			r.fail(nil, v)
+		}
+	}()
+	if err := Absorb(dst, partition(p), opts...); err != nil {
+		r.fail(fmt.Errorf("absorb: partition %d: %w", p, err), nil)
+	}
+}
+
+func (r *partitionRun) fail(err error, panicked interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err == nil && r.panicked == nil {
+		r.err, r.panicked = err, panicked
+	}
+}
+
+func (r *partitionRun) failed() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err != nil || r.panicked != nil
+}
@@ -0,0 +1,75 @@
+package absorb_test
+
+import (
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/jyopp/absorb"
+)
+
+// shard returns a source emitting rows p*10+1 through p*10+3 for partition p.
+func shard(p int) absorb.Absorbable {
+	return repeatSource{p*10 + 1, p*10 + 2, p*10 + 3}
+}
+
+func TestAbsorbPartitions(t *testing.T) {
+	var dst []TestDst
+	if err := absorb.AbsorbPartitions(&dst, 4, 2, shard); err != nil {
+		t.Fatal(err)
+	}
+	if len(dst) != 12 {
+		t.Fatalf("Expected 12 rows, got %d", len(dst))
+	}
+	for idx, row := range dst {
+		if expect := idx/3*10 + idx%3 + 1; row.Actual != expect {
+			t.Fatalf("Expected row %d to be %d, got %+v", idx, expect, row)
+		}
+	}
+
+	rows := make(chan TestDst, 12)
+	if err := absorb.AbsorbPartitions(rows, 4, 0, shard); err != nil {
+		t.Fatal(err)
+	}
+	close(rows)
+	var received []int
+	for row := range rows {
+		received = append(received, row.Actual)
+	}
+	sort.Ints(received)
+	if len(received) != 12 || received[0] != 1 || received[11] != 33 {
+		t.Fatalf("Unexpected rows %v", received)
+	}
+}
+
+type failingSource struct{ err error }
+
+func (f failingSource) Emit(into absorb.Absorber) error {
+	return f.err
+}
+
+func TestAbsorbPartitionsError(t *testing.T) {
+	errShard := errors.New("shard unavailable")
+	dst := []TestDst{{Name: "unchanged"}}
+	err := absorb.AbsorbPartitions(&dst, 8, 1, func(p int) absorb.Absorbable {
+		if p == 2 {
+			return failingSource{errShard}
+		}
+		if p > 2 {
+			t.Errorf("Partition %d started after an error", p)
+		}
+		return shard(p)
+	})
+	if !errors.Is(err, errShard) {
+		t.Fatal("Expected partition error, got", err)
+	}
+	if len(dst) != 1 || dst[0].Name != "unchanged" {
+		t.Fatalf("Expected destination to be unchanged, got %+v", dst)
+	}
+
+	subpanic(t, "Partition panics", func() {
+		absorb.AbsorbPartitions(&dst, 2, 2, func(p int) absorb.Absorbable {
+			panic("cannot open shard")
+		})
+	})
+}
@@ -0,0 +1,63 @@
+package absorb
+
+// Pipeline composes middleware Absorbers between a source and its destination.
+// Stages are applied to rows in the order they are added.
+//
+// A Pipeline is itself Absorbable, so it may be passed anywhere a source is accepted.
+//
+// Example:
+//
+//	err := absorb.Pipe(rows).
+//		Filter(func(r absorb.Row) bool { return r.Value("active") == true }).
+//		Limit(100).
+//		Into(&users)
+type Pipeline struct {
+	src    Absorbable
+	stages []func(next Absorber) Absorber
+}
+
+// Pipe returns a Pipeline that reads from src.
+func Pipe(src Absorbable) *Pipeline {
+	return &Pipeline{src: src}
+}
+
+// Use adds a stage that wraps the rest of the pipeline in a custom middleware Absorber.
+func (p *Pipeline) Use(wrap func(next Absorber) Absorber) *Pipeline {
+	p.stages = append(p.stages, wrap)
+	return p
+}
+
+// Filter adds a stage that drops the rows for which keep returns false.
+func (p *Pipeline) Filter(keep func(Row) bool) *Pipeline {
+	return p.Use(func(next Absorber) Absorber { return Filter(next, keep) })
+}
+
+// Map adds a stage that replaces each row's values with the values returned by fn.
+func (p *Pipeline) Map(fn func(Row) []interface{}) *Pipeline {
+	return p.Use(func(next Absorber) Absorber { return Map(next, fn) })
+}
+
+// Limit adds a stage that passes at most n rows to later stages.
+func (p *Pipeline) Limit(n int) *Pipeline {
+	return p.Use(func(next Absorber) Absorber { return Limit(next, n) })
+}
+
+// Dedupe adds a stage that drops rows with the same values for keys as an earlier row.
+// See the Dedupe function.
+func (p *Pipeline) Dedupe(limit int, keys ...string) *Pipeline {
+	return p.Use(func(next Absorber) Absorber { return Dedupe(next, limit, keys...) })
+}
+
+// Emit runs the pipeline's source, passing its rows through each stage into the given Absorber.
+func (p *Pipeline) Emit(into Absorber) error {
+	for idx := len(p.stages) - 1; idx >= 0; idx-- {
+		into = p.stages[idx](into)
+	}
+	return p.src.Emit(into)
+}
+
+// Into runs the pipeline, absorbing its rows into dst.
+// Equivalent to absorb.Absorb(dst, p, opts...).
+func (p *Pipeline) Into(dst interface{}, opts ...Option) error {
+	return Absorb(dst, p, opts...)
+}
@@ -0,0 +1,50 @@
+package absorb_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jyopp/absorb"
+)
+
+func TestMiddleware(t *testing.T) {
+	var dst []TestDst
+	abs := absorb.Filter(absorb.New(&dst), func(r absorb.Row) bool {
+		return r.Value("Aliased").(int)%2 == 1
+	})
+	if err := (testSource{i: 5}).Emit(abs); err != nil {
+		t.Fatal(err)
+	}
+	expect := []TestDst{{"test", 1, 0}, {"test", 3, 0}, {"test", 5, 0}}
+	if !reflect.DeepEqual(dst, expect) {
+		t.Fatalf("Expected %+v, got %+v", expect, dst)
+	}
+}
+
+func TestPipeline(t *testing.T) {
+	var dst []TestDst
+	err := absorb.Pipe(testSource{i: 10}).
+		Filter(func(r absorb.Row) bool { return r.Value("Aliased").(int) > 2 }).
+		Map(func(r absorb.Row) []interface{} {
+			r.Values[1] = r.Values[1].(int) * 10
+			return r.Values
+		}).
+		Limit(3).
+		Into(&dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []TestDst{{"test", 30, 0}, {"test", 40, 0}, {"test", 50, 0}}
+	if !reflect.DeepEqual(dst, expect) {
+		t.Fatalf("Expected %+v, got %+v", expect, dst)
+	}
+
+	// Pipelines are sources, so a limit may be applied to an array of exact size.
+	var arr [2]TestDst
+	if err := absorb.Absorb(&arr, absorb.Pipe(testSource{i: 5}).Limit(2)); err != nil {
+		t.Fatal(err)
+	}
+	if arr[1].Actual != 2 {
+		t.Fatalf("Unexpected array %+v", arr)
+	}
+}
@@ -0,0 +1,65 @@
+package absorb
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Pool recycles the elements built for channel and callback destinations, reducing
+// allocation churn for high-throughput streams. The zero value is ready to use.
+//
+// For destinations of pointer type, such as chan *T, each element belongs to the receiver,
+// who should call Release once finished with it. For destinations of value type, such as
+// chan T, elements are copied as they are sent, so the absorber recycles them itself.
+//
+// Example:
+//
+//	var pool absorb.Pool[Event]
+//	ch := make(chan *Event)
+//	wait := absorb.Go(ch, src, absorb.WithPool(&pool))
+//	for event := range ch {
+//		handle(event)
+//		pool.Release(event)
+//	}
+//	err := wait()
+type Pool[T any] struct {
+	pool sync.Pool
+}
+
+// Release zeroes elem and returns it to the pool. The caller must not use elem afterward.
+func (p *Pool[T]) Release(elem *T) {
+	var zero T
+	*elem = zero
+	p.pool.Put(elem)
+}
+
+func (p *Pool[T]) get() reflect.Value {
+	if elem, ok := p.pool.Get().(*T); ok {
+		return reflect.ValueOf(elem)
+	}
+	return reflect.ValueOf(new(T))
+}
+
+func (p *Pool[T]) put(elem reflect.Value) {
+	p.Release(elem.Interface().(*T))
+}
+
+func (p *Pool[T]) elemType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// elementPool is implemented by *Pool[T] for every T.
+type elementPool interface {
+	get() reflect.Value
+	put(elem reflect.Value)
+	elemType() reflect.Type
+}
+
+// WithPool builds the elements of a channel or callback destination in elements taken
+// from pool, whose type must match the destination's element type. Other destinations
+// ignore this option.
+func WithPool[T any](pool *Pool[T]) Option {
+	return func(c *config) {
+		c.pool = pool
+	}
+}
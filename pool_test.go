@@ -0,0 +1,41 @@
+package absorb_test
+
+import (
+	"testing"
+
+	"github.com/jyopp/absorb"
+)
+
+func TestPool(t *testing.T) {
+	var pool absorb.Pool[TestDst]
+
+	// Pointer elements are released by the receiver.
+	ch := make(chan *TestDst)
+	go func() {
+		absorb.Absorb(ch, testSource{i: 100}, absorb.WithPool(&pool))
+		close(ch)
+	}()
+	count := 0
+	for elem := range ch {
+		count++
+		if elem.Actual != count || elem.Unused != 0 {
+			t.Fatalf("Unexpected element %+v", elem)
+		}
+		// Dirty the element, which Release must clear before reuse.
+		elem.Unused = 1
+		pool.Release(elem)
+	}
+
+	// Value elements are recycled by the absorber.
+	var sum int
+	if err := absorb.Absorb(func(d TestDst) { sum += d.Actual }, testSource{i: 10}, absorb.WithPool(&pool)); err != nil {
+		t.Fatal(err)
+	}
+	if sum != 55 {
+		t.Fatalf("Expected sum 55, got %d", sum)
+	}
+
+	subpanic(t, "Mismatched Type", func() {
+		absorb.New(make(chan int), absorb.WithPool(&pool)).Open("", 1)
+	})
+}
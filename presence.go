@@ -0,0 +1,67 @@
+package absorb
+
+import "reflect"
+
+// Presence holds the names of the fields that were assigned from a row: those whose keys
+// were passed to Open, and whose values were not nil. Fields are named as declared in
+// Go, or by key for map elements. PATCH-style consumers can use it to tell an absent
+// column from a column holding the zero value.
+type Presence map[string]bool
+
+// Has reports whether the named field was assigned.
+func (p Presence) Has(field string) bool {
+	return p[field]
+}
+
+// PresenceTracker is implemented by elements that record which of their fields were
+// assigned. SetPresence is called on a pointer to each element, once the row's values have
+// been assigned, and before any finalizer.
+type PresenceTracker interface {
+	SetPresence(p Presence)
+}
+
+var presenceTrackerType = reflect.TypeOf((*PresenceTracker)(nil)).Elem()
+
+// TrackPresence records the fields assigned to each element in the Present field of its
+// Envelope. Elements implementing PresenceTracker are told regardless of this option.
+func TrackPresence() Option {
+	return func(c *config) {
+		c.trackPresence = true
+	}
+}
+
+// presence returns the fields assigned by absorbing values.
+func (a *elementBuilder) presence(values []interface{}) Presence {
+	p := make(Presence, len(values))
+	for idx, value := range values {
+		if value == nil {
+			continue
+		}
+		switch {
+		case a.Type.Kind() == reflect.Map:
+			p[a.Keys[idx]] = true
+		case a.Type.Kind() == reflect.Struct && a.Setters[idx] != nil:
+			p[a.Fields[idx].Name] = true
+		}
+	}
+	return p
+}
+
+// recordPresence passes the fields assigned to target to its PresenceTracker, or to the
+// Envelope env when presence is tracked.
+func (a *absorberImpl) recordPresence(target, env reflect.Value, values []interface{}) {
+	tracker := a.tracksPresence
+	if !tracker && !(a.cfg.trackPresence && env.IsValid()) {
+		return
+	}
+	p := a.builder.presence(values)
+	if tracker {
+		if target.Kind() != reflect.Ptr {
+			target = target.Addr()
+		}
+		target.Interface().(PresenceTracker).SetPresence(p)
+	}
+	if a.cfg.trackPresence && env.IsValid() {
+		env.Interface().(enveloper).setPresence(p)
+	}
+}
@@ -0,0 +1,54 @@
+package absorb_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jyopp/absorb"
+)
+
+type patch struct {
+	Name    string
+	Actual  int `test:"Aliased"`
+	Unused  int
+	present absorb.Presence
+}
+
+func (p *patch) SetPresence(present absorb.Presence) {
+	p.present = present
+}
+
+func TestPresenceTracker(t *testing.T) {
+	var dst []patch
+	abs := absorb.New(&dst)
+	abs.Open("test", 2, "Name", "Aliased", "Extra")
+	abs.Absorb("full", 0, "ignored")
+	abs.Absorb(nil, 2, nil)
+	abs.Close()
+
+	if expect := (absorb.Presence{"Name": true, "Actual": true}); !reflect.DeepEqual(dst[0].present, expect) {
+		t.Fatalf("Expected %v, got %v", expect, dst[0].present)
+	}
+	if p := dst[1].present; p.Has("Name") || !p.Has("Actual") || p.Has("Unused") {
+		t.Fatalf("Unexpected presence %v", p)
+	}
+}
+
+func TestPresenceEnvelope(t *testing.T) {
+	var dst []absorb.Envelope[map[string]int]
+	abs := absorb.New(&dst, absorb.TrackPresence())
+	abs.Open("", 1, "a", "b")
+	abs.Absorb(1, nil)
+	abs.Close()
+	if expect := (absorb.Presence{"a": true}); !reflect.DeepEqual(dst[0].Present, expect) {
+		t.Fatalf("Expected %v, got %v", expect, dst[0].Present)
+	}
+
+	var untracked []absorb.Envelope[TestDst]
+	if err := absorb.Absorb(&untracked, testSource{i: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if untracked[0].Present != nil {
+		t.Fatalf("Expected no presence without TrackPresence, got %v", untracked[0].Present)
+	}
+}
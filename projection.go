@@ -0,0 +1,188 @@
+package absorb
+
+import (
+	"reflect"
+	"sort"
+)
+
+// ProjectableAbsorber is implemented by Absorbers that can tell a source which keys their
+// destination maps, so that the source can skip columns that would be discarded, such as
+// blobs that are expensive to read. Absorbers created with New implement it.
+type ProjectableAbsorber interface {
+	Absorber
+	// WantsKeys returns the keys that the destination maps when opened with tag, as its
+	// fields declare them, or nil if it absorbs every key, as map elements do.
+	WantsKeys(tag string) []string
+}
+
+// WantedKeys returns the keys, of those a source could emit, that into would absorb when
+// opened with tag, in their original order. If into is not a ProjectableAbsorber, every
+// key is returned.
+//
+// For Absorbers created with New, keys are matched exactly as Open would match them,
+// including normalizers, overrides, and case-insensitive matching.
+//
+// Example:
+//
+//	keys := absorb.WantedKeys(into, "db", columnNames)
+//	rows, err := db.Query("SELECT " + strings.Join(keys, ", ") + " FROM users")
+func WantedKeys(into Absorber, tag string, keys []string) []string {
+	switch into := into.(type) {
+	case *absorberImpl:
+		return into.wantedKeys(tag, keys)
+	case ProjectableAbsorber:
+		wanted := into.WantsKeys(tag)
+		if wanted == nil {
+			return keys
+		}
+		set := make(map[string]bool, len(wanted))
+		for _, key := range wanted {
+			set[key] = true
+		}
+		var kept []string
+		for _, key := range keys {
+			if set[key] {
+				kept = append(kept, key)
+			}
+		}
+		return kept
+	}
+	return keys
+}
+
+func (a *absorberImpl) WantsKeys(tag string) []string {
+	rowType := a.rowType()
+	if rowType.Kind() != reflect.Struct {
+		return nil
+	}
+	tags := a.cfg.tagChain(tag)
+	var keys []string
+	seen := make(map[string]bool)
+	add := func(key string) {
+		if key != "" && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	for _, key := range []string{a.cfg.groupBy, a.cfg.indexBy, a.cfg.opColumn} {
+		add(key)
+	}
+	if override := a.cfg.overrides[rowType.String()]; override != nil {
+		remapped := make([]string, 0, len(override.Fields))
+		for key := range override.Fields {
+			remapped = append(remapped, key)
+		}
+		sort.Strings(remapped)
+		for _, key := range append(remapped, override.Required...) {
+			add(key)
+		}
+	}
+	for _, key := range declaredBuilder(rowType, tags).Keys {
+		add(key)
+	}
+	return keys
+}
+
+// KeysFor returns the keys that elements of type t, a struct or a pointer to one, map when
+// absorbed from a source using tag: the tag of each field in that namespace, or its name
+// if it has none. Fields promoted from embedded structs are included, and fields with an
+// explicitly empty tag are not. Returns nil if t is not a struct.
+//
+// Tag may be a comma-separated list of namespaces, as passed to Open. Options such as
+// Tags, NormalizeKeys, and WithOverrides are not taken into account.
+func KeysFor(t reflect.Type, tag string) []string {
+	builder := introspect(t, tag)
+	if builder == nil {
+		return nil
+	}
+	return append([]string(nil), builder.Keys...)
+}
+
+// FieldsFor returns the field that each key returned by KeysFor maps to, in the same order.
+// The Index of each field may be passed to reflect.Value's FieldByIndex.
+func FieldsFor(t reflect.Type, tag string) []reflect.StructField {
+	builder := introspect(t, tag)
+	if builder == nil {
+		return nil
+	}
+	return append([]reflect.StructField(nil), builder.Fields...)
+}
+
+// introspect returns the builder for every key declared by t with tag, or nil if t is not
+// a struct.
+func introspect(t reflect.Type, tag string) *elementBuilder {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	return declaredBuilder(t, new(config).tagChain(tag))
+}
+
+// declaredBuilder returns the cached builder for every key that the fields of structType
+// declare with tags, in order of depth, then declaration. A key declared by several fields
+// is mapped as Open would map it.
+func declaredBuilder(structType reflect.Type, tags []string) *elementBuilder {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, field := range structFields(structType, tags) {
+		key := field.Name
+		if tagVal, ok := lookupTag(field, tags); ok {
+			key, _ = parseTag(tagVal)
+		}
+		if key != "" && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	return getBuilder(loadRegistry(), structType, tags, keys, nil, nil)
+}
+
+// wantedKeys returns the keys that map to a field of the element built for each row, or
+// all keys if elements are not structs.
+func (a *absorberImpl) wantedKeys(tag string, keys []string) []string {
+	rowType := a.rowType()
+	if rowType.Kind() != reflect.Struct {
+		return keys
+	}
+	normalized := a.cfg.normalize(keys)
+	override := a.cfg.overrides[rowType.String()]
+	builder := getBuilder(a.cfg.registry, rowType, a.cfg.tagChain(tag), normalized, override, a.cfg.matcher)
+	var kept []string
+	for idx, key := range keys {
+		if builder.mapped(idx, a.cfg) || override.requires(normalized[idx]) {
+			kept = append(kept, key)
+		}
+	}
+	return kept
+}
+
+// rowType returns the type of the element built from each row when the Absorber is opened
+// with keys, after pointers and Envelopes are unwrapped.
+func (a *absorberImpl) rowType() reflect.Type {
+	t := a.setVal.Type()
+	switch t.Kind() {
+	case reflect.Array, reflect.Slice, reflect.Chan:
+		t = t.Elem()
+	case reflect.Func:
+		t = t.In(0)
+	case reflect.Map:
+		// Maps of structs are keyed by a column or field, and may group them in slices.
+		// Sets are built from each row into their keys.
+		if elem := t.Elem(); elem.Kind() == reflect.Struct && elem.NumField() == 0 {
+			t = t.Key()
+		} else if t = elem; t.Kind() == reflect.Slice {
+			t = t.Elem()
+		}
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if reflect.PtrTo(t).Implements(enveloperType) {
+		if t = t.Field(0).Type; t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+	}
+	return t
+}
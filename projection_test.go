@@ -0,0 +1,69 @@
+package absorb_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jyopp/absorb"
+)
+
+type projectedDst struct {
+	ID      int
+	Name    string `db:"full_name"`
+	Skipped string `db:""`
+	EmbeddedBase
+}
+
+func TestWantedKeys(t *testing.T) {
+	var dst []projectedDst
+	abs := absorb.New(&dst)
+
+	wants := abs.(absorb.ProjectableAbsorber).WantsKeys("db")
+	expect := []string{"ID", "full_name", "EmbeddedBase", "Created"}
+	if !reflect.DeepEqual(wants, expect) {
+		t.Fatalf("Expected %v, got %v", expect, wants)
+	}
+
+	// Columns are matched as Open matches them, including by lowercased names.
+	columns := []string{"id", "full_name", "Skipped", "blob", "Created"}
+	kept := absorb.WantedKeys(abs, "db", columns)
+	if expect := []string{"id", "full_name", "Created"}; !reflect.DeepEqual(kept, expect) {
+		t.Fatalf("Expected %v, got %v", expect, kept)
+	}
+
+	// Grouping columns are wanted along with mapped fields.
+	var groups map[string][]projectedDst
+	kept = absorb.WantedKeys(absorb.New(&groups, absorb.GroupBy("team")), "db", []string{"team", "blob", "ID"})
+	if expect := []string{"team", "ID"}; !reflect.DeepEqual(kept, expect) {
+		t.Fatalf("Expected %v, got %v", expect, kept)
+	}
+
+	// Map elements, and other Absorbers, absorb every key.
+	var rows []map[string]interface{}
+	if kept := absorb.WantedKeys(absorb.New(&rows), "db", columns); !reflect.DeepEqual(kept, columns) {
+		t.Fatalf("Expected every key, got %v", kept)
+	}
+	if kept := absorb.WantedKeys(absorb.Limit(abs, 1), "db", columns); !reflect.DeepEqual(kept, columns) {
+		t.Fatalf("Expected every key, got %v", kept)
+	}
+}
+
+func TestKeysFor(t *testing.T) {
+	typ := reflect.TypeOf(&projectedDst{})
+	keys := absorb.KeysFor(typ, "db")
+	if expect := []string{"ID", "full_name", "EmbeddedBase", "Created"}; !reflect.DeepEqual(keys, expect) {
+		t.Fatalf("Expected %v, got %v", expect, keys)
+	}
+	fields := absorb.FieldsFor(typ, "db")
+	if len(fields) != len(keys) || fields[1].Name != "Name" || fields[3].Name != "Created" || len(fields[3].Index) != 2 {
+		t.Fatalf("Unexpected fields %+v", fields)
+	}
+
+	// Without a tag in the chain, fields are keyed by name.
+	if keys := absorb.KeysFor(typ, "csv, json"); keys[1] != "Name" || len(keys) != 5 {
+		t.Fatalf("Unexpected keys %v", keys)
+	}
+	if keys := absorb.KeysFor(reflect.TypeOf(0), "db"); keys != nil {
+		t.Fatalf("Expected no keys for int, got %v", keys)
+	}
+}
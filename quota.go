@@ -0,0 +1,89 @@
+package absorb
+
+import (
+	"sync"
+	"time"
+)
+
+// Quota is a token bucket that can be shared by any number of Absorbers, across
+// goroutines, to cap the aggregate cost of concurrent absorptions in a service.
+// Each absorbed row spends tokens according to a cost function; When the bucket is
+// empty, Absorb blocks until enough tokens have been refilled.
+type Quota struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewQuota creates a Quota that refills at rate tokens per second, and holds at most
+// burst tokens. The bucket starts full.
+func NewQuota(rate, burst float64) *Quota {
+	if rate <= 0 {
+		panic("absorb.NewQuota requires a positive rate")
+	}
+	return &Quota{rate: rate, burst: burst, tokens: burst, last: time.Now()}
+}
+
+// Wait spends n tokens, blocking until the bucket has refilled enough to cover them.
+// A cost larger than the burst size is allowed, and waits for the full deficit.
+func (q *Quota) Wait(n float64) {
+	q.mu.Lock()
+	now := time.Now()
+	q.tokens += now.Sub(q.last).Seconds() * q.rate
+	if q.tokens > q.burst {
+		q.tokens = q.burst
+	}
+	q.last = now
+	// Reserve the tokens immediately, so concurrent waiters queue up fairly behind us.
+	q.tokens -= n
+	deficit := -q.tokens
+	q.mu.Unlock()
+
+	if deficit > 0 {
+		time.Sleep(time.Duration(deficit / q.rate * float64(time.Second)))
+	}
+}
+
+// RowCost estimates the memory cost of a row in bytes: 16 per value, plus the length of
+// string and []byte values. It can be passed to WithQuota to cap ingest bandwidth.
+func RowCost(values []interface{}) float64 {
+	cost := 16 * len(values)
+	for _, value := range values {
+		switch v := value.(type) {
+		case string:
+			cost += len(v)
+		case []byte:
+			cost += len(v)
+		}
+	}
+	return float64(cost)
+}
+
+// WithQuota spends tokens from q for each absorbed row, blocking Absorb while q is
+// exhausted. The cost of each row is computed by cost, or is 1 if cost is nil.
+//
+// Example:
+//
+//	// Shared by every ingest pipeline in the service: at most 8MiB/s, in 1MiB bursts.
+//	var ingestQuota = absorb.NewQuota(8<<20, 1<<20)
+//	err := absorb.Absorb(&rows, src, absorb.WithQuota(ingestQuota, absorb.RowCost))
+func WithQuota(q *Quota, cost func(values []interface{}) float64) Option {
+	return func(c *config) {
+		c.quota = q
+		c.quotaCost = cost
+	}
+}
+
+// spendQuota waits for the cost of values, if the Absorber has a Quota.
+func (c *config) spendQuota(values []interface{}) {
+	if c.quota == nil {
+		return
+	}
+	cost := 1.0
+	if c.quotaCost != nil {
+		cost = c.quotaCost(values)
+	}
+	c.quota.Wait(cost)
+}
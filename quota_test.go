@@ -0,0 +1,39 @@
+package absorb_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jyopp/absorb"
+)
+
+func TestQuota(t *testing.T) {
+	// 10 rows are free, then 1000 rows per second are shared by both absorbers.
+	quota := absorb.NewQuota(1000, 10)
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var dst []TestDst
+			if err := absorb.Absorb(&dst, testSource{i: 30}, absorb.WithQuota(quota, nil)); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// 60 rows, less the burst of 10, at 1 row per millisecond.
+	if elapsed := time.Since(start); elapsed < 45*time.Millisecond {
+		t.Fatalf("Quota did not limit absorption; took %v", elapsed)
+	}
+}
+
+func TestRowCost(t *testing.T) {
+	if cost := absorb.RowCost([]interface{}{"four", []byte{1, 2}, 3}); cost != 54 {
+		t.Fatal("Expected cost 54, got", cost)
+	}
+}
@@ -0,0 +1,103 @@
+package absorb
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// registry holds converters and profiles shared by every Absorber.
+// It is copy-on-write: each Absorber takes a snapshot when it is created, so updates
+// never race with in-flight absorptions, and never change a mapping mid-run.
+type registry struct {
+	converters map[reflect.Type]ConverterFunc
+	profiles   map[string][]Option
+	codecs     map[string]Codec
+	// codecVersion counts updates to codecs, which change the mappings of cached builders.
+	codecVersion int
+}
+
+var (
+	// registryMu serializes updates; Readers load the current snapshot without locking.
+	registryMu      sync.Mutex
+	currentRegistry atomic.Value
+)
+
+func init() {
+	currentRegistry.Store(&registry{codecs: builtinCodecs})
+}
+
+func loadRegistry() *registry {
+	return currentRegistry.Load().(*registry)
+}
+
+// updateRegistry applies fn to a copy of the current registry, then publishes the copy.
+func updateRegistry(fn func(r *registry)) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	current := loadRegistry()
+	next := &registry{
+		converters: make(map[reflect.Type]ConverterFunc, len(current.converters)+1),
+		profiles:   make(map[string][]Option, len(current.profiles)+1),
+		codecs:     make(map[string]Codec, len(current.codecs)+1),
+
+		codecVersion: current.codecVersion,
+	}
+	for t, fn := range current.converters {
+		next.converters[t] = fn
+	}
+	for name, opts := range current.profiles {
+		next.profiles[name] = opts
+	}
+	for name, codec := range current.codecs {
+		next.codecs[name] = codec
+	}
+	fn(next)
+	currentRegistry.Store(next)
+}
+
+// RegisterConverter sets the default converter for destinations of type dstType, used by
+// every Absorber that does not set its own with the Converter option. Passing a nil fn
+// removes the converter.
+//
+// The registry may be updated at any time, such as when a new date format appears in a
+// feed. Absorbers created before an update are unaffected by it.
+func RegisterConverter(dstType reflect.Type, fn ConverterFunc) {
+	updateRegistry(func(r *registry) {
+		if fn == nil {
+			delete(r.converters, dstType)
+		} else {
+			r.converters[dstType] = fn
+		}
+	})
+}
+
+// RegisterProfile stores a named set of options, so that mapping conventions can be
+// maintained centrally and selected with Profile. Registering a name again replaces it,
+// without affecting Absorbers that were already created with the previous profile.
+//
+// Example:
+//
+//	absorb.RegisterProfile("warehouse-v2", absorb.Tags("wh2", "db"), absorb.NormalizeKeys(strings.ToLower))
+//	abs := absorb.New(&rows, absorb.Profile("warehouse-v2"))
+func RegisterProfile(name string, opts ...Option) {
+	opts = append([]Option(nil), opts...)
+	updateRegistry(func(r *registry) {
+		r.profiles[name] = opts
+	})
+}
+
+// Profile applies the options registered under name.
+// Panics when the Absorber is created if no such profile has been registered.
+func Profile(name string) Option {
+	return func(c *config) {
+		opts, ok := c.registry.profiles[name]
+		if !ok {
+			panic("cannot absorb with unregistered profile " + name)
+		}
+		for _, opt := range opts {
+			opt(c)
+		}
+	}
+}
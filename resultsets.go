@@ -0,0 +1,98 @@
+package absorb
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ResultSets returns an Absorber that fills each field of the struct pointed to by dst
+// with one result set, for sources such as stored procedures and multi-statement scripts
+// that open and close the Absorber once per result set:
+//
+//	var results struct {
+//		Users  []User
+//		Orders []Order `absorb:"orders"`
+//	}
+//	err := src.Emit(absorb.ResultSets(&results))
+//
+// Each Open targets the next exported field in declaration order, skipping fields tagged
+// `absorb:"-"`, unless Select names another. Fields are destinations like any other, and
+// are absorbed with the given options. Open panics once every field has been filled.
+func ResultSets(dst interface{}, opts ...Option) *ResultSetAbsorber {
+	setVal := destination(dst)
+	if setVal.Kind() != reflect.Struct {
+		panic("cannot absorb result sets into " + setVal.Type().String())
+	}
+	r := &ResultSetAbsorber{typ: setVal.Type(), selected: -1}
+	for i := 0; i < setVal.NumField(); i++ {
+		field := setVal.Type().Field(i)
+		name, _ := parseTag(field.Tag.Get("absorb"))
+		if field.PkgPath != "" || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		r.names = append(r.names, name)
+		r.fields = append(r.fields, New(setVal.Field(i).Addr().Interface(), opts...))
+	}
+	return r
+}
+
+// ResultSetAbsorber absorbs consecutive result sets into the fields of a struct.
+// See ResultSets.
+type ResultSetAbsorber struct {
+	typ    reflect.Type
+	names  []string
+	fields []Absorber
+	// next is the index of the field targeted by the next Open, unless one is selected.
+	next     int
+	selected int
+	current  Absorber
+	open     bool
+}
+
+// Select targets the field named name with the next result set. Fields are named by their
+// `absorb` tag, or else by the field's name, matched case-insensitively.
+// Panics if there is no such field.
+func (r *ResultSetAbsorber) Select(name string) {
+	for idx, n := range r.names {
+		if strings.EqualFold(n, name) {
+			r.selected = idx
+			return
+		}
+	}
+	panic("cannot select result set " + name + " of " + r.typ.String())
+}
+
+func (r *ResultSetAbsorber) Open(tag string, count int, keys ...string) {
+	if r.open {
+		panic(ErrAlreadyOpen)
+	}
+	idx := r.next
+	if r.selected >= 0 {
+		idx, r.selected = r.selected, -1
+	}
+	if idx >= len(r.fields) {
+		panic(fmt.Sprintf("cannot absorb more than %d result sets into %s", len(r.fields), r.typ))
+	}
+	r.current, r.next = r.fields[idx], idx+1
+	r.current.Open(tag, count, keys...)
+	r.open = true
+}
+
+func (r *ResultSetAbsorber) Absorb(values ...interface{}) {
+	if r.current == nil {
+		panic(ErrNotOpen)
+	}
+	r.current.Absorb(values...)
+}
+
+func (r *ResultSetAbsorber) Close() {
+	if r.current == nil {
+		panic(ErrNotOpen)
+	}
+	r.open = false
+	r.current.Close()
+}
@@ -0,0 +1,59 @@
+package absorb_test
+
+import (
+	"testing"
+
+	"github.com/jyopp/absorb"
+)
+
+// multiSource emits each of its sources as a separate result set.
+type multiSource []absorb.Absorbable
+
+func (ms multiSource) Emit(into absorb.Absorber) error {
+	for _, src := range ms {
+		if err := src.Emit(into); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestResultSets(t *testing.T) {
+	var results struct {
+		Tests   []TestDst
+		Skipped []int    `absorb:"-"`
+		Players []player `absorb:"players"`
+		Count   int
+	}
+	src := multiSource{testSource{i: 2}, playerSource{{"ann", "red"}}, keysSource{"Count"}}
+	if err := src.Emit(absorb.ResultSets(&results)); err != nil {
+		t.Fatal(err)
+	}
+	if len(results.Tests) != 2 || results.Tests[1].Actual != 2 {
+		t.Fatalf("Unexpected first result set %+v", results.Tests)
+	}
+	if len(results.Players) != 1 || results.Players[0].Team != "red" {
+		t.Fatalf("Unexpected second result set %+v", results.Players)
+	}
+
+	abs := absorb.ResultSets(&results)
+	abs.Select("Players")
+	if err := (playerSource{{"bob", "blue"}, {"cat", "red"}}).Emit(abs); err != nil {
+		t.Fatal(err)
+	}
+	if len(results.Players) != 2 || results.Players[0].Name != "bob" {
+		t.Fatalf("Unexpected selected result set %+v", results.Players)
+	}
+
+	subpanic(t, "Too many result sets", func() {
+		src := multiSource{testSource{i: 1}, testSource{i: 1}, testSource{i: 1}, testSource{i: 1}}
+		src.Emit(absorb.ResultSets(&results))
+	})
+	subpanic(t, "Unknown result set", func() {
+		absorb.ResultSets(&results).Select("Skipped")
+	})
+	subpanic(t, "Not a struct", func() {
+		var dst []TestDst
+		absorb.ResultSets(&dst)
+	})
+}
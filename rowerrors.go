@@ -0,0 +1,102 @@
+package absorb
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// CollectErrors absorbs rows on a best-effort basis: a row that fails to convert or to
+// validate, or has the wrong number of values, is skipped and its error collected, and
+// the remaining rows are absorbed as usual. Absorb returns the collected errors as
+// RowErrors, unless the source itself fails. Absorbers created with New report them with
+// Errors.
+//
+// A skipped row leaves no element in a slice, array, map, or channel destination, but a
+// row updating an existing element with Partial or Operations may be partly applied.
+// Errors raised by Parallel workers are not collected.
+func CollectErrors() Option {
+	return func(c *config) {
+		c.collectErrors = true
+	}
+}
+
+// ErrorCollector is implemented by Absorbers created with New.
+type ErrorCollector interface {
+	Absorber
+	// Errors returns the errors of the rows skipped since Open, as RowErrors, or nil if
+	// none were. Rows are only skipped when the CollectErrors option is set.
+	Errors() error
+}
+
+// RowErrors holds the errors of rows skipped with CollectErrors, in order. Each is a
+// *ConversionError, a *ValidationError, or an error wrapping ErrArity, identifying its row.
+type RowErrors []error
+
+func (e RowErrors) Error() string {
+	msgs := make([]string, len(e))
+	for idx, err := range e {
+		msgs[idx] = err.Error()
+	}
+	return "absorb: " + strconv.Itoa(len(e)) + " rows failed:\n" + strings.Join(msgs, "\n")
+}
+
+// Is reports whether any row's error matches target, for errors.Is.
+func (e RowErrors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first row error that matches target, for errors.As.
+func (e RowErrors) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *absorberImpl) Errors() error {
+	if len(a.errs) == 0 {
+		return nil
+	}
+	return RowErrors(append([]error(nil), a.errs...))
+}
+
+// recoverRow recovers the row at idx if it was skipped by an ErrorHandler, or collects
+// its error if it failed in a way CollectErrors allows, and discards any element it left
+// behind. Other panics are re-raised.
+// It must be deferred directly, to recover the panic.
+func (a *absorberImpl) recoverRow(idx int) {
+	p := recover()
+	if p == nil {
+		return
+	}
+	if p != errSkipRow {
+		err, _ := p.(error)
+		var convErr *ConversionError
+		var validErr *ValidationError
+		if !a.cfg.collectErrors || err == nil || !errors.As(err, &convErr) && !errors.As(err, &validErr) && !errors.Is(err, ErrArity) {
+			panic(p)
+		}
+		a.errs = append(a.errs, err)
+	}
+	a.idx = idx + 1
+
+	// Later rows fill the position this row would have taken.
+	if kind := a.setVal.Kind(); (kind == reflect.Slice || kind == reflect.Array) && a.keyed == nil && a.upsert == nil {
+		if pos := idx - a.dropped; pos < a.setVal.Len() && a.elemType != byteType {
+			a.setVal.Index(pos).Set(reflect.Zero(a.setVal.Type().Elem()))
+			if kind == reflect.Slice {
+				a.setVal.SetLen(pos)
+			}
+		}
+		a.dropped++
+	}
+}
@@ -0,0 +1,60 @@
+package absorb_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jyopp/absorb"
+)
+
+// messySource emits rows of name and count, some of which cannot be converted.
+type messySource [][]interface{}
+
+func (ms messySource) Emit(into absorb.Absorber) error {
+	into.Open("test", len(ms), "Name", "Aliased")
+	defer into.Close()
+	for _, row := range ms {
+		into.Absorb(row...)
+	}
+	return nil
+}
+
+func TestCollectErrors(t *testing.T) {
+	src := messySource{
+		{"a", 1},
+		{"b", "two"},
+		{"c"},
+		{"d", 4},
+		{"e", []int{5}},
+	}
+	var dst []TestDst
+	err := absorb.Absorb(&dst, src, absorb.CollectErrors())
+	var rowErrs absorb.RowErrors
+	if !errors.As(err, &rowErrs) || len(rowErrs) != 3 {
+		t.Fatalf("Expected 3 row errors, got %v", err)
+	}
+	var convErr *absorb.ConversionError
+	if !errors.As(rowErrs[0], &convErr) || convErr.Row != 2 || convErr.Key != "Aliased" {
+		t.Fatalf("Expected a conversion error in row 2, got %v", rowErrs[0])
+	}
+	if !errors.Is(err, absorb.ErrArity) || !errors.As(rowErrs[2], &convErr) || convErr.Row != 5 {
+		t.Fatalf("Unexpected errors %v", err)
+	}
+	if len(dst) != 2 || dst[0].Name != "a" || dst[1].Name != "d" || dst[1].Actual != 4 {
+		t.Fatalf("Expected the valid rows, got %+v", dst)
+	}
+
+	// Channels receive only the valid rows.
+	ch := make(chan TestDst, len(src))
+	abs := absorb.New(ch, absorb.CollectErrors()).(absorb.ErrorCollector)
+	src.Emit(abs)
+	close(ch)
+	if len(ch) != 2 || abs.Errors() == nil {
+		t.Fatalf("Expected 2 rows and errors, got %d rows and %v", len(ch), abs.Errors())
+	}
+
+	// Without the option, the first failure still panics.
+	subpanic(t, "Abort", func() {
+		absorb.Absorb(&dst, src)
+	})
+}
@@ -0,0 +1,84 @@
+package absorb
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// Sample returns an Absorber that keeps a uniformly random sample of k rows from a source
+// of any length, and absorbs them into dst, as New(dst, opts...) would, when it is closed.
+// Rows are kept in their source order. Every row is kept if there are k or fewer. This is
+// useful for previewing and profiling sources too large to absorb whole.
+//
+// Sampled rows are copied, including byte slices, so sources may reuse their buffers.
+//
+// Example:
+//
+//	var preview []Event
+//	err := src.Emit(absorb.Sample(&preview, 100))
+func Sample(dst interface{}, k int, opts ...Option) Absorber {
+	if k < 0 {
+		panic("cannot sample a negative number of rows")
+	}
+	return &sampleAbsorber{next: New(dst, opts...), k: k}
+}
+
+// sampledRow is a row kept by reservoir sampling, with its position in the source.
+type sampledRow struct {
+	idx    int
+	values []interface{}
+}
+
+type sampleAbsorber struct {
+	next Absorber
+	k    int
+	tag  string
+	keys []string
+	seen int
+	rows []sampledRow
+	open bool
+}
+
+func (s *sampleAbsorber) Open(tag string, count int, keys ...string) {
+	if s.open {
+		panic(ErrAlreadyOpen)
+	}
+	s.tag, s.keys = tag, keys
+	s.seen, s.rows, s.open = 0, nil, true
+}
+
+func (s *sampleAbsorber) Absorb(values ...interface{}) {
+	if !s.open {
+		panic(ErrNotOpen)
+	}
+	idx := s.seen
+	s.seen++
+	// Algorithm R: the nth row replaces a kept row with probability k/n.
+	if len(s.rows) < s.k {
+		s.rows = append(s.rows, sampledRow{idx, copyRow(values)})
+	} else if j := rand.Intn(s.seen); j < s.k {
+		s.rows[j] = sampledRow{idx, copyRow(values)}
+	}
+}
+
+// copyRow returns a copy of values that shares no buffers with the source.
+func copyRow(values []interface{}) []interface{} {
+	return copyBytes(append([]interface{}(nil), values...))
+}
+
+// Close absorbs the sampled rows into the destination.
+func (s *sampleAbsorber) Close() {
+	if !s.open {
+		panic(ErrNotOpen)
+	}
+	s.open = false
+	sort.Slice(s.rows, func(i, j int) bool {
+		return s.rows[i].idx < s.rows[j].idx
+	})
+	s.next.Open(s.tag, len(s.rows), s.keys...)
+	defer s.next.Close()
+	for _, row := range s.rows {
+		s.next.Absorb(row.values...)
+	}
+	s.rows = nil
+}
@@ -0,0 +1,41 @@
+package absorb_test
+
+import (
+	"testing"
+
+	"github.com/jyopp/absorb"
+)
+
+func TestSample(t *testing.T) {
+	var all []TestDst
+	if err := absorb.Absorb(&all, testSource{i: 50}); err != nil {
+		t.Fatal(err)
+	}
+
+	counts := make(map[int]int)
+	for trial := 0; trial < 200; trial++ {
+		var sample []TestDst
+		if err := (testSource{i: 50}).Emit(absorb.Sample(&sample, 5)); err != nil {
+			t.Fatal(err)
+		}
+		if len(sample) != 5 {
+			t.Fatalf("Expected 5 rows, got %d", len(sample))
+		}
+		for idx, dst := range sample {
+			// Rows are kept in source order.
+			if idx > 0 && dst.Actual <= sample[idx-1].Actual {
+				t.Fatalf("Rows out of order: %+v", sample)
+			}
+			counts[dst.Actual]++
+		}
+	}
+	// Each row is expected 20 times; Rows at either end must not be favored.
+	if first, last := counts[all[0].Actual], counts[all[49].Actual]; first < 4 || first > 50 || last < 4 || last > 50 {
+		t.Fatalf("Biased sample: first row kept %d times, last %d", first, last)
+	}
+
+	var few []TestDst
+	if err := (testSource{i: 3}).Emit(absorb.Sample(&few, 5)); err != nil || len(few) != 3 {
+		t.Fatalf("Expected every row, got %d (err %v)", len(few), err)
+	}
+}
@@ -0,0 +1,82 @@
+package absorb
+
+import "database/sql"
+
+// RowScanner adapts an Absorber to a rows.Scan loop, so that code reading *sql.Rows can
+// use absorb's mapping and conversions before adopting the Absorbable interface. Each
+// column is scanned into a target that holds its value, which is then absorbed as part
+// of the row.
+//
+// Example:
+//
+//	columns, _ := rows.Columns()
+//	scanner := absorb.NewRowScanner(&users, "db", columns)
+//	for rows.Next() {
+//		if err := rows.Scan(scanner.Targets()...); err != nil {
+//			return err
+//		}
+//		if err := scanner.Absorb(); err != nil {
+//			return err
+//		}
+//	}
+//	if err := scanner.Close(); err != nil {
+//		return err
+//	}
+//	return rows.Err()
+type RowScanner struct {
+	abs     Absorber
+	values  []interface{}
+	targets []interface{}
+}
+
+// NewRowScanner opens an Absorber for dst with the given struct tag and column names, as
+// returned by rows.Columns. Like New, panics if dst is not a valid destination.
+func NewRowScanner(dst interface{}, tag string, columns []string, opts ...Option) *RowScanner {
+	s := &RowScanner{
+		abs:     New(dst, opts...),
+		values:  make([]interface{}, len(columns)),
+		targets: make([]interface{}, len(columns)),
+	}
+	for idx := range columns {
+		s.targets[idx] = scanTarget{&s.values[idx]}
+	}
+	s.abs.Open(tag, -1, columns...)
+	return s
+}
+
+// Targets returns the scan targets to pass to rows.Scan, one per column. The same
+// targets are returned by every call.
+func (s *RowScanner) Targets() []interface{} {
+	return s.targets
+}
+
+// Absorb absorbs the values most recently scanned into the targets as one row, and
+// returns any error raised while converting them, such as a *ConversionError.
+func (s *RowScanner) Absorb() (err error) {
+	defer recoverError(&err)
+	s.abs.Absorb(s.values...)
+	return nil
+}
+
+// Close closes the Absorber, assigning its destination, and returns any error raised.
+func (s *RowScanner) Close() (err error) {
+	defer recoverError(&err)
+	s.abs.Close()
+	return nil
+}
+
+// scanTarget stores the value scanned from one column. Byte slices are copied, because
+// drivers may reuse their buffers for the next row.
+type scanTarget struct {
+	value *interface{}
+}
+
+var _ sql.Scanner = scanTarget{}
+
+func (t scanTarget) Scan(src interface{}) error {
+	if b, ok := src.([]byte); ok {
+		src = append([]byte(nil), b...)
+	}
+	*t.value = src
+	return nil
+}
@@ -0,0 +1,54 @@
+package absorb_test
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jyopp/absorb"
+)
+
+func TestRowScanner(t *testing.T) {
+	type User struct {
+		ID      int64     `db:"id"`
+		Name    string    `db:"name"`
+		Created time.Time `db:"created_at"`
+		Active  bool      `db:"active"`
+	}
+	created := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	// Rows as a driver would return them, reusing one buffer.
+	buf := []byte("ann")
+	rows := [][]interface{}{
+		{int64(1), buf, created, true},
+		{int64(2), buf, created, "0"},
+	}
+
+	var users []User
+	scanner := absorb.NewRowScanner(&users, "db", []string{"id", "name", "created_at", "active"})
+	for _, row := range rows {
+		// rows.Scan calls each target's Scan method with the column's value.
+		for idx, target := range scanner.Targets() {
+			if err := target.(sql.Scanner).Scan(row[idx]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := scanner.Absorb(); err != nil {
+			t.Fatal(err)
+		}
+		copy(buf, "bob")
+	}
+	if err := scanner.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 2 || users[0].Name != "ann" || users[1].Name != "bob" || !users[0].Active || users[1].Active || !users[1].Created.Equal(created) {
+		t.Fatalf("Unexpected users %+v", users)
+	}
+
+	scanner = absorb.NewRowScanner(&users, "db", []string{"id"})
+	scanner.Targets()[0].(sql.Scanner).Scan("one")
+	var convErr *absorb.ConversionError
+	if err := scanner.Absorb(); !errors.As(err, &convErr) || convErr.Key != "id" {
+		t.Fatalf("Expected a conversion error, got %v", err)
+	}
+}
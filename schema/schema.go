@@ -0,0 +1,243 @@
+// Package schema infers the columns of an absorb.Absorbable by sampling its rows, and
+// generates Go struct definitions to absorb them into. Use it to bootstrap types for
+// unexplored CSV files and query results.
+package schema
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/jyopp/absorb"
+)
+
+// Column describes a column inferred from a source.
+type Column struct {
+	// Key is the column's key, as passed to Open.
+	Key string
+	// Type is the type of the column's values, or nil if every sampled value was nil.
+	Type reflect.Type
+	// Nullable is true if any sampled value was nil.
+	Nullable bool
+}
+
+// Schema describes the rows emitted by a source.
+type Schema struct {
+	// Tag is the tag namespace passed to Open, used to tag the fields of generated structs.
+	Tag     string
+	Columns []Column
+	// Rows is the number of rows sampled.
+	Rows int
+}
+
+// Infer samples up to limit rows from src, or every row if limit is not positive, and
+// returns the columns it emits. Rows past the limit are still emitted, and ignored.
+//
+// When a column's values have different numeric types, the column has the widest of them,
+// and mixed integers and floats are float64. Other mixed types become interface{}.
+func Infer(src absorb.Absorbable, limit int) (*Schema, error) {
+	s := &sampler{limit: limit}
+	if err := src.Emit(s); err != nil {
+		return nil, err
+	}
+	if s.schema == nil {
+		return nil, fmt.Errorf("schema: source did not open an absorber")
+	}
+	return s.schema, nil
+}
+
+// sampler is an Absorber that infers a Schema from the rows it absorbs.
+type sampler struct {
+	limit  int
+	schema *Schema
+}
+
+func (s *sampler) Open(tag string, count int, keys ...string) {
+	if s.schema != nil {
+		return
+	}
+	s.schema = &Schema{Tag: strings.TrimSpace(strings.Split(tag, ",")[0])}
+	for _, key := range keys {
+		s.schema.Columns = append(s.schema.Columns, Column{Key: key})
+	}
+}
+
+func (s *sampler) Absorb(values ...interface{}) {
+	if s.limit > 0 && s.schema.Rows >= s.limit {
+		return
+	}
+	s.schema.Rows++
+	for idx, value := range values {
+		if idx >= len(s.schema.Columns) {
+			break
+		}
+		col := &s.schema.Columns[idx]
+		if value == nil {
+			col.Nullable = true
+		} else {
+			col.Type = widen(col.Type, reflect.TypeOf(value))
+		}
+	}
+}
+
+func (s *sampler) Close() {}
+
+var (
+	int64Type     = reflect.TypeOf(int64(0))
+	uint64Type    = reflect.TypeOf(uint64(0))
+	float64Type   = reflect.TypeOf(float64(0))
+	interfaceType = reflect.TypeOf((*interface{})(nil)).Elem()
+)
+
+// widen returns a type able to hold the values of both a and b.
+func widen(a, b reflect.Type) reflect.Type {
+	if a == nil || a == b {
+		return b
+	}
+	classA, classB := numericClass(a), numericClass(b)
+	switch {
+	case classA == 0 || classB == 0:
+		return interfaceType
+	case classA == 'f' || classB == 'f':
+		return float64Type
+	case classA == 'u' && classB == 'u':
+		return uint64Type
+	}
+	return int64Type
+}
+
+func numericClass(t reflect.Type) byte {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return 'i'
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return 'u'
+	case reflect.Float32, reflect.Float64:
+		return 'f'
+	}
+	return 0
+}
+
+// WriteStruct writes a gofmt-formatted definition of a struct type with the given name,
+// with a field for each column. Fields are tagged with the schema's Tag, when it is set;
+// Otherwise fields are only matched to columns by name.
+//
+// Nullable columns have pointer fields, except for slice and interface types.
+func (s *Schema) WriteStruct(w io.Writer, name string) error {
+	var buf bytes.Buffer
+	imports := map[string]bool{}
+	fmt.Fprintf(&buf, "type %s struct {\n", name)
+	used := map[string]int{}
+	for _, col := range s.Columns {
+		field := fieldName(col.Key)
+		if n := used[field]; n > 0 {
+			used[field]++
+			field = fmt.Sprintf("%s%d", field, n+1)
+		} else {
+			used[field] = 1
+		}
+
+		typ := col.Type
+		if typ == nil {
+			typ = interfaceType
+		}
+		typeName := typeString(typ, imports)
+		if col.Nullable && typ.Kind() != reflect.Slice && typ.Kind() != reflect.Interface {
+			typeName = "*" + typeName
+		}
+		fmt.Fprintf(&buf, "\t%s %s", field, typeName)
+		if s.Tag != "" {
+			fmt.Fprintf(&buf, " `%s:%q`", s.Tag, col.Key)
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString("}\n")
+
+	var header bytes.Buffer
+	if len(imports) > 0 {
+		paths := make([]string, 0, len(imports))
+		for path := range imports {
+			paths = append(paths, fmt.Sprintf("%q", path))
+		}
+		sort.Strings(paths)
+		if len(paths) == 1 {
+			fmt.Fprintf(&header, "import %s\n\n", paths[0])
+		} else {
+			fmt.Fprintf(&header, "import (\n%s\n)\n\n", strings.Join(paths, "\n"))
+		}
+	}
+	header.Write(buf.Bytes())
+	src, err := format.Source(header.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+// Struct returns the definition written by WriteStruct.
+func (s *Schema) Struct(name string) string {
+	var buf strings.Builder
+	if err := s.WriteStruct(&buf, name); err != nil {
+		// Generated code is always valid, unless name is not an identifier.
+		panic("cannot generate struct " + name + ": " + err.Error())
+	}
+	return buf.String()
+}
+
+// typeString returns the name of typ as written in Go source, recording its imports.
+func typeString(typ reflect.Type, imports map[string]bool) string {
+	switch typ.Kind() {
+	case reflect.Ptr:
+		return "*" + typeString(typ.Elem(), imports)
+	case reflect.Slice:
+		if typ.Name() == "" {
+			return "[]" + typeString(typ.Elem(), imports)
+		}
+	case reflect.Map:
+		if typ.Name() == "" {
+			return "map[" + typeString(typ.Key(), imports) + "]" + typeString(typ.Elem(), imports)
+		}
+	case reflect.Interface:
+		if typ.Name() == "" {
+			return "interface{}"
+		}
+	}
+	if typ == reflect.TypeOf(byte(0)) {
+		return "byte"
+	}
+	if path := typ.PkgPath(); path != "" {
+		imports[path] = true
+	}
+	return typ.String()
+}
+
+// fieldName converts a column key, such as "last_seen" or "Last-Seen", to an exported Go
+// identifier, such as "LastSeen".
+func fieldName(key string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range key {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if b.Len() == 0 && unicode.IsDigit(r) {
+			b.WriteString("X")
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	if b.Len() == 0 {
+		return "Field"
+	}
+	return b.String()
+}
@@ -0,0 +1,52 @@
+package schema_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jyopp/absorb"
+	"github.com/jyopp/absorb/schema"
+)
+
+type sampleSource [][]interface{}
+
+func (ss sampleSource) Emit(into absorb.Absorber) error {
+	into.Open("db,sqlite", len(ss), "id", "last_seen", "Score", "blob", "2fa", "note", "id")
+	defer into.Close()
+	for _, row := range ss {
+		into.Absorb(row...)
+	}
+	return nil
+}
+
+func TestInfer(t *testing.T) {
+	when := time.Now()
+	src := sampleSource{
+		{int32(1), when, 1, []byte("x"), true, nil, "a"},
+		{int64(2), nil, 2.5, nil, false, nil, 1},
+		{"ignored", "ignored", "ignored", "ignored", "ignored", "ignored", "ignored"},
+	}
+	s, err := schema.Infer(src, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Rows != 2 || s.Tag != "db" {
+		t.Fatalf("Unexpected schema %+v", s)
+	}
+
+	expect := `import "time"
+
+type Row struct {
+	Id       int64       ` + "`db:\"id\"`" + `
+	LastSeen *time.Time  ` + "`db:\"last_seen\"`" + `
+	Score    float64     ` + "`db:\"Score\"`" + `
+	Blob     []byte      ` + "`db:\"blob\"`" + `
+	X2fa     bool        ` + "`db:\"2fa\"`" + `
+	Note     interface{} ` + "`db:\"note\"`" + `
+	Id2      interface{} ` + "`db:\"id\"`" + `
+}
+`
+	if got := s.Struct("Row"); got != expect {
+		t.Fatalf("Expected:\n%s\nGot:\n%s", expect, got)
+	}
+}
@@ -0,0 +1,67 @@
+package absorb
+
+// Set is a set of comparable values. Like a map[T]struct{}, a *Set[T] is a destination
+// which collects the distinct values of a single column, or of the column named by
+// IndexBy. When rows have several columns, T may be a struct built from each row.
+//
+// Example:
+//
+//	var hosts absorb.Set[netip.Addr]
+//	err := absorb.Absorb(&hosts, rows, absorb.IndexBy("remote_addr"))
+type Set[T comparable] map[T]struct{}
+
+// Add adds values to the set, allocating it if needed.
+func (s *Set[T]) Add(values ...T) {
+	if *s == nil {
+		*s = make(Set[T], len(values))
+	}
+	for _, v := range values {
+		(*s)[v] = struct{}{}
+	}
+}
+
+// Has reports whether v is in the set.
+func (s Set[T]) Has(v T) bool {
+	_, ok := s[v]
+	return ok
+}
+
+// Remove removes v from the set.
+func (s Set[T]) Remove(v T) {
+	delete(s, v)
+}
+
+// Len returns the number of values in the set.
+func (s Set[T]) Len() int {
+	return len(s)
+}
+
+// Values returns the set's values, in no particular order.
+func (s Set[T]) Values() []T {
+	values := make([]T, 0, len(s))
+	for v := range s {
+		values = append(values, v)
+	}
+	return values
+}
+
+// Distinct absorbs the distinct values of the column named key from src, converted to T.
+// Values are returned in no particular order.
+func Distinct[T comparable](src Absorbable, key string, opts ...Option) ([]T, error) {
+	set, err := distinct[T](src, key, opts)
+	return set.Values(), err
+}
+
+// Cardinality counts the distinct values of the column named key from src, as converted
+// to T.
+func Cardinality[T comparable](src Absorbable, key string, opts ...Option) (int, error) {
+	set, err := distinct[T](src, key, opts)
+	return set.Len(), err
+}
+
+func distinct[T comparable](src Absorbable, key string, opts []Option) (Set[T], error) {
+	var set Set[T]
+	opts = append(opts[:len(opts):len(opts)], IndexBy(key))
+	err := Absorb(&set, src, opts...)
+	return set, err
+}
@@ -0,0 +1,72 @@
+package absorb_test
+
+import (
+	"net/netip"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/jyopp/absorb"
+)
+
+func TestSets(t *testing.T) {
+	src := repeatSource{3, 1, 3, 2}
+
+	var ids map[int]struct{}
+	if err := absorb.Absorb(&ids, src, absorb.IndexBy("Aliased")); err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 3 {
+		t.Fatalf("Unexpected set %v", ids)
+	}
+
+	// Whole rows build struct members.
+	var rows absorb.Set[TestDst]
+	if err := absorb.Absorb(&rows, src); err != nil {
+		t.Fatal(err)
+	}
+	if rows.Len() != 3 || !rows.Has(TestDst{Name: "test", Actual: 2}) {
+		t.Fatalf("Unexpected set %v", rows)
+	}
+
+	var addrs absorb.Set[netip.Addr]
+	if err := absorb.Absorb(&addrs, addrSource{"10.0.0.1", "10.0.0.2", "10.0.0.1"}); err != nil {
+		t.Fatal(err)
+	}
+	if addrs.Len() != 2 || !addrs.Has(netip.MustParseAddr("10.0.0.2")) {
+		t.Fatalf("Unexpected set %v", addrs)
+	}
+	addrs.Remove(netip.MustParseAddr("10.0.0.2"))
+	addrs.Add(netip.MustParseAddr("::1"))
+	if vals := addrs.Values(); len(vals) != 2 {
+		t.Fatalf("Unexpected values %v", vals)
+	}
+}
+
+// addrSource emits a single column of parsed addresses.
+type addrSource []string
+
+func (as addrSource) Emit(into absorb.Absorber) error {
+	into.Open("test", len(as), "addr")
+	defer into.Close()
+	for _, a := range as {
+		into.Absorb(netip.MustParseAddr(a))
+	}
+	return nil
+}
+
+func TestDistinct(t *testing.T) {
+	src := repeatSource{3, 1, 3, 2, 1}
+	values, err := absorb.Distinct[int64](src, "Aliased")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	if !reflect.DeepEqual(values, []int64{1, 2, 3}) {
+		t.Fatalf("Unexpected values %v", values)
+	}
+
+	if n, err := absorb.Cardinality[string](src, "Name"); err != nil || n != 1 {
+		t.Fatalf("Expected cardinality 1, got %d (%v)", n, err)
+	}
+}
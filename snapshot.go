@@ -0,0 +1,77 @@
+package absorb
+
+import "sync"
+
+// ChangeTokenSource is implemented by Absorbables that can cheaply report a token for the
+// current version of their data, such as an ETag, max(updated_at), or a binlog position.
+// The token must change whenever the emitted data would.
+type ChangeTokenSource interface {
+	Absorbable
+	ChangeToken() (string, error)
+}
+
+// Snapshot holds a destination value absorbed from a source, along with the source's
+// change token at the time. Refresh re-absorbs the source only when its token changes,
+// which makes a Snapshot a minimal cache over an expensive query.
+//
+// A Snapshot is safe for concurrent use; Readers see either the old or the new value,
+// never a partially absorbed one.
+//
+// Example:
+//
+//	var users absorb.Snapshot[[]User]
+//	changed, err := users.Refresh(usersQuery)
+//	current, _ := users.Get()
+type Snapshot[T any] struct {
+	mu    sync.RWMutex
+	value T
+	token string
+	valid bool
+	// refreshMu serializes refreshes, without blocking readers.
+	refreshMu sync.Mutex
+}
+
+// Get returns the absorbed value and the token it was absorbed with.
+func (s *Snapshot[T]) Get() (T, string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.value, s.token
+}
+
+// Refresh absorbs src into a new value of type T, unless src is a ChangeTokenSource whose
+// token matches the snapshot's. Sources without a change token are always re-absorbed.
+// Returns true if the value was replaced. If absorbing fails, the previous value is kept.
+func (s *Snapshot[T]) Refresh(src Absorbable, opts ...Option) (bool, error) {
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+
+	var token string
+	if tokens, ok := src.(ChangeTokenSource); ok {
+		var err error
+		if token, err = tokens.ChangeToken(); err != nil {
+			return false, err
+		}
+		s.mu.RLock()
+		unchanged := s.valid && token == s.token
+		s.mu.RUnlock()
+		if unchanged {
+			return false, nil
+		}
+	}
+
+	var value T
+	if err := Absorb(&value, src, opts...); err != nil {
+		return false, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.value, s.token, s.valid = value, token, true
+	return true, nil
+}
+
+// Invalidate forgets the snapshot's token, so that the next Refresh re-absorbs the source.
+func (s *Snapshot[T]) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.valid = false
+}
@@ -0,0 +1,66 @@
+package absorb_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jyopp/absorb"
+)
+
+// versionedSource emits testSource rows, and reports its version as a change token.
+type versionedSource struct {
+	testSource
+	version string
+	emits   *int
+}
+
+func (vs versionedSource) ChangeToken() (string, error) {
+	if vs.version == "" {
+		return "", errors.New("unavailable")
+	}
+	return vs.version, nil
+}
+
+func (vs versionedSource) Emit(into absorb.Absorber) error {
+	*vs.emits++
+	return vs.testSource.Emit(into)
+}
+
+func TestSnapshot(t *testing.T) {
+	var emits int
+	var snap absorb.Snapshot[[]TestDst]
+
+	for _, step := range []struct {
+		src     versionedSource
+		changed bool
+		rows    int
+	}{
+		{versionedSource{testSource{2}, "v1", &emits}, true, 2},
+		{versionedSource{testSource{3}, "v1", &emits}, false, 2},
+		{versionedSource{testSource{3}, "v2", &emits}, true, 3},
+	} {
+		changed, err := snap.Refresh(step.src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows, token := snap.Get()
+		if changed != step.changed || len(rows) != step.rows || token != step.src.version {
+			t.Fatalf("Refresh to %s: changed %v with %d rows, token %q", step.src.version, changed, len(rows), token)
+		}
+	}
+	if emits != 2 {
+		t.Fatalf("Expected 2 emits, got %d", emits)
+	}
+
+	if _, err := snap.Refresh(versionedSource{testSource{1}, "", &emits}); err == nil {
+		t.Fatal("Expected change token error")
+	}
+	if rows, _ := snap.Get(); len(rows) != 3 {
+		t.Fatal("Failed refresh replaced the snapshot")
+	}
+
+	snap.Invalidate()
+	if changed, _ := snap.Refresh(versionedSource{testSource{1}, "v2", &emits}); !changed {
+		t.Fatal("Expected refresh after Invalidate")
+	}
+}
@@ -0,0 +1,159 @@
+package absorb
+
+import (
+	"container/list"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// SoftCache is a best-effort destination for large reference datasets: It holds elements
+// by key, up to a total cost, evicting the least recently used elements to make room.
+// Absorbing more data than fits drops elements instead of exhausting memory.
+//
+// A SoftCache is safe for concurrent use.
+//
+// Example:
+//
+//	cache := absorb.NewSoftCache[int, Product](64<<20, func(p Product) int64 {
+//		return int64(len(p.Description)) + 64
+//	})
+//	err := src.Emit(cache.Absorber("id"))
+//	product, ok := cache.Get(42)
+type SoftCache[K comparable, V any] struct {
+	mu     sync.Mutex
+	budget int64
+	cost   func(V) int64
+	used   int64
+	items  map[K]*list.Element
+	// recent orders entries from most to least recently used.
+	recent *list.List
+}
+
+type softEntry[K comparable, V any] struct {
+	key   K
+	value V
+	cost  int64
+}
+
+// NewSoftCache creates a cache holding elements with a total cost of at most budget.
+// If cost is nil, every element costs 1, so budget is a maximum number of elements.
+func NewSoftCache[K comparable, V any](budget int64, cost func(V) int64) *SoftCache[K, V] {
+	if cost == nil {
+		cost = func(V) int64 { return 1 }
+	}
+	return &SoftCache[K, V]{budget: budget, cost: cost, items: make(map[K]*list.Element), recent: list.New()}
+}
+
+// Get returns the element for key, if it has not been evicted.
+func (c *SoftCache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.items[key]; ok {
+		c.recent.MoveToFront(elem)
+		return elem.Value.(*softEntry[K, V]).value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Put stores value under key, evicting older elements as needed. An element costing more
+// than the whole budget is not stored.
+func (c *SoftCache[K, V]) Put(key K, value V) {
+	cost := c.cost(value)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.items[key]; ok {
+		c.remove(elem)
+	}
+	if cost > c.budget {
+		return
+	}
+	c.items[key] = c.recent.PushFront(&softEntry[K, V]{key: key, value: value, cost: cost})
+	c.used += cost
+	c.trim(c.budget)
+}
+
+// Trim evicts the least recently used elements until their total cost is at most budget,
+// such as when the process is under memory pressure. The cache's budget is unchanged.
+func (c *SoftCache[K, V]) Trim(budget int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.trim(budget)
+}
+
+// Len returns the number of elements in the cache.
+func (c *SoftCache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.items)
+}
+
+// Cost returns the total cost of the elements in the cache.
+func (c *SoftCache[K, V]) Cost() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.used
+}
+
+func (c *SoftCache[K, V]) trim(budget int64) {
+	for c.used > budget {
+		c.remove(c.recent.Back())
+	}
+}
+
+func (c *SoftCache[K, V]) remove(elem *list.Element) {
+	entry := c.recent.Remove(elem).(*softEntry[K, V])
+	delete(c.items, entry.key)
+	c.used -= entry.cost
+}
+
+// Absorber returns an Absorber that builds an element of type V from each row, and puts it
+// in the cache under the value of the column named key, converted to K. Unlike other
+// destinations, the cache is not cleared when the Absorber is opened.
+// Panics on Open if the key is missing.
+func (c *SoftCache[K, V]) Absorber(key string, opts ...Option) Absorber {
+	s := &softCacheAbsorber[K, V]{cache: c, key: key}
+	opts = append(opts[:len(opts):len(opts)], func(c *config) { c.workers = 0 })
+	s.inner = New(s.put, opts...).(*absorberImpl)
+	return s
+}
+
+type softCacheAbsorber[K comparable, V any] struct {
+	cache   *SoftCache[K, V]
+	key     string
+	inner   *absorberImpl
+	keyIdx  int
+	current K
+}
+
+func (s *softCacheAbsorber[K, V]) Open(tag string, count int, keys ...string) {
+	s.keyIdx = -1
+	for idx, k := range keys {
+		if k == s.key {
+			s.keyIdx = idx
+		}
+	}
+	if s.keyIdx < 0 {
+		panic(fmt.Errorf("%w: cannot cache by %q", ErrMissingKey, s.key))
+	}
+	s.inner.Open(tag, count, keys...)
+}
+
+func (s *softCacheAbsorber[K, V]) Absorb(values ...interface{}) {
+	s.inner.checkOpen()
+	key := reflect.ValueOf(&s.current).Elem()
+	key.Set(reflect.Zero(key.Type()))
+	if val := reflect.ValueOf(values[s.keyIdx]); val.IsValid() {
+		_assign(key, val, s.inner.cfg)
+	}
+	s.inner.Absorb(values...)
+}
+
+func (s *softCacheAbsorber[K, V]) put(elem V) {
+	s.cache.Put(s.current, elem)
+}
+
+func (s *softCacheAbsorber[K, V]) Close() {
+	s.inner.Close()
+}
@@ -0,0 +1,44 @@
+package absorb_test
+
+import (
+	"testing"
+
+	"github.com/jyopp/absorb"
+)
+
+func TestSoftCache(t *testing.T) {
+	// Each element costs its Aliased value.
+	cache := absorb.NewSoftCache[int64, TestDst](10, func(d TestDst) int64 { return int64(d.Actual) })
+	if err := (repeatSource{1, 2, 3, 4}).Emit(cache.Absorber("Aliased")); err != nil {
+		t.Fatal(err)
+	}
+	if cache.Len() != 4 || cache.Cost() != 10 {
+		t.Fatalf("Expected 4 elements costing 10, got %d costing %d", cache.Len(), cache.Cost())
+	}
+
+	// Touch 1, so that 2 is the least recently used, then overflow the budget.
+	if d, ok := cache.Get(1); !ok || d.Actual != 1 {
+		t.Fatalf("Unexpected element %+v", d)
+	}
+	cache.Put(5, TestDst{Actual: 2})
+	if _, ok := cache.Get(2); ok {
+		t.Fatal("Expected least recently used element to be evicted")
+	}
+	if _, ok := cache.Get(1); !ok || cache.Cost() != 10 {
+		t.Fatalf("Unexpected eviction, cost %d", cache.Cost())
+	}
+
+	cache.Put(6, TestDst{Actual: 11})
+	if _, ok := cache.Get(6); ok {
+		t.Fatal("Expected element over budget to be dropped")
+	}
+
+	cache.Trim(0)
+	if cache.Len() != 0 {
+		t.Fatalf("Expected empty cache, got %d elements", cache.Len())
+	}
+
+	subpanic(t, "Missing Key", func() {
+		(repeatSource{1}).Emit(cache.Absorber("ID"))
+	})
+}
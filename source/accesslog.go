@@ -0,0 +1,128 @@
+package source
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jyopp/absorb"
+)
+
+// accessLogKeys are the keys emitted by AccessLogSource, in order.
+var accessLogKeys = []string{
+	"remote_addr", "remote_user", "timestamp", "request", "method", "path", "protocol",
+	"status", "bytes", "referer", "user_agent",
+}
+
+// accessLogLine matches the Common and Combined Log Formats. The referer and user agent
+// are optional, so that both formats are accepted.
+var accessLogLine = regexp.MustCompile(`^(\S+) \S+ (\S+) \[([^\]]+)\] "((?:[^"\\]|\\.)*)" (\d{3}) (\d+|-)(?: "((?:[^"\\]|\\.)*)" "((?:[^"\\]|\\.)*)")?`)
+
+// accessLogTime is the layout of timestamps in access logs, such as
+// "10/Oct/2000:13:55:36 -0700".
+const accessLogTime = "02/Jan/2006:15:04:05 -0700"
+
+// AccessLogSource emits a row for each line of an HTTP access log in the Common or
+// Combined Log Format, as written by Apache's httpd and by nginx's default "combined"
+// format. Keys are in tag namespace "accesslog":
+//
+//	remote_addr  string     client address
+//	remote_user  string     authenticated user
+//	timestamp    time.Time  time the request was received
+//	request      string     request line, such as "GET /index.html HTTP/1.1"
+//	method       string     request method, path, and protocol, split from the
+//	path         string     request line; nil if it is malformed
+//	protocol     string
+//	status       int64      response status code
+//	bytes        int64      size of the response body
+//	referer      string     Referer header (Combined format only)
+//	user_agent   string     User-Agent header (Combined format only)
+//
+// Fields logged as "-" are nil. Blank lines are skipped, and other lines that do not
+// match the format stop the source with an error.
+type AccessLogSource struct {
+	r io.Reader
+}
+
+// AccessLog creates a source that reads access log lines from r.
+func AccessLog(r io.Reader) *AccessLogSource {
+	return &AccessLogSource{r: r}
+}
+
+// Emit implements absorb.Absorbable
+func (s *AccessLogSource) Emit(into absorb.Absorber) error {
+	into.Open("accesslog", -1, accessLogKeys...)
+	defer into.Close()
+
+	rowData := make([]interface{}, len(accessLogKeys))
+	scanner := bufio.NewScanner(s.r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if err := parseAccessLog(line, rowData); err != nil {
+			return fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		into.Absorb(rowData...)
+	}
+	return scanner.Err()
+}
+
+// parseAccessLog parses line into rowData, in the order of accessLogKeys.
+func parseAccessLog(line string, rowData []interface{}) error {
+	m := accessLogLine.FindStringSubmatch(line)
+	if m == nil {
+		return fmt.Errorf("not an access log line: %q", line)
+	}
+	timestamp, err := time.Parse(accessLogTime, m[3])
+	if err != nil {
+		return err
+	}
+	status, _ := strconv.ParseInt(m[5], 10, 64)
+
+	rowData[0] = logField(m[1])
+	rowData[1] = logField(m[2])
+	rowData[2] = timestamp
+	request := unescapeLogField(m[4])
+	rowData[3] = logField(request)
+	rowData[4], rowData[5], rowData[6] = nil, nil, nil
+	if parts := strings.Fields(request); len(parts) == 3 {
+		rowData[4], rowData[5], rowData[6] = parts[0], parts[1], parts[2]
+	}
+	rowData[7] = status
+	rowData[8] = nil
+	if m[6] != "-" {
+		rowData[8], _ = strconv.ParseInt(m[6], 10, 64)
+	}
+	rowData[9] = logField(unescapeLogField(m[7]))
+	rowData[10] = logField(unescapeLogField(m[8]))
+	return nil
+}
+
+// logField returns text, or nil if it is empty or "-".
+func logField(text string) interface{} {
+	if text == "" || text == "-" {
+		return nil
+	}
+	return text
+}
+
+// unescapeLogField returns a quoted field with its backslash escapes removed.
+func unescapeLogField(text string) string {
+	if !strings.Contains(text, `\`) {
+		return text
+	}
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\\' && i+1 < len(text) {
+			i++
+		}
+		b.WriteByte(text[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,51 @@
+package source_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jyopp/absorb"
+	"github.com/jyopp/absorb/source"
+)
+
+func TestAccessLog(t *testing.T) {
+	type Request struct {
+		Addr      string    `accesslog:"remote_addr"`
+		User      *string   `accesslog:"remote_user"`
+		Time      time.Time `accesslog:"timestamp"`
+		Method    string    `accesslog:"method"`
+		Path      string    `accesslog:"path"`
+		Status    int       `accesslog:"status"`
+		Bytes     *int64    `accesslog:"bytes"`
+		UserAgent string    `accesslog:"user_agent"`
+	}
+	const log = "" +
+		`127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326` + "\n" +
+		"\n" +
+		`192.0.2.7 - - [10/Oct/2000:13:56:00 +0000] "POST /api?q=\"x\" HTTP/1.1" 304 - "https://example.com/" "curl/8.0"` + "\n"
+
+	var dst []Request
+	if err := absorb.Absorb(&dst, source.AccessLog(strings.NewReader(log))); err != nil {
+		t.Fatal(err)
+	}
+	if len(dst) != 2 {
+		t.Fatalf("Expected 2 requests, got %+v", dst)
+	}
+	first, second := dst[0], dst[1]
+	if first.Addr != "127.0.0.1" || first.User == nil || *first.User != "frank" || first.Method != "GET" ||
+		first.Path != "/apache_pb.gif" || first.Status != 200 || *first.Bytes != 2326 {
+		t.Fatalf("Unexpected first request %+v", first)
+	}
+	if !first.Time.Equal(time.Date(2000, 10, 10, 20, 55, 36, 0, time.UTC)) {
+		t.Fatalf("Unexpected time %v", first.Time)
+	}
+	if second.User != nil || second.Bytes != nil || second.Path != `/api?q="x"` || second.UserAgent != "curl/8.0" || second.Status != 304 {
+		t.Fatalf("Unexpected second request %+v", second)
+	}
+
+	err := absorb.Absorb(&dst, source.AccessLog(strings.NewReader("garbage\n")))
+	if err == nil || !strings.HasPrefix(err.Error(), "line 1:") {
+		t.Fatalf("Expected a parse error, got %v", err)
+	}
+}
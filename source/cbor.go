@@ -0,0 +1,300 @@
+package source
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+
+	"github.com/jyopp/absorb"
+)
+
+// CBORSource emits a CBOR Sequence (RFC 8742), in which each data item is a map
+// representing one row. Keys are the maps' keys, in tag namespace "cbor".
+//
+// Items are decoded to int64 (or uint64 when too large), float64, string, []byte, bool,
+// nil, []interface{}, and map[interface{}]interface{}. Tagged items are decoded as
+// their content, without interpreting the tag.
+type CBORSource struct {
+	r io.Reader
+	// Keys are passed to Open. If empty, the sorted keys of the first item are used,
+	// and keys that only appear in later items are ignored.
+	Keys []string
+}
+
+// CBOR creates a source that decodes a CBOR Sequence from r.
+func CBOR(r io.Reader) *CBORSource {
+	return &CBORSource{r: r}
+}
+
+// Emit implements absorb.Absorbable
+func (s *CBORSource) Emit(into absorb.Absorber) error {
+	decoder := &cborDecoder{r: bufio.NewReader(s.r)}
+
+	record, err := decoder.nextRecord()
+	if err != nil {
+		return err
+	}
+	keys := s.Keys
+	if len(keys) == 0 {
+		for key := range record {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+	}
+
+	into.Open("cbor", -1, keys...)
+	defer into.Close()
+
+	rowData := make([]interface{}, len(keys))
+	for ; err == nil && record != nil; record, err = decoder.nextRecord() {
+		for idx, key := range keys {
+			rowData[idx] = record[key]
+		}
+		into.Absorb(rowData...)
+	}
+	return err
+}
+
+type cborDecoder struct {
+	r *bufio.Reader
+	// depth is the number of arrays, maps, and tags enclosing the item being decoded.
+	depth int
+}
+
+// cborMaxDepth limits the nesting of decoded items, so that malformed input cannot
+// exhaust the stack.
+const cborMaxDepth = 256
+
+// errBreak is returned when the "break" stop code ends an indefinite-length item.
+var errBreak = errors.New("cbor: unexpected break")
+
+// nextRecord decodes the next map in the sequence, or returns nil at the end of input.
+func (d *cborDecoder) nextRecord() (map[string]interface{}, error) {
+	if _, err := d.r.Peek(1); err == io.EOF {
+		return nil, nil
+	}
+	item, err := d.decode()
+	if err != nil {
+		return nil, err
+	}
+	m, ok := item.(map[interface{}]interface{})
+	if !ok {
+		return nil, fmt.Errorf("cbor: sequence item is %T, not a map", item)
+	}
+	record := make(map[string]interface{}, len(m))
+	for key, value := range m {
+		if s, ok := key.(string); ok {
+			record[s] = value
+		} else {
+			record[fmt.Sprint(key)] = value
+		}
+	}
+	return record, nil
+}
+
+func (d *cborDecoder) decode() (interface{}, error) {
+	initial, err := d.r.ReadByte()
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	major, info := initial>>5, initial&0x1f
+	if initial == 0xff {
+		return nil, errBreak
+	}
+	if major == 7 {
+		return d.decodeSimple(info)
+	}
+	if major >= 4 {
+		if d.depth++; d.depth > cborMaxDepth {
+			return nil, fmt.Errorf("cbor: items nested deeper than %d", cborMaxDepth)
+		}
+		defer func() { d.depth-- }()
+	}
+
+	indefinite := info == 31
+	var arg uint64
+	if !indefinite {
+		if arg, err = d.argument(info); err != nil {
+			return nil, err
+		}
+	}
+
+	switch major {
+	case 0:
+		if arg > math.MaxInt64 {
+			return arg, nil
+		}
+		return int64(arg), nil
+	case 1:
+		if arg > math.MaxInt64 {
+			return nil, errors.New("cbor: negative integer overflows int64")
+		}
+		return -1 - int64(arg), nil
+	case 2, 3:
+		var b []byte
+		if !indefinite {
+			if b, err = d.readBytes(arg); err != nil {
+				return nil, err
+			}
+		}
+		// Indefinite strings are a series of definite strings of the same major type,
+		// ended by a break. Chunks are read here rather than decoded, so they cannot nest.
+		for indefinite {
+			chunk, err := d.r.ReadByte()
+			if err != nil {
+				return nil, unexpectedEOF(err)
+			}
+			if chunk == 0xff {
+				break
+			}
+			if chunk>>5 != major || chunk&0x1f == 31 {
+				return nil, fmt.Errorf("cbor: invalid chunk 0x%02x in indefinite string", chunk)
+			}
+			length, err := d.argument(chunk & 0x1f)
+			if err != nil {
+				return nil, err
+			}
+			data, err := d.readBytes(length)
+			if err != nil {
+				return nil, err
+			}
+			b = append(b, data...)
+		}
+		if major == 3 {
+			return string(b), nil
+		}
+		return b, nil
+	case 4:
+		var items []interface{}
+		for i := uint64(0); indefinite || i < arg; i++ {
+			item, err := d.decode()
+			if indefinite && err == errBreak {
+				break
+			} else if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	case 5:
+		m := make(map[interface{}]interface{})
+		for i := uint64(0); indefinite || i < arg; i++ {
+			key, err := d.decode()
+			if indefinite && err == errBreak {
+				break
+			} else if err != nil {
+				return nil, err
+			}
+			value, err := d.decode()
+			if err != nil {
+				return nil, err
+			}
+			switch k := key.(type) {
+			case []byte:
+				// Byte slices are not comparable, so they cannot be map keys.
+				key = string(k)
+			case []interface{}, map[interface{}]interface{}:
+				return nil, fmt.Errorf("cbor: map key is of type %T", key)
+			}
+			m[key] = value
+		}
+		return m, nil
+	default:
+		// Major type 6 is a tag number followed by a single data item.
+		return d.decode()
+	}
+}
+
+// readBytes reads the n bytes of a definite-length string. The declared length is not
+// trusted: The buffer only grows as input arrives.
+func (d *cborDecoder) readBytes(n uint64) ([]byte, error) {
+	if n > math.MaxInt64 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	var buf bytes.Buffer
+	if copied, err := io.CopyN(&buf, d.r, int64(n)); uint64(copied) < n {
+		return nil, unexpectedEOF(err)
+	}
+	return buf.Bytes(), nil
+}
+
+// argument reads the integer argument that follows an initial byte.
+func (d *cborDecoder) argument(info byte) (uint64, error) {
+	if info < 24 {
+		return uint64(info), nil
+	}
+	var size int
+	switch info {
+	case 24:
+		size = 1
+	case 25:
+		size = 2
+	case 26:
+		size = 4
+	case 27:
+		size = 8
+	default:
+		return 0, fmt.Errorf("cbor: invalid additional information %d", info)
+	}
+	var buf [8]byte
+	if _, err := io.ReadFull(d.r, buf[8-size:]); err != nil {
+		return 0, unexpectedEOF(err)
+	}
+	return binary.BigEndian.Uint64(buf[:]), nil
+}
+
+func (d *cborDecoder) decodeSimple(info byte) (interface{}, error) {
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23:
+		// null and undefined
+		return nil, nil
+	case 25:
+		bits, err := d.argument(info)
+		return float64(halfToFloat(uint16(bits))), err
+	case 26:
+		bits, err := d.argument(info)
+		return float64(math.Float32frombits(uint32(bits))), err
+	case 27:
+		bits, err := d.argument(info)
+		return math.Float64frombits(bits), err
+	}
+	// Unassigned simple values are returned as their number.
+	value, err := d.argument(info)
+	return value, err
+}
+
+// halfToFloat converts an IEEE 754 half-precision float to a float32.
+func halfToFloat(h uint16) float32 {
+	sign := uint32(h>>15) << 31
+	exp := uint32(h>>10) & 0x1f
+	frac := uint32(h & 0x3ff)
+	switch exp {
+	case 0:
+		// Zero or subnormal
+		f := float32(frac) / (1 << 24)
+		if sign != 0 {
+			f = -f
+		}
+		return f
+	case 0x1f:
+		// Infinity or NaN
+		return math.Float32frombits(sign | 0x7f800000 | frac<<13)
+	}
+	return math.Float32frombits(sign | (exp+112)<<23 | frac<<13)
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,93 @@
+package source_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/jyopp/absorb"
+	"github.com/jyopp/absorb/source"
+)
+
+func TestCBOR(t *testing.T) {
+	type Reading struct {
+		Sensor string
+		Value  float64
+		Raw    []byte
+		OK     *bool `cbor:"ok"`
+	}
+
+	seq := []byte{
+		// {"sensor": "a1", "value": 1.5 (half), "ok": true}
+		0xa3,
+		0x66, 's', 'e', 'n', 's', 'o', 'r', 0x62, 'a', '1',
+		0x65, 'v', 'a', 'l', 'u', 'e', 0xf9, 0x3e, 0x00,
+		0x62, 'o', 'k', 0xf5,
+		// {_ "sensor": "b2", "value": -3, "raw": h'0102'} (indefinite map)
+		0xbf,
+		0x66, 's', 'e', 'n', 's', 'o', 'r', 0x62, 'b', '2',
+		0x65, 'v', 'a', 'l', 'u', 'e', 0x22,
+		0x63, 'r', 'a', 'w', 0x42, 0x01, 0x02,
+		0xff,
+	}
+
+	var dst []Reading
+	src := source.CBOR(bytes.NewReader(seq))
+	src.Keys = []string{"sensor", "value", "raw", "ok"}
+	if err := absorb.Absorb(&dst, src); err != nil {
+		t.Fatal(err)
+	}
+	if len(dst) != 2 {
+		t.Fatalf("Expected 2 readings, got %+v", dst)
+	}
+	if dst[0].Sensor != "a1" || dst[0].Value != 1.5 || dst[0].OK == nil || !*dst[0].OK {
+		t.Fatalf("Unexpected first reading %+v", dst[0])
+	}
+	if dst[1].Value != -3 || !bytes.Equal(dst[1].Raw, []byte{1, 2}) || dst[1].OK != nil {
+		t.Fatalf("Unexpected second reading %+v", dst[1])
+	}
+
+	// Truncated input is an error.
+	var ignored []Reading
+	if err := absorb.Absorb(&ignored, source.CBOR(bytes.NewReader(seq[:10]))); err != io.ErrUnexpectedEOF {
+		t.Fatal("Expected unexpected EOF, got", err)
+	}
+}
+
+func TestCBORMalformed(t *testing.T) {
+	deep := bytes.Repeat([]byte{0x81}, 10000)
+	for name, input := range map[string][]byte{
+		"Huge byte string":  {0xa1, 0x61, 'a', 0x5b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		"Long byte string":  {0xa1, 0x61, 'a', 0x5b, 0x00, 0x00, 0x00, 0x00, 0x7f, 0xff, 0xff, 0xff, 0x01},
+		"Array key":         {0xa1, 0x80, 0x01},
+		"Map key":           {0xa1, 0xa0, 0x01},
+		"Deep nesting":      append([]byte{0xa1, 0x61, 'a'}, deep...),
+		"Deep tags":         append([]byte{0xa1, 0x61, 'a'}, bytes.Repeat([]byte{0xc1}, 10000)...),
+		"Non-string chunk":  {0xa1, 0x61, 'a', 0x5f, 0x80, 0xff},
+		"Text chunk":        {0xa1, 0x61, 'a', 0x5f, 0x61, 'b', 0xff},
+		"Nested strings":    append([]byte{0xa1, 0x61, 'a'}, bytes.Repeat([]byte{0x5f}, 1<<20)...),
+		"Huge array length": {0xa1, 0x61, 'a', 0x9b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+	} {
+		var dst []map[string]interface{}
+		if err := absorb.Absorb(&dst, source.CBOR(bytes.NewReader(input))); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func FuzzCBOR(f *testing.F) {
+	f.Add([]byte{0xa1, 0x61, 'a', 0x01})
+	f.Add([]byte{0xbf, 0x61, 'a', 0x5f, 0x41, 0x01, 0xff, 0xff})
+	f.Add([]byte{0xa1, 0x80, 0x01})
+	f.Fuzz(func(t *testing.T, input []byte) {
+		// Malformed input must be reported as an error, rather than panic.
+		source.CBOR(bytes.NewReader(input)).Emit(discard{})
+	})
+}
+
+// discard is an Absorber that accepts any rows, and keeps none.
+type discard struct{}
+
+func (discard) Open(tag string, count int, keys ...string) {}
+func (discard) Absorb(values ...interface{})               {}
+func (discard) Close()                                     {}
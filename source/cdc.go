@@ -0,0 +1,362 @@
+package source
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jyopp/absorb"
+)
+
+// Metadata columns emitted by ChangeSource and ChangeRecords, alongside each row's
+// columns. Their names follow Debezium's convention for flattened events.
+const (
+	// OpColumn holds the change's operation: "insert", "update", or "delete".
+	OpColumn = "__op"
+	// TableColumn holds the changed table's name, qualified by its schema if known.
+	TableColumn = "__table"
+	// TimeColumn holds the time.Time of the change, or nil if it is not known.
+	TimeColumn = "__ts"
+	// BeforeColumn holds the row's previous values as a map[string]interface{}, or nil.
+	BeforeColumn = "__before"
+)
+
+// ChangeEvent is a row change decoded from a change-data-capture stream.
+type ChangeEvent struct {
+	Op    absorb.ChangeOp
+	Table string
+	Time  time.Time
+	// Before holds the previous values of updated and deleted rows, as far as the stream
+	// reports them; Often only the primary key. It is nil for inserts.
+	Before map[string]interface{}
+	// After holds the values of inserted and updated rows. It is nil for deletes.
+	After map[string]interface{}
+}
+
+// Row returns the event's current values: After, or Before for deletes.
+func (e ChangeEvent) Row() map[string]interface{} {
+	if e.Op == absorb.Delete {
+		return e.Before
+	}
+	return e.After
+}
+
+// ChangeDecoder decodes one message of a change stream into the row changes it holds.
+// Messages that hold no row changes, such as transaction markers, decode to no events.
+type ChangeDecoder func(payload []byte) ([]ChangeEvent, error)
+
+// ChangeSource emits the row changes decoded from a stream of JSON messages, in tag
+// namespace "cdc". Each row holds the changed row's values, with the metadata columns
+// OpColumn, TableColumn, TimeColumn, and BeforeColumn.
+//
+// Integers are decoded as int64 when they fit, and other numbers as float64.
+type ChangeSource struct {
+	r      io.Reader
+	decode ChangeDecoder
+	// Keys are the row columns passed to Open, after the metadata columns. If empty, the
+	// sorted columns of the first change are used, and other columns are ignored.
+	Keys []string
+}
+
+// Changes creates a source that decodes consecutive JSON messages from r, such as the
+// output of pg_recvlogical or a file of Debezium events, with decode.
+func Changes(r io.Reader, decode ChangeDecoder) *ChangeSource {
+	return &ChangeSource{r: r, decode: decode}
+}
+
+// Emit implements absorb.Absorbable
+func (s *ChangeSource) Emit(into absorb.Absorber) error {
+	decoder := json.NewDecoder(s.r)
+	var pending []ChangeEvent
+	next := func() (*ChangeEvent, error) {
+		for len(pending) == 0 {
+			var msg json.RawMessage
+			if err := decoder.Decode(&msg); err == io.EOF {
+				return nil, nil
+			} else if err != nil {
+				return nil, err
+			}
+			events, err := s.decode(msg)
+			if err != nil {
+				return nil, err
+			}
+			pending = events
+		}
+		event := &pending[0]
+		pending = pending[1:]
+		return event, nil
+	}
+
+	event, err := next()
+	if err != nil || event == nil {
+		return err
+	}
+	keys := s.Keys
+	if len(keys) == 0 {
+		keys = sortedKeys(event.Row())
+	}
+
+	into.Open("cdc", -1, append(changeColumns(), keys...)...)
+	defer into.Close()
+
+	rowData := make([]interface{}, len(keys)+4)
+	for ; err == nil && event != nil; event, err = next() {
+		event.fill(rowData, keys)
+		into.Absorb(rowData...)
+	}
+	return err
+}
+
+// ChangeRecords adapts decode to a KafkaDecoder, for topics with one row change per
+// message, such as Debezium's. Each record holds the row's columns and the metadata
+// columns. Messages without a change, such as tombstones, decode to empty records.
+func ChangeRecords(decode ChangeDecoder) KafkaDecoder {
+	return func(payload []byte) (map[string]interface{}, error) {
+		events, err := decode(payload)
+		if err != nil || len(events) == 0 {
+			return nil, err
+		} else if len(events) > 1 {
+			return nil, fmt.Errorf("source: message holds %d changes; expected one", len(events))
+		}
+		event := events[0]
+		record := make(map[string]interface{}, len(event.Row())+4)
+		for key, value := range event.Row() {
+			record[key] = value
+		}
+		meta := make([]interface{}, 4)
+		event.fill(meta, nil)
+		for idx, column := range changeColumns() {
+			record[column] = meta[idx]
+		}
+		return record, nil
+	}
+}
+
+func changeColumns() []string {
+	return []string{OpColumn, TableColumn, TimeColumn, BeforeColumn}
+}
+
+// fill sets the metadata columns, then the values of keys, in rowData.
+func (e *ChangeEvent) fill(rowData []interface{}, keys []string) {
+	rowData[0] = strings.ToLower(e.Op.String())
+	rowData[1] = e.Table
+	rowData[2], rowData[3] = nil, nil
+	if !e.Time.IsZero() {
+		rowData[2] = e.Time
+	}
+	if e.Before != nil {
+		rowData[3] = e.Before
+	}
+	row := e.Row()
+	for idx, key := range keys {
+		rowData[idx+4] = row[key]
+	}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Debezium decodes a Debezium change event, with or without its schema envelope.
+// Snapshot reads are decoded as inserts; Tombstones and truncations decode to no events.
+func Debezium(payload []byte) ([]ChangeEvent, error) {
+	var event struct {
+		Payload *json.RawMessage
+		Before  map[string]interface{}
+		After   map[string]interface{}
+		Op      string
+		TsMs    json.Number `json:"ts_ms"`
+		Source  struct {
+			Schema string
+			Table  string
+		}
+	}
+	if len(bytes.TrimSpace(payload)) == 0 {
+		return nil, nil
+	}
+	if err := decodeChange(payload, &event); err != nil {
+		return nil, err
+	}
+	if event.Payload != nil {
+		// Events serialized with schemas wrap the change in a payload field.
+		if bytes.Equal(*event.Payload, []byte("null")) {
+			return nil, nil
+		}
+		return Debezium(*event.Payload)
+	}
+
+	change := ChangeEvent{Table: qualifiedTable(event.Source.Schema, event.Source.Table)}
+	change.Before, _ = normalizeNumbers(event.Before).(map[string]interface{})
+	change.After, _ = normalizeNumbers(event.After).(map[string]interface{})
+	switch event.Op {
+	case "c", "r":
+		change.Op = absorb.Insert
+	case "u":
+		change.Op = absorb.Update
+	case "d":
+		change.Op = absorb.Delete
+	case "", "t", "m":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("source: unknown Debezium operation %q", event.Op)
+	}
+	if ms, err := event.TsMs.Int64(); err == nil {
+		change.Time = time.UnixMilli(ms).UTC()
+	}
+	return []ChangeEvent{change}, nil
+}
+
+// wal2jsonTime is the layout of timestamps included by wal2json.
+const wal2jsonTime = "2006-01-02 15:04:05.999999999-07"
+
+// Wal2JSON decodes the output of PostgreSQL's wal2json logical decoding plugin, in either
+// format version. Version 1 messages hold every change of a transaction; Version 2
+// messages hold one change each. Transaction markers and messages decode to no events.
+func Wal2JSON(payload []byte) ([]ChangeEvent, error) {
+	var msg struct {
+		// Format version 1
+		Timestamp string
+		Change    []struct {
+			Kind         string
+			Schema       string
+			Table        string
+			ColumnNames  []string
+			ColumnValues []interface{}
+			OldKeys      struct {
+				KeyNames  []string
+				KeyValues []interface{}
+			}
+		}
+		// Format version 2
+		Action   string
+		Schema   string
+		Table    string
+		Columns  []wal2jsonColumn
+		Identity []wal2jsonColumn
+	}
+	if err := decodeChange(payload, &msg); err != nil {
+		return nil, err
+	}
+	var when time.Time
+	if msg.Timestamp != "" {
+		when, _ = time.Parse(wal2jsonTime, msg.Timestamp)
+	}
+
+	if msg.Action != "" {
+		change := ChangeEvent{Table: qualifiedTable(msg.Schema, msg.Table), Time: when}
+		switch msg.Action {
+		case "I":
+			change.Op = absorb.Insert
+		case "U":
+			change.Op = absorb.Update
+		case "D":
+			change.Op = absorb.Delete
+		default:
+			return nil, nil
+		}
+		change.After = wal2jsonRow(msg.Columns)
+		change.Before = wal2jsonRow(msg.Identity)
+		return []ChangeEvent{change}, nil
+	}
+
+	var events []ChangeEvent
+	for _, c := range msg.Change {
+		change := ChangeEvent{Table: qualifiedTable(c.Schema, c.Table), Time: when}
+		switch c.Kind {
+		case "insert":
+			change.Op = absorb.Insert
+		case "update":
+			change.Op = absorb.Update
+		case "delete":
+			change.Op = absorb.Delete
+		default:
+			continue
+		}
+		if len(c.ColumnNames) != len(c.ColumnValues) || len(c.OldKeys.KeyNames) != len(c.OldKeys.KeyValues) {
+			return nil, errors.New("source: wal2json change has mismatched names and values")
+		}
+		change.After = zipRow(c.ColumnNames, c.ColumnValues)
+		change.Before = zipRow(c.OldKeys.KeyNames, c.OldKeys.KeyValues)
+		events = append(events, change)
+	}
+	return events, nil
+}
+
+type wal2jsonColumn struct {
+	Name  string
+	Value interface{}
+}
+
+// wal2jsonRow returns the values of columns by name, or nil if there are none.
+func wal2jsonRow(columns []wal2jsonColumn) map[string]interface{} {
+	if len(columns) == 0 {
+		return nil
+	}
+	row := make(map[string]interface{}, len(columns))
+	for _, col := range columns {
+		row[col.Name] = normalizeNumbers(col.Value)
+	}
+	return row
+}
+
+// zipRow returns the values of a row by column name, or nil if there are none.
+func zipRow(names []string, values []interface{}) map[string]interface{} {
+	if len(names) == 0 {
+		return nil
+	}
+	row := make(map[string]interface{}, len(names))
+	for idx, name := range names {
+		row[name] = normalizeNumbers(values[idx])
+	}
+	return row
+}
+
+func qualifiedTable(schema, table string) string {
+	if schema == "" {
+		return table
+	}
+	return schema + "." + table
+}
+
+// decodeChange unmarshals payload into v, decoding numbers so that integers keep their
+// precision.
+func decodeChange(payload []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("source: cannot decode change: %w", err)
+	}
+	return nil
+}
+
+// normalizeNumbers replaces each json.Number in v with an int64 if it is an integer that
+// fits, or a float64 otherwise. Maps are modified in place.
+func normalizeNumbers(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for key, value := range v {
+			v[key] = normalizeNumbers(value)
+		}
+	case []interface{}:
+		for idx, value := range v {
+			v[idx] = normalizeNumbers(value)
+		}
+	}
+	return v
+}
@@ -0,0 +1,101 @@
+package source_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jyopp/absorb"
+	"github.com/jyopp/absorb/source"
+)
+
+type userChange struct {
+	Op     string                 `cdc:"__op"`
+	Table  string                 `cdc:"__table"`
+	Time   time.Time              `cdc:"__ts"`
+	Before map[string]interface{} `cdc:"__before"`
+	ID     int64                  `cdc:"id"`
+	Name   string                 `cdc:"name"`
+}
+
+func TestDebezium(t *testing.T) {
+	stream := `
+		{"schema": {}, "payload": {"before": null, "after": {"id": 9007199254740993, "name": "ann"},
+			"source": {"schema": "public", "table": "users"}, "op": "c", "ts_ms": 1700000000000}}
+		{"before": {"id": 1, "name": "bob"}, "after": {"id": 1, "name": "rob"},
+			"source": {"table": "users"}, "op": "u"}
+		{"before": {"id": 2, "name": "cat"}, "after": null, "source": {"table": "users"}, "op": "d"}
+		{"schema": {}, "payload": null}
+	`
+	var dst []userChange
+	if err := absorb.Absorb(&dst, source.Changes(strings.NewReader(stream), source.Debezium)); err != nil {
+		t.Fatal(err)
+	}
+	expect := []userChange{
+		{"insert", "public.users", time.UnixMilli(1700000000000).UTC(), nil, 9007199254740993, "ann"},
+		{"update", "users", time.Time{}, map[string]interface{}{"id": int64(1), "name": "bob"}, 1, "rob"},
+		{"delete", "users", time.Time{}, map[string]interface{}{"id": int64(2), "name": "cat"}, 2, "cat"},
+	}
+	if !reflect.DeepEqual(dst, expect) {
+		t.Fatalf("Expected %+v, got %+v", expect, dst)
+	}
+}
+
+func TestWal2JSON(t *testing.T) {
+	v1 := `{"xid": 1, "timestamp": "2024-01-02 03:04:05.5+00", "change": [
+		{"kind": "insert", "schema": "public", "table": "users",
+			"columnnames": ["id", "name"], "columntypes": ["int4", "text"], "columnvalues": [1, "ann"]},
+		{"kind": "delete", "schema": "public", "table": "users",
+			"oldkeys": {"keynames": ["id"], "keytypes": ["int4"], "keyvalues": [2]}}
+	]}`
+	v2 := `{"action": "B"}
+		{"action": "U", "schema": "public", "table": "users",
+			"columns": [{"name": "id", "type": "integer", "value": 1}, {"name": "name", "type": "text", "value": "rob"}],
+			"identity": [{"name": "id", "type": "integer", "value": 1}]}
+		{"action": "C"}`
+
+	var dst []userChange
+	if err := absorb.Absorb(&dst, source.Changes(strings.NewReader(v1+v2), source.Wal2JSON)); err != nil {
+		t.Fatal(err)
+	}
+	when := time.Date(2024, 1, 2, 3, 4, 5, 5e8, time.UTC)
+	expect := []userChange{
+		{"insert", "public.users", when, nil, 1, "ann"},
+		{"delete", "public.users", when, map[string]interface{}{"id": int64(2)}, 2, ""},
+		{"update", "public.users", time.Time{}, map[string]interface{}{"id": int64(1)}, 1, "rob"},
+	}
+	if len(dst) != len(expect) {
+		t.Fatalf("Expected %d changes, got %+v", len(expect), dst)
+	}
+	for idx := range expect {
+		if !dst[idx].Time.Equal(expect[idx].Time) {
+			t.Fatalf("Expected time %v, got %v", expect[idx].Time, dst[idx].Time)
+		}
+		dst[idx].Time = expect[idx].Time
+	}
+	if !reflect.DeepEqual(dst, expect) {
+		t.Fatalf("Expected %+v, got %+v", expect, dst)
+	}
+}
+
+func TestChangeRecords(t *testing.T) {
+	consumer := &fakeConsumer{messages: make(chan source.KafkaMessage, 1)}
+	consumer.messages <- source.KafkaMessage{Value: []byte(`{"after": {"id": 7, "name": "dee"}, "op": "r"}`)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	kafka := source.Kafka(ctx, consumer)
+	kafka.Decode = source.ChangeRecords(source.Debezium)
+	kafka.Keys = []string{"__op", "id", "name"}
+	var received userChange
+	if err := absorb.Absorb(func(c userChange) {
+		received = c
+		cancel()
+	}, kafka, absorb.Tags("cdc")); err != nil {
+		t.Fatal(err)
+	}
+	if received.Op != "insert" || received.ID != 7 || received.Name != "dee" {
+		t.Fatalf("Unexpected change %+v", received)
+	}
+}
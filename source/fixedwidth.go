@@ -0,0 +1,73 @@
+package source
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jyopp/absorb"
+)
+
+// FixedWidthColumn describes one field of a fixed-width record.
+type FixedWidthColumn struct {
+	Name string
+	// Start is the zero-based byte offset of the field within each line.
+	Start int
+	Width int
+	Type  ValueType
+}
+
+// FixedWidthSource emits one row per line of mainframe-style fixed-width text.
+// Fields are sliced from each line by byte offset, and surrounding spaces are trimmed
+// before parsing. Fields beyond the end of a short line are blank.
+//
+// Keys are the column names, in tag namespace "fixedwidth".
+type FixedWidthSource struct {
+	r       io.Reader
+	columns []FixedWidthColumn
+}
+
+// FixedWidth creates a source that reads records described by columns from r.
+func FixedWidth(r io.Reader, columns ...FixedWidthColumn) *FixedWidthSource {
+	return &FixedWidthSource{r: r, columns: columns}
+}
+
+// Emit implements absorb.Absorbable
+func (s *FixedWidthSource) Emit(into absorb.Absorber) error {
+	keys := make([]string, len(s.columns))
+	for idx, col := range s.columns {
+		keys[idx] = col.Name
+	}
+
+	into.Open("fixedwidth", -1, keys...)
+	defer into.Close()
+
+	rowData := make([]interface{}, len(keys))
+	scanner := bufio.NewScanner(s.r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := scanner.Text()
+		if len(strings.TrimSpace(line)) == 0 {
+			continue
+		}
+		for idx, col := range s.columns {
+			value, err := col.Type.parse(strings.TrimSpace(sliceField(line, col.Start, col.Width)))
+			if err != nil {
+				return fmt.Errorf("line %d, column %s: %w", lineNum, col.Name, err)
+			}
+			rowData[idx] = value
+		}
+		into.Absorb(rowData...)
+	}
+	return scanner.Err()
+}
+
+func sliceField(line string, start, width int) string {
+	if start >= len(line) {
+		return ""
+	}
+	if end := start + width; end < len(line) {
+		return line[start:end]
+	}
+	return line[start:]
+}
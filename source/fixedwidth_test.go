@@ -0,0 +1,54 @@
+package source_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jyopp/absorb"
+	"github.com/jyopp/absorb/source"
+)
+
+func TestFixedWidth(t *testing.T) {
+	type Account struct {
+		ID      int64
+		Name    string
+		Balance *float64
+	}
+
+	const data = "" +
+		"0001Picard      00012.50\n" +
+		"\n" +
+		"0002Riker       \n" +
+		"0003Troi        99999.99\n"
+
+	src := source.FixedWidth(strings.NewReader(data),
+		source.FixedWidthColumn{Name: "id", Start: 0, Width: 4, Type: source.TypeInt},
+		source.FixedWidthColumn{Name: "name", Start: 4, Width: 12},
+		source.FixedWidthColumn{Name: "balance", Start: 16, Width: 8, Type: source.TypeFloat},
+	)
+
+	var dst []Account
+	if err := absorb.Absorb(&dst, src); err != nil {
+		t.Fatal(err)
+	}
+	if len(dst) != 3 {
+		t.Fatalf("Expected 3 accounts, got %+v", dst)
+	}
+	if dst[0].ID != 1 || dst[0].Name != "Picard" || *dst[0].Balance != 12.5 {
+		t.Fatalf("Unexpected first account %+v", dst[0])
+	}
+	if dst[1].Balance != nil {
+		t.Fatalf("Expected nil balance for short line, got %v", *dst[1].Balance)
+	}
+}
+
+func TestFixedWidthParseError(t *testing.T) {
+	src := source.FixedWidth(strings.NewReader("00x1\n"),
+		source.FixedWidthColumn{Name: "id", Width: 4, Type: source.TypeInt},
+	)
+	var dst []int64
+	err := absorb.Absorb(&dst, src)
+	if err == nil || !strings.Contains(err.Error(), "line 1, column id") {
+		t.Fatalf("Expected parse error with location, got %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package source
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/jyopp/absorb"
+)
+
+// FlagSource emits parsed command-line flags as a single row, keyed by flag name in tag
+// namespace "flag", so that a tool can populate its options struct with the same tags and
+// conversions it uses for config files. Flags whose Value implements flag.Getter emit
+// their typed value, such as a bool or time.Duration, and others emit their String.
+//
+// Because absorbing a single row into a struct pointer assigns only the row's keys, a
+// struct may be absorbed from a config file first, and then from the flags that were set.
+//
+// Example:
+//
+//	var opts Options
+//	absorb.Absorb(&opts, source.TOMLTables(configFile, "options"))
+//	absorb.Absorb(&opts, source.Flags(flag.CommandLine), absorb.Tags("toml"))
+type FlagSource struct {
+	fs  *flag.FlagSet
+	all bool
+}
+
+// Flags creates a source that emits the flags of fs that were set on the command line.
+// It must be used after fs is parsed.
+func Flags(fs *flag.FlagSet) *FlagSource {
+	return &FlagSource{fs: fs}
+}
+
+// AllFlags creates a source that emits every flag of fs, set or not, with its current
+// value, which is its default if it was not set.
+func AllFlags(fs *flag.FlagSet) *FlagSource {
+	return &FlagSource{fs: fs, all: true}
+}
+
+// Emit implements absorb.Absorbable
+func (s *FlagSource) Emit(into absorb.Absorber) error {
+	var keys []string
+	var rowData []interface{}
+	visit := func(f *flag.Flag) {
+		keys = append(keys, f.Name)
+		if getter, ok := f.Value.(flag.Getter); ok {
+			rowData = append(rowData, getter.Get())
+		} else {
+			rowData = append(rowData, f.Value.String())
+		}
+	}
+	if s.all {
+		s.fs.VisitAll(visit)
+	} else {
+		s.fs.Visit(visit)
+	}
+
+	into.Open("flag", 1, keys...)
+	defer into.Close()
+	into.Absorb(rowData...)
+	return nil
+}
+
+// ArgsSource emits arguments of the form key=value, such as "--name=value" or
+// "level=3", as a single row of string values, keyed in tag namespace "flag". Leading
+// dashes are removed from keys, and an argument without a value, such as "--verbose",
+// is "true". A key given more than once keeps its last value. Values are parsed into
+// numeric, bool, and time.Duration fields, and a value that cannot be assigned to its
+// field is returned from Emit as a *absorb.ConversionError, rather than raised as a panic.
+type ArgsSource struct {
+	args []string
+}
+
+// Args creates a source that emits args, such as os.Args[1:].
+func Args(args []string) *ArgsSource {
+	return &ArgsSource{args: args}
+}
+
+// Emit implements absorb.Absorbable
+func (s *ArgsSource) Emit(into absorb.Absorber) (err error) {
+	defer recoverConversion(&err)
+	var keys []string
+	var rowData []interface{}
+	index := make(map[string]int)
+	for _, arg := range s.args {
+		key, value, hasValue := strings.Cut(arg, "=")
+		dashed := strings.HasPrefix(key, "-")
+		if key = strings.TrimLeft(key, "-"); key == "" || !hasValue && !dashed {
+			return fmt.Errorf("argument %q is not of the form key=value", arg)
+		}
+		if !hasValue {
+			value = "true"
+		}
+		if idx, ok := index[key]; ok {
+			rowData[idx] = value
+			continue
+		}
+		index[key] = len(keys)
+		keys = append(keys, key)
+		rowData = append(rowData, value)
+	}
+
+	into.Open("flag", 1, keys...)
+	defer into.Close()
+	into.Absorb(rowData...)
+	return nil
+}
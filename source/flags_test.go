@@ -0,0 +1,63 @@
+package source_test
+
+import (
+	"errors"
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/jyopp/absorb"
+	"github.com/jyopp/absorb/source"
+)
+
+type options struct {
+	Name    string        `toml:"name"`
+	Verbose bool          `toml:"verbose"`
+	Timeout time.Duration `toml:"timeout"`
+	Level   int           `flag:"level"`
+}
+
+func TestFlags(t *testing.T) {
+	fs := flag.NewFlagSet("tool", flag.ContinueOnError)
+	fs.String("name", "default", "")
+	fs.Bool("verbose", false, "")
+	fs.Duration("timeout", time.Second, "")
+	if err := fs.Parse([]string{"-verbose", "-timeout=5s"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only flags that were set replace values from a config file.
+	opts := options{Name: "from config"}
+	if err := absorb.Absorb(&opts, source.Flags(fs), absorb.Tags("toml")); err != nil {
+		t.Fatal(err)
+	}
+	if opts.Name != "from config" || !opts.Verbose || opts.Timeout != 5*time.Second {
+		t.Fatalf("Unexpected options %+v", opts)
+	}
+
+	if err := absorb.Absorb(&opts, source.AllFlags(fs), absorb.Tags("toml")); err != nil {
+		t.Fatal(err)
+	}
+	if opts.Name != "default" {
+		t.Fatalf("Expected the flag's default, got %+v", opts)
+	}
+}
+
+func TestArgs(t *testing.T) {
+	var opts options
+	args := []string{"--name=first", "level=3", "--verbose", "name=second", "-timeout=1m30s"}
+	if err := absorb.Absorb(&opts, source.Args(args), absorb.Tags("toml")); err != nil {
+		t.Fatal(err)
+	}
+	if opts.Name != "second" || opts.Level != 3 || !opts.Verbose || opts.Timeout != 90*time.Second {
+		t.Fatalf("Unexpected options %+v", opts)
+	}
+
+	if err := absorb.Absorb(&opts, source.Args([]string{"positional"})); err == nil {
+		t.Fatal("Expected an error for a positional argument")
+	}
+	var convErr *absorb.ConversionError
+	if err := absorb.Absorb(&opts, source.Args([]string{"--level=high"})); !errors.As(err, &convErr) {
+		t.Fatalf("Expected a conversion error, got %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package source
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"sort"
+
+	"github.com/jyopp/absorb"
+)
+
+// FormSource emits url.Values, such as a parsed query string or POST form, as a single
+// row. Keys are the sorted parameter names, in tag namespace "form".
+//
+// Only the first value of each parameter is emitted, as a string, which is parsed into
+// numeric, bool, and time.Duration fields. Since forms are client input, a value that
+// cannot be assigned to its field is returned from Emit as a *absorb.ConversionError,
+// rather than raised as a panic.
+type FormSource struct {
+	values  url.Values
+	request *http.Request
+}
+
+// Form creates a source that emits values as a single row.
+func Form(values url.Values) *FormSource {
+	return &FormSource{values: values}
+}
+
+// Request creates a source that emits the query and form parameters of r as a single
+// row. The form is parsed when the source is emitted, and parse errors are returned
+// from Emit.
+//
+// Example:
+//
+//	var req SearchRequest
+//	if err := absorb.Absorb(&req, source.Request(r)); err != nil {
+//		http.Error(w, err.Error(), http.StatusBadRequest)
+//	}
+func Request(r *http.Request) *FormSource {
+	return &FormSource{request: r}
+}
+
+// Emit implements absorb.Absorbable
+func (s *FormSource) Emit(into absorb.Absorber) (err error) {
+	defer recoverConversion(&err)
+	values := s.values
+	if s.request != nil {
+		if err := s.request.ParseForm(); err != nil {
+			return err
+		}
+		values = s.request.Form
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	into.Open("form", 1, keys...)
+	defer into.Close()
+
+	rowData := make([]interface{}, len(keys))
+	for idx, key := range keys {
+		rowData[idx] = values.Get(key)
+	}
+	into.Absorb(rowData...)
+	return nil
+}
+
+// recoverConversion returns a *absorb.ConversionError raised by an Absorber as err, for
+// sources of untrusted input. Other panics are raised again.
+func recoverConversion(err *error) {
+	if p := recover(); p != nil {
+		var convErr *absorb.ConversionError
+		if e, ok := p.(error); !ok || !errors.As(e, &convErr) {
+			panic(p)
+		}
+		*err = convErr
+	}
+}
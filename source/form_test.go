@@ -0,0 +1,75 @@
+package source_test
+
+import (
+	"errors"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jyopp/absorb"
+	"github.com/jyopp/absorb/source"
+)
+
+type SearchRequest struct {
+	Query string `form:"q"`
+	Sort  string
+	Page  *string
+}
+
+func TestForm(t *testing.T) {
+	values := url.Values{"q": {"absorb", "ignored"}, "sort": {"name"}}
+
+	var dst SearchRequest
+	if err := absorb.Absorb(&dst, source.Form(values)); err != nil {
+		t.Fatal(err)
+	}
+	if dst.Query != "absorb" || dst.Sort != "name" || dst.Page != nil {
+		t.Fatalf("Unexpected request %+v", dst)
+	}
+}
+
+func TestRequest(t *testing.T) {
+	r := httptest.NewRequest("POST", "/search?q=absorb", strings.NewReader("page=2"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var dst SearchRequest
+	if err := absorb.Absorb(&dst, source.Request(r)); err != nil {
+		t.Fatal(err)
+	}
+	if dst.Query != "absorb" || dst.Page == nil || *dst.Page != "2" {
+		t.Fatalf("Unexpected request %+v", dst)
+	}
+
+	bad := httptest.NewRequest("GET", "/search", nil)
+	bad.URL.RawQuery = "q=%zz"
+	if err := absorb.Absorb(&dst, source.Request(bad)); err == nil {
+		t.Fatal("Expected error for malformed query")
+	}
+}
+
+func TestFormTyped(t *testing.T) {
+	type PageRequest struct {
+		Page    int     `form:"page"`
+		Ratio   float64 `form:"ratio"`
+		Draft   bool    `form:"draft"`
+		Timeout time.Duration
+	}
+	values := url.Values{"page": {" 2"}, "ratio": {"0.5"}, "draft": {"on"}, "timeout": {"1m30s"}}
+
+	var dst PageRequest
+	if err := absorb.Absorb(&dst, source.Form(values)); err != nil {
+		t.Fatal(err)
+	}
+	if expect := (PageRequest{2, 0.5, true, 90 * time.Second}); dst != expect {
+		t.Fatalf("Expected %+v, got %+v", expect, dst)
+	}
+
+	// Client input that cannot be converted is an error, not a panic.
+	var convErr *absorb.ConversionError
+	err := absorb.Absorb(&dst, source.Form(url.Values{"page": {"two"}}))
+	if !errors.As(err, &convErr) || convErr.Key != "page" {
+		t.Fatalf("Expected a conversion error for page, got %v", err)
+	}
+}
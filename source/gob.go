@@ -0,0 +1,71 @@
+package source
+
+import (
+	"encoding/gob"
+	"io"
+	"reflect"
+
+	"github.com/jyopp/absorb"
+)
+
+// GobSource emits a gob stream of homogeneous values, such as one recorded from an
+// earlier absorb pipeline, in tag namespace "gob".
+//
+// By default, struct values are emitted as rows keyed by their exported field names.
+// Other values, and structs when Whole is set, are emitted whole under the single key
+// "value", which absorbs each value directly into a []T or chan T.
+type GobSource struct {
+	r   io.Reader
+	typ reflect.Type
+	// Whole emits each decoded value as a single column, even if it is a struct.
+	Whole bool
+}
+
+// Gob creates a source that decodes values of example's type from r.
+// If example is a pointer, values of the type it points to are decoded.
+func Gob(r io.Reader, example interface{}) *GobSource {
+	typ := reflect.TypeOf(example)
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return &GobSource{r: r, typ: typ}
+}
+
+// Emit implements absorb.Absorbable
+func (s *GobSource) Emit(into absorb.Absorber) error {
+	var fields []int
+	keys := []string{"value"}
+	if !s.Whole && s.typ.Kind() == reflect.Struct {
+		keys = keys[:0]
+		for i := 0; i < s.typ.NumField(); i++ {
+			if field := s.typ.Field(i); field.PkgPath == "" {
+				fields = append(fields, i)
+				keys = append(keys, field.Name)
+			}
+		}
+	}
+
+	into.Open("gob", -1, keys...)
+	defer into.Close()
+
+	decoder := gob.NewDecoder(s.r)
+	rowData := make([]interface{}, len(keys))
+	for {
+		value := reflect.New(s.typ)
+		if err := decoder.DecodeValue(value); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		value = value.Elem()
+
+		if fields == nil {
+			rowData[0] = value.Interface()
+		} else {
+			for idx, field := range fields {
+				rowData[idx] = value.Field(field).Interface()
+			}
+		}
+		into.Absorb(rowData...)
+	}
+}
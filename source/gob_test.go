@@ -0,0 +1,62 @@
+package source_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"reflect"
+	"testing"
+
+	"github.com/jyopp/absorb"
+	"github.com/jyopp/absorb/source"
+)
+
+type gobRecord struct {
+	Name  string
+	Count int
+}
+
+func encodeGob(t *testing.T, values ...interface{}) *bytes.Buffer {
+	var buf bytes.Buffer
+	encoder := gob.NewEncoder(&buf)
+	for _, value := range values {
+		if err := encoder.Encode(value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return &buf
+}
+
+func TestGobFields(t *testing.T) {
+	buf := encodeGob(t, gobRecord{"a", 1}, gobRecord{"b", 2})
+
+	var dst []map[string]interface{}
+	if err := absorb.Absorb(&dst, source.Gob(buf, gobRecord{})); err != nil {
+		t.Fatal(err)
+	}
+	expect := []map[string]interface{}{{"Name": "a", "Count": 1}, {"Name": "b", "Count": 2}}
+	if !reflect.DeepEqual(dst, expect) {
+		t.Fatalf("Expected %+v, got %+v", expect, dst)
+	}
+}
+
+func TestGobWhole(t *testing.T) {
+	buf := encodeGob(t, gobRecord{"a", 1}, gobRecord{"b", 2})
+
+	var dst []*gobRecord
+	src := source.Gob(buf, &gobRecord{})
+	src.Whole = true
+	if err := absorb.Absorb(&dst, src); err != nil {
+		t.Fatal(err)
+	}
+	if len(dst) != 2 || *dst[1] != (gobRecord{"b", 2}) {
+		t.Fatalf("Unexpected records %+v", dst)
+	}
+
+	var ints []int
+	if err := absorb.Absorb(&ints, source.Gob(encodeGob(t, 3, 4, 5), 0)); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ints, []int{3, 4, 5}) {
+		t.Fatalf("Unexpected ints %v", ints)
+	}
+}
@@ -0,0 +1,74 @@
+package source
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jyopp/absorb"
+)
+
+// INISource emits each section of an INI file as a row. The first key is "section",
+// holding the section's name, followed by the union of the sections' keys, in the order
+// they first appear, in tag namespace "ini"; a section without one of them emits nil.
+// Keys set before the first section header form a section named "", which is emitted
+// only if it has keys.
+//
+// Keys and values are separated by "=" or ":", and values are strings with surrounding
+// spaces and quotes removed. Lines starting with ";" or "#" are comments.
+type INISource struct {
+	r io.Reader
+}
+
+// INI creates a source that reads sections from r.
+func INI(r io.Reader) *INISource {
+	return &INISource{r: r}
+}
+
+// Emit implements absorb.Absorbable
+func (s *INISource) Emit(into absorb.Absorber) error {
+	var records records
+	records.addKey("section")
+	records.add()
+	records.rows[0]["section"] = ""
+	global := true
+
+	scanner := bufio.NewScanner(s.r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == ';' || line[0] == '#' {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return fmt.Errorf("line %d: malformed section header %q", lineNum, line)
+			}
+			if global && len(records.rows[0]) == 1 {
+				// The global section has no keys.
+				records.rows = records.rows[:0]
+			}
+			global = false
+			records.add()
+			records.set("section", strings.TrimSpace(line[1:len(line)-1]))
+			continue
+		}
+		sep := strings.IndexAny(line, "=:")
+		if sep <= 0 {
+			return fmt.Errorf("line %d: expected key = value, got %q", lineNum, line)
+		}
+		value := strings.TrimSpace(line[sep+1:])
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		records.set(strings.TrimSpace(line[:sep]), value)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if global && len(records.rows[0]) == 1 {
+		records.rows = nil
+	}
+	records.emit(into, "ini")
+	return nil
+}
@@ -0,0 +1,121 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/jyopp/absorb"
+)
+
+// KafkaMessage is a message fetched from a Kafka topic.
+type KafkaMessage struct {
+	Topic     string
+	Partition int
+	Offset    int64
+	Key       []byte
+	Value     []byte
+	Time      time.Time
+}
+
+// KafkaConsumer is the subset of a Kafka consumer used by KafkaSource.
+// A few lines can adapt segmentio/kafka-go, confluent-kafka-go, or sarama to this interface.
+type KafkaConsumer interface {
+	// FetchMessage blocks until a message is available or ctx is done.
+	FetchMessage(ctx context.Context) (KafkaMessage, error)
+	// CommitMessages commits the offsets of msgs for the consumer group.
+	CommitMessages(ctx context.Context, msgs ...KafkaMessage) error
+}
+
+// KafkaDecoder decodes a message payload into a record, keyed by field name.
+type KafkaDecoder func(payload []byte) (map[string]interface{}, error)
+
+// JSONDecoder decodes JSON object payloads. It is the default KafkaDecoder.
+func JSONDecoder(payload []byte) (map[string]interface{}, error) {
+	var record map[string]interface{}
+	err := json.Unmarshal(payload, &record)
+	return record, err
+}
+
+// KafkaSource continuously emits decoded messages from a Kafka consumer, in tag namespace
+// "kafka". It is intended for channel destinations, and runs until its context is done.
+//
+// Each message is committed only after Absorb returns for it, so with a channel
+// destination, offsets are committed once the row has been handed to a receiver.
+type KafkaSource struct {
+	ctx      context.Context
+	consumer KafkaConsumer
+	// Keys are passed to Open. If empty, the sorted fields of the first record are used,
+	// and fields that only appear in later records are ignored.
+	Keys []string
+	// Decode converts payloads to records. Defaults to JSONDecoder; Avro and other
+	// formats can be supported by providing a decoder backed by their own library.
+	Decode KafkaDecoder
+	// Absorbed is called after each message is absorbed. Defaults to committing the
+	// message with the consumer. Returning an error stops the source.
+	Absorbed func(ctx context.Context, msg KafkaMessage) error
+}
+
+// Kafka creates a source that emits messages fetched by consumer until ctx is done.
+func Kafka(ctx context.Context, consumer KafkaConsumer) *KafkaSource {
+	return &KafkaSource{ctx: ctx, consumer: consumer}
+}
+
+// Emit implements absorb.Absorbable
+//
+// Returns nil when the source's context is done, or the first error fetching,
+// decoding, or committing a message.
+func (s *KafkaSource) Emit(into absorb.Absorber) error {
+	decode, absorbed := s.Decode, s.Absorbed
+	if decode == nil {
+		decode = JSONDecoder
+	}
+	if absorbed == nil {
+		absorbed = func(ctx context.Context, msg KafkaMessage) error {
+			return s.consumer.CommitMessages(ctx, msg)
+		}
+	}
+
+	msg, record, err := s.next(decode)
+	if err != nil || record == nil {
+		return err
+	}
+	keys := s.Keys
+	if len(keys) == 0 {
+		for key := range record {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+	}
+
+	into.Open("kafka", -1, keys...)
+	defer into.Close()
+
+	rowData := make([]interface{}, len(keys))
+	for ; record != nil; msg, record, err = s.next(decode) {
+		for idx, key := range keys {
+			rowData[idx] = record[key]
+		}
+		into.Absorb(rowData...)
+		if err = absorbed(s.ctx, msg); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// next fetches and decodes the next message. Returns a nil record once the context is done.
+func (s *KafkaSource) next(decode KafkaDecoder) (KafkaMessage, map[string]interface{}, error) {
+	msg, err := s.consumer.FetchMessage(s.ctx)
+	if s.ctx.Err() != nil {
+		return msg, nil, nil
+	} else if err != nil {
+		return msg, nil, err
+	}
+	record, err := decode(msg.Value)
+	if err == nil && record == nil {
+		record = map[string]interface{}{}
+	}
+	return msg, record, err
+}
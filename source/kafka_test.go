@@ -0,0 +1,70 @@
+package source_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jyopp/absorb"
+	"github.com/jyopp/absorb/source"
+)
+
+// fakeConsumer serves messages from a channel and records commits.
+type fakeConsumer struct {
+	messages  chan source.KafkaMessage
+	committed []int64
+}
+
+func (c *fakeConsumer) FetchMessage(ctx context.Context) (source.KafkaMessage, error) {
+	select {
+	case msg := <-c.messages:
+		return msg, nil
+	case <-ctx.Done():
+		return source.KafkaMessage{}, ctx.Err()
+	}
+}
+
+func (c *fakeConsumer) CommitMessages(ctx context.Context, msgs ...source.KafkaMessage) error {
+	for _, msg := range msgs {
+		c.committed = append(c.committed, msg.Offset)
+	}
+	return nil
+}
+
+func TestKafka(t *testing.T) {
+	type Event struct {
+		User  string
+		Count int
+	}
+
+	consumer := &fakeConsumer{messages: make(chan source.KafkaMessage, 3)}
+	for offset, payload := range []string{
+		`{"user": "picard", "count": 1}`,
+		`{"user": "riker", "count": 2}`,
+		`{"user": "troi", "count": 3}`,
+	} {
+		consumer.messages <- source.KafkaMessage{Offset: int64(offset), Value: []byte(payload)}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := make(chan Event)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(ch)
+		errs <- absorb.Absorb(ch, source.Kafka(ctx, consumer))
+	}()
+
+	for idx, user := range []string{"picard", "riker", "troi"} {
+		if event := <-ch; event.User != user || event.Count != idx+1 {
+			t.Fatalf("Unexpected event %+v", event)
+		}
+	}
+	cancel()
+	for range ch {
+	}
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+	if len(consumer.committed) != 3 || consumer.committed[2] != 2 {
+		t.Fatalf("Expected all three offsets to be committed, got %v", consumer.committed)
+	}
+}
@@ -0,0 +1,35 @@
+package source
+
+import (
+	"bufio"
+	"io"
+
+	"github.com/jyopp/absorb"
+)
+
+// LineSource emits each line of plain text, such as a log or a word list, as a row with a
+// single string value under the key "line", in tag namespace "lines". Line endings are
+// removed, and blank lines are emitted as empty strings.
+//
+// A single key lets lines be absorbed into a []string, a chan string, or a struct with
+// a Line field, without writing an adapter.
+type LineSource struct {
+	r io.Reader
+}
+
+// Lines creates a source that reads lines from r.
+func Lines(r io.Reader) *LineSource {
+	return &LineSource{r: r}
+}
+
+// Emit implements absorb.Absorbable
+func (s *LineSource) Emit(into absorb.Absorber) error {
+	into.Open("lines", -1, "line")
+	defer into.Close()
+
+	scanner := bufio.NewScanner(s.r)
+	for scanner.Scan() {
+		into.Absorb(scanner.Text())
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,29 @@
+package source_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jyopp/absorb"
+	"github.com/jyopp/absorb/source"
+)
+
+func TestLines(t *testing.T) {
+	const text = "alpha\r\nbeta\n\ngamma"
+
+	var words []string
+	if err := absorb.Absorb(&words, source.Lines(strings.NewReader(text))); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(words, ",") != "alpha,beta,,gamma" {
+		t.Fatalf("Unexpected lines %q", words)
+	}
+
+	var entries []struct{ Line string }
+	if err := absorb.Absorb(&entries, source.Lines(strings.NewReader(text))); err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 4 || entries[3].Line != "gamma" {
+		t.Fatalf("Unexpected entries %+v", entries)
+	}
+}
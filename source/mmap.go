@@ -0,0 +1,132 @@
+package source
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/jyopp/absorb"
+)
+
+// MappedSource emits the rows of a large delimited file, such as a CSV or TSV export.
+// The file is memory-mapped where the platform supports it, and read whole otherwise.
+// The first line holds the keys, in tag namespace "csv", and each following non-blank
+// line is a row. Fields are split on the delimiter alone: quoting is not supported, so
+// fields must not contain the delimiter or line breaks.
+//
+// Emit copies each row's fields into a single new buffer, and emits them as []byte
+// slices of it, so absorbed values remain valid after the file is unmapped. Each avoids
+// even that copy, for callers that are done with each row before the next.
+type MappedSource struct {
+	path  string
+	delim byte
+}
+
+// Mapped creates a source that reads the file at path, with fields separated by delim.
+func Mapped(path string, delim byte) *MappedSource {
+	return &MappedSource{path: path, delim: delim}
+}
+
+// Emit implements absorb.Absorbable
+func (s *MappedSource) Emit(into absorb.Absorber) error {
+	open := false
+	defer func() {
+		if open {
+			into.Close()
+		}
+	}()
+	var rowData []interface{}
+	return s.scan(func(keys []string) {
+		into.Open("csv", -1, keys...)
+		open = true
+		rowData = make([]interface{}, len(keys))
+	}, func(row [][]byte) error {
+		size := 0
+		for _, field := range row {
+			size += len(field)
+		}
+		buf := make([]byte, 0, size)
+		rowData = rowData[:0]
+		for _, field := range row {
+			start := len(buf)
+			buf = append(buf, field...)
+			rowData = append(rowData, buf[start:len(buf):len(buf)])
+		}
+		into.Absorb(rowData...)
+		return nil
+	})
+}
+
+// Each calls fn with the keys and the fields of each row, without copying them. Fields
+// are slices of the mapped file, and are valid only until fn returns: Reading them later,
+// even indirectly through a value absorbed from them, may crash the program. Stops at the
+// first error returned by fn, and returns it.
+func (s *MappedSource) Each(fn func(keys []string, row [][]byte) error) error {
+	var keys []string
+	return s.scan(func(k []string) {
+		keys = k
+	}, func(row [][]byte) error {
+		return fn(keys, row)
+	})
+}
+
+// scan maps the file, and calls header with its keys, then row with the fields of each
+// row, which are only valid until row returns. The file is unmapped before scan returns.
+func (s *MappedSource) scan(header func(keys []string), row func(fields [][]byte) error) (err error) {
+	data, unmap, err := mapFile(s.path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if unmapErr := unmap(); err == nil {
+			err = unmapErr
+		}
+	}()
+
+	line, data := nextLine(data)
+	if line == nil {
+		return fmt.Errorf("%s: missing header line", s.path)
+	}
+	fields := bytes.Split(line, []byte{s.delim})
+	keys := make([]string, len(fields))
+	for idx, field := range fields {
+		keys[idx] = string(field)
+	}
+	header(keys)
+
+	for len(data) > 0 {
+		line, data = nextLine(data)
+		if len(line) == 0 {
+			continue
+		}
+		fields = fields[:0]
+		for {
+			end := bytes.IndexByte(line, s.delim)
+			if end < 0 {
+				fields = append(fields, line)
+				break
+			}
+			fields = append(fields, line[:end:end])
+			line = line[end+1:]
+		}
+		if err := row(fields); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nextLine returns the first line of data, without its line ending, and the rest of data.
+// Returns a nil line if data is empty.
+func nextLine(data []byte) (line, rest []byte) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	end := bytes.IndexByte(data, '\n')
+	if end < 0 {
+		line, rest = data, nil
+	} else {
+		line, rest = data[:end], data[end+1:]
+	}
+	line = bytes.TrimSuffix(line, []byte{'\r'})
+	return line[:len(line):len(line)], rest
+}
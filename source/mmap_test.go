@@ -0,0 +1,68 @@
+package source_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/jyopp/absorb"
+	"github.com/jyopp/absorb/source"
+)
+
+func TestMapped(t *testing.T) {
+	type Record struct {
+		ID      string `csv:"id"`
+		Name    string `csv:"name"`
+		Payload []byte `csv:"payload"`
+	}
+
+	path := filepath.Join(t.TempDir(), "records.tsv")
+	data := "id\tname\tpayload\r\n1\tPicard\tabc\r\n\n2\tRiker\t\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var dst []Record
+	if err := absorb.Absorb(&dst, source.Mapped(path, '\t')); err != nil {
+		t.Fatal(err)
+	}
+	if len(dst) != 2 || dst[0].ID != "1" || dst[0].Name != "Picard" || string(dst[0].Payload) != "abc" {
+		t.Fatalf("Unexpected records %+v", dst)
+	}
+	if dst[1].Name != "Riker" || len(dst[1].Payload) != 0 {
+		t.Fatalf("Unexpected record %+v", dst[1])
+	}
+
+	// Values absorbed as []byte remain valid once the file is unmapped.
+	var rows []map[string]interface{}
+	if err := absorb.Absorb(&rows, source.Mapped(path, '\t')); err != nil {
+		t.Fatal(err)
+	}
+	if name, _ := rows[0]["name"].([]byte); string(name) != "Picard" {
+		t.Fatalf("Unexpected rows %v", rows)
+	}
+
+	var names []string
+	err := source.Mapped(path, '\t').Each(func(keys []string, row [][]byte) error {
+		if keys[1] != "name" {
+			t.Fatalf("Unexpected keys %q", keys)
+		}
+		names = append(names, string(row[1]))
+		return nil
+	})
+	if err != nil || !reflect.DeepEqual(names, []string{"Picard", "Riker"}) {
+		t.Fatalf("Unexpected names %q (err %v)", names, err)
+	}
+
+	empty := filepath.Join(t.TempDir(), "empty.tsv")
+	if err := os.WriteFile(empty, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := absorb.Absorb(&dst, source.Mapped(empty, '\t')); err == nil {
+		t.Fatal("Expected an error for a file without a header")
+	}
+	if err := absorb.Absorb(&dst, source.Mapped(filepath.Join(t.TempDir(), "missing"), '\t')); !os.IsNotExist(err) {
+		t.Fatalf("Expected a missing file error, got %v", err)
+	}
+}
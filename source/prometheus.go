@@ -0,0 +1,221 @@
+package source
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jyopp/absorb"
+)
+
+// PrometheusSource emits one row per sample of metrics in the Prometheus text exposition
+// format, as served on a /metrics endpoint. Keys are in tag namespace "prometheus":
+//
+//	name       string     metric name, such as "http_requests_total"
+//	type       string     metric type from its TYPE line, such as "counter", or nil
+//	value      float64    sample value
+//	timestamp  time.Time  sample timestamp, or nil if the sample has none
+//
+// followed by one key for each label name found in the input, in sorted order, whose
+// values are strings, or nil for samples without the label. A label named like one of
+// the keys above is emitted with the prefix "label_". Samples of histograms and summaries,
+// such as "rpc_seconds_bucket", have the type of their metric family.
+//
+// The input is read whole before any row is emitted, to find every label name.
+type PrometheusSource struct {
+	r io.Reader
+}
+
+// Prometheus creates a source that reads the text exposition format from r.
+func Prometheus(r io.Reader) *PrometheusSource {
+	return &PrometheusSource{r: r}
+}
+
+// ScrapePrometheus fetches url with client, or http.DefaultClient if nil, and emits the
+// metrics it serves into into.
+func ScrapePrometheus(ctx context.Context, client *http.Client, url string, into absorb.Absorber) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/plain;version=0.0.4")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("scraping %s: %s", url, resp.Status)
+	}
+	return Prometheus(resp.Body).Emit(into)
+}
+
+// promSample is a sample parsed from the exposition format.
+type promSample struct {
+	name      string
+	labels    map[string]string
+	value     float64
+	timestamp interface{}
+}
+
+var promKeys = []string{"name", "type", "value", "timestamp"}
+
+// Emit implements absorb.Absorbable
+func (s *PrometheusSource) Emit(into absorb.Absorber) error {
+	var samples []promSample
+	types := make(map[string]string)
+	labelNames := make(map[string]bool)
+
+	scanner := bufio.NewScanner(s.r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			if fields := strings.Fields(line); len(fields) == 4 && fields[1] == "TYPE" {
+				types[fields[2]] = fields[3]
+			}
+			continue
+		}
+		sample, err := parsePromSample(line)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		for name := range sample.labels {
+			labelNames[name] = true
+		}
+		samples = append(samples, sample)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	labels := make([]string, 0, len(labelNames))
+	for name := range labelNames {
+		labels = append(labels, name)
+	}
+	sort.Strings(labels)
+	keys := append([]string(nil), promKeys...)
+	for _, name := range labels {
+		for _, key := range promKeys {
+			if name == key {
+				name = "label_" + name
+				break
+			}
+		}
+		keys = append(keys, name)
+	}
+
+	into.Open("prometheus", len(samples), keys...)
+	defer into.Close()
+
+	rowData := make([]interface{}, len(keys))
+	for _, sample := range samples {
+		rowData[0] = sample.name
+		rowData[1] = promType(types, sample.name)
+		rowData[2] = sample.value
+		rowData[3] = sample.timestamp
+		for idx, name := range labels {
+			if value, ok := sample.labels[name]; ok {
+				rowData[len(promKeys)+idx] = value
+			} else {
+				rowData[len(promKeys)+idx] = nil
+			}
+		}
+		into.Absorb(rowData...)
+	}
+	return nil
+}
+
+// promType returns the type of the metric family of name, or nil if it has no TYPE line.
+func promType(types map[string]string, name string) interface{} {
+	if t, ok := types[name]; ok {
+		return t
+	}
+	for _, suffix := range []string{"_bucket", "_sum", "_count"} {
+		if family := strings.TrimSuffix(name, suffix); family != name {
+			if t, ok := types[family]; ok && (t == "histogram" || t == "summary") {
+				return t
+			}
+		}
+	}
+	return nil
+}
+
+// parsePromSample parses a sample line, such as `http_requests_total{code="200"} 1027`.
+func parsePromSample(line string) (promSample, error) {
+	sample := promSample{}
+	end := strings.IndexAny(line, "{ \t")
+	if end <= 0 {
+		return sample, fmt.Errorf("malformed sample %q", line)
+	}
+	sample.name, line = line[:end], line[end:]
+	if strings.HasPrefix(line, "{") {
+		var err error
+		if sample.labels, line, err = parsePromLabels(line[1:]); err != nil {
+			return sample, err
+		}
+	}
+	fields := strings.Fields(line)
+	if len(fields) != 1 && len(fields) != 2 {
+		return sample, fmt.Errorf("malformed sample value %q", line)
+	}
+	value, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return sample, err
+	}
+	sample.value = value
+	if len(fields) == 2 {
+		ms, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return sample, err
+		}
+		sample.timestamp = time.UnixMilli(ms).UTC()
+	}
+	return sample, nil
+}
+
+// parsePromLabels parses the labels that follow an opening brace, and returns the text
+// after the closing brace.
+func parsePromLabels(text string) (map[string]string, string, error) {
+	labels := make(map[string]string)
+	for {
+		text = strings.TrimLeft(text, " \t,")
+		if strings.HasPrefix(text, "}") {
+			return labels, text[1:], nil
+		}
+		eq := strings.IndexByte(text, '=')
+		if eq <= 0 || len(text) < eq+2 || text[eq+1] != '"' {
+			return nil, "", errors.New("malformed labels")
+		}
+		name := strings.TrimSpace(text[:eq])
+		var value strings.Builder
+		i := eq + 2
+		for ; i < len(text) && text[i] != '"'; i++ {
+			if text[i] == '\\' && i+1 < len(text) {
+				i++
+				if text[i] == 'n' {
+					value.WriteByte('\n')
+					continue
+				}
+			}
+			value.WriteByte(text[i])
+		}
+		if i == len(text) {
+			return nil, "", errors.New("unterminated label value")
+		}
+		labels[name] = value.String()
+		text = text[i+1:]
+	}
+}
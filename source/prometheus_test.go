@@ -0,0 +1,76 @@
+package source_test
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jyopp/absorb"
+	"github.com/jyopp/absorb/source"
+)
+
+const metrics = `# HELP http_requests_total The total number of HTTP requests.
+# TYPE http_requests_total counter
+http_requests_total{method="post",code="200"} 1027 1395066363000
+http_requests_total{method="post",code="400"}    3 1395066363000
+
+# TYPE rpc_duration_seconds histogram
+rpc_duration_seconds_bucket{le="+Inf"} 144320
+rpc_duration_seconds_count 144320
+go_goroutines{path="C:\\DIR\\",msg="say \"hi\""} NaN
+`
+
+type sample struct {
+	Name   string     `prometheus:"name"`
+	Type   *string    `prometheus:"type"`
+	Value  float64    `prometheus:"value"`
+	Time   *time.Time `prometheus:"timestamp"`
+	Code   string     `prometheus:"code"`
+	Method string     `prometheus:"method"`
+	Path   string     `prometheus:"path"`
+	Msg    string     `prometheus:"msg"`
+}
+
+func TestPrometheus(t *testing.T) {
+	var dst []sample
+	if err := absorb.Absorb(&dst, source.Prometheus(strings.NewReader(metrics))); err != nil {
+		t.Fatal(err)
+	}
+	if len(dst) != 5 {
+		t.Fatalf("Expected 5 samples, got %+v", dst)
+	}
+	first := dst[0]
+	if first.Name != "http_requests_total" || *first.Type != "counter" || first.Value != 1027 ||
+		first.Code != "200" || first.Method != "post" || !first.Time.Equal(time.UnixMilli(1395066363000)) {
+		t.Fatalf("Unexpected first sample %+v", first)
+	}
+	if dst[1].Value != 3 || dst[2].Time != nil || *dst[2].Type != "histogram" || *dst[3].Type != "histogram" {
+		t.Fatalf("Unexpected samples %+v", dst[1:4])
+	}
+	if last := dst[4]; last.Type != nil || !math.IsNaN(last.Value) || last.Path != `C:\DIR\` || last.Msg != `say "hi"` || last.Code != "" {
+		t.Fatalf("Unexpected last sample %+v", last)
+	}
+
+	if err := absorb.Absorb(&dst, source.Prometheus(strings.NewReader("metric{a=1} 2\n"))); err == nil {
+		t.Fatal("Expected an error for unquoted label values")
+	}
+}
+
+func TestScrapePrometheus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(metrics))
+	}))
+	defer server.Close()
+
+	var dst []sample
+	if err := source.ScrapePrometheus(context.Background(), nil, server.URL, absorb.New(&dst)); err != nil {
+		t.Fatal(err)
+	}
+	if len(dst) != 5 {
+		t.Fatalf("Expected 5 samples, got %d", len(dst))
+	}
+}
@@ -0,0 +1,146 @@
+package source
+
+import (
+	"context"
+	"sort"
+
+	"github.com/jyopp/absorb"
+)
+
+// RedisClient is the subset of a Redis client used by RedisSource.
+// A few lines can adapt go-redis, redigo, or any other client to this interface.
+type RedisClient interface {
+	// HGetAll returns every field and value of the hash stored at key.
+	HGetAll(ctx context.Context, key string) (map[string]string, error)
+	// Scan returns a page of keys matching pattern, starting from cursor, along with
+	// the cursor of the next page. A returned cursor of 0 ends the iteration.
+	Scan(ctx context.Context, cursor uint64, match string, count int64) ([]string, uint64, error)
+}
+
+// RedisSource emits Redis hashes as rows, keyed by hash field name in tag namespace "redis".
+// Field values are emitted as strings, and fields missing from a hash are nil.
+type RedisSource struct {
+	ctx     context.Context
+	client  RedisClient
+	keys    []string
+	pattern string
+	// Fields are passed to Open. If empty, the sorted fields of the first hash are used,
+	// and fields that only appear in later hashes are ignored.
+	Fields []string
+	// KeyField, if set, adds a key with this name whose value is each hash's Redis key.
+	KeyField string
+	// ScanCount is the COUNT hint passed to each SCAN call. Defaults to 100.
+	ScanCount int64
+}
+
+// RedisHashes creates a source that emits the hashes stored at the given keys, in order.
+func RedisHashes(ctx context.Context, client RedisClient, keys ...string) *RedisSource {
+	return &RedisSource{ctx: ctx, client: client, keys: keys}
+}
+
+// RedisScan creates a source that emits every hash whose key matches pattern, in SCAN order.
+func RedisScan(ctx context.Context, client RedisClient, pattern string) *RedisSource {
+	return &RedisSource{ctx: ctx, client: client, pattern: pattern}
+}
+
+// Emit implements absorb.Absorbable
+func (s *RedisSource) Emit(into absorb.Absorber) error {
+	next := s.keyIterator()
+
+	// Fetch the first hash before opening, in case its fields are needed as keys.
+	key, hash, err := s.nextHash(next)
+	if err != nil {
+		return err
+	}
+	fields := s.Fields
+	if len(fields) == 0 {
+		for field := range hash {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+	}
+	keys := fields
+	if s.KeyField != "" {
+		keys = append([]string{s.KeyField}, fields...)
+	}
+
+	count := -1
+	if s.pattern == "" {
+		count = len(s.keys)
+	}
+	into.Open("redis", count, keys...)
+	defer into.Close()
+
+	rowData := make([]interface{}, len(keys))
+	for ; hash != nil; key, hash, err = s.nextHash(next) {
+		row := rowData
+		if s.KeyField != "" {
+			rowData[0] = key
+			row = rowData[1:]
+		}
+		for idx, field := range fields {
+			if value, ok := hash[field]; ok {
+				row[idx] = value
+			} else {
+				row[idx] = nil
+			}
+		}
+		into.Absorb(rowData...)
+	}
+	return err
+}
+
+// nextHash returns the next key and its hash, or a nil hash when there are no more keys.
+func (s *RedisSource) nextHash(next func() (string, bool, error)) (string, map[string]string, error) {
+	key, ok, err := next()
+	if !ok || err != nil {
+		return "", nil, err
+	}
+	hash, err := s.client.HGetAll(s.ctx, key)
+	if err != nil {
+		return "", nil, err
+	}
+	if hash == nil {
+		hash = map[string]string{}
+	}
+	return key, hash, nil
+}
+
+// keyIterator returns a function yielding each key to emit, then false when done.
+func (s *RedisSource) keyIterator() func() (string, bool, error) {
+	if s.pattern == "" {
+		keys := s.keys
+		return func() (string, bool, error) {
+			if len(keys) == 0 {
+				return "", false, nil
+			}
+			key := keys[0]
+			keys = keys[1:]
+			return key, true, nil
+		}
+	}
+
+	count := s.ScanCount
+	if count <= 0 {
+		count = 100
+	}
+	var page []string
+	var cursor uint64
+	started := false
+	return func() (string, bool, error) {
+		for len(page) == 0 {
+			if started && cursor == 0 {
+				return "", false, nil
+			}
+			var err error
+			page, cursor, err = s.client.Scan(s.ctx, cursor, s.pattern, count)
+			if err != nil {
+				return "", false, err
+			}
+			started = true
+		}
+		key := page[0]
+		page = page[1:]
+		return key, true, nil
+	}
+}
@@ -0,0 +1,78 @@
+package source_test
+
+import (
+	"context"
+	"path"
+	"sort"
+	"testing"
+
+	"github.com/jyopp/absorb"
+	"github.com/jyopp/absorb/source"
+)
+
+// fakeRedis implements source.RedisClient, returning one key per SCAN page.
+type fakeRedis map[string]map[string]string
+
+func (r fakeRedis) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	return r[key], nil
+}
+
+func (r fakeRedis) Scan(ctx context.Context, cursor uint64, match string, count int64) ([]string, uint64, error) {
+	var keys []string
+	for key := range r {
+		if ok, _ := path.Match(match, key); ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	if int(cursor) >= len(keys) {
+		return nil, 0, nil
+	}
+	page, next := keys[cursor:cursor+1], cursor+1
+	if int(next) == len(keys) {
+		next = 0
+	}
+	return page, next, nil
+}
+
+var testRedis = fakeRedis{
+	"user:1":  {"name": "Picard", "rank": "Captain"},
+	"user:2":  {"name": "Riker"},
+	"user:3":  {"name": "Troi", "rank": "Commander"},
+	"other:1": {"name": "Skipped"},
+}
+
+type redisUser struct {
+	Key  string
+	Name string
+	Rank *string
+}
+
+func TestRedisHashes(t *testing.T) {
+	var dst []redisUser
+	src := source.RedisHashes(context.Background(), testRedis, "user:2", "user:1")
+	src.Fields = []string{"name", "rank"}
+	if err := absorb.Absorb(&dst, src); err != nil {
+		t.Fatal(err)
+	}
+	if len(dst) != 2 || dst[0].Name != "Riker" || dst[0].Rank != nil || *dst[1].Rank != "Captain" {
+		t.Fatalf("Unexpected users %+v", dst)
+	}
+}
+
+func TestRedisScan(t *testing.T) {
+	var dst []redisUser
+	src := source.RedisScan(context.Background(), testRedis, "user:*")
+	src.KeyField = "key"
+	if err := absorb.Absorb(&dst, src); err != nil {
+		t.Fatal(err)
+	}
+	if len(dst) != 3 {
+		t.Fatalf("Expected 3 users, got %+v", dst)
+	}
+	for idx, name := range []string{"Picard", "Riker", "Troi"} {
+		if dst[idx].Name != name || dst[idx].Key == "" {
+			t.Fatalf("Unexpected user %+v at %d", dst[idx], idx)
+		}
+	}
+}
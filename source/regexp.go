@@ -0,0 +1,75 @@
+package source
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"regexp"
+
+	"github.com/jyopp/absorb"
+)
+
+// RegexpSource emits a row for each line of text that matches a regular expression, such
+// as a structured log line. The expression's named capture groups are the keys, in tag
+// namespace "regexp", and their submatches are the string values. Groups that do not
+// participate in a match are nil. Lines that do not match are skipped.
+//
+// Example:
+//
+//	re := regexp.MustCompile(`^(?P<level>\w+) (?P<time>\S+) (?P<msg>.*)$`)
+//	err := absorb.Absorb(&entries, source.Regexp(logFile, re))
+type RegexpSource struct {
+	r       io.Reader
+	re      *regexp.Regexp
+	skipped int
+}
+
+// Regexp creates a source that matches each line read from r against re, which must have
+// at least one named capture group.
+func Regexp(r io.Reader, re *regexp.Regexp) *RegexpSource {
+	return &RegexpSource{r: r, re: re}
+}
+
+// Skipped returns the number of lines that did not match during the last Emit.
+func (s *RegexpSource) Skipped() int {
+	return s.skipped
+}
+
+// Emit implements absorb.Absorbable
+func (s *RegexpSource) Emit(into absorb.Absorber) error {
+	var keys []string
+	var groups []int
+	for idx, name := range s.re.SubexpNames() {
+		if name != "" {
+			keys = append(keys, name)
+			groups = append(groups, idx)
+		}
+	}
+	if len(keys) == 0 {
+		return errors.New("regexp source: " + s.re.String() + " has no named capture groups")
+	}
+
+	into.Open("regexp", -1, keys...)
+	defer into.Close()
+
+	s.skipped = 0
+	rowData := make([]interface{}, len(keys))
+	scanner := bufio.NewScanner(s.r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		match := s.re.FindStringSubmatchIndex(line)
+		if match == nil {
+			s.skipped++
+			continue
+		}
+		for idx, group := range groups {
+			if start, end := match[2*group], match[2*group+1]; start >= 0 {
+				rowData[idx] = line[start:end]
+			} else {
+				rowData[idx] = nil
+			}
+		}
+		into.Absorb(rowData...)
+	}
+	return scanner.Err()
+}
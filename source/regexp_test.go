@@ -0,0 +1,45 @@
+package source_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/jyopp/absorb"
+	"github.com/jyopp/absorb/source"
+)
+
+func TestRegexp(t *testing.T) {
+	type Entry struct {
+		Level   string  `regexp:"level"`
+		Message string  `regexp:"msg"`
+		User    *string `regexp:"user"`
+	}
+	const log = "" +
+		"INFO started\n" +
+		"not a log line\n" +
+		"WARN disk low user=ann\n"
+
+	// Unnamed groups are not emitted.
+	re := regexp.MustCompile(`^(?P<level>[A-Z]+)( +)(?P<msg>.*?)(?: user=(?P<user>\w+))?$`)
+	src := source.Regexp(strings.NewReader(log), re)
+
+	var entries []Entry
+	if err := absorb.Absorb(&entries, src); err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || src.Skipped() != 1 {
+		t.Fatalf("Expected 2 entries and 1 skipped line, got %+v and %d", entries, src.Skipped())
+	}
+	if entries[0].Level != "INFO" || entries[0].Message != "started" || entries[0].User != nil {
+		t.Fatalf("Unexpected entry %+v", entries[0])
+	}
+	if entries[1].Message != "disk low" || entries[1].User == nil || *entries[1].User != "ann" {
+		t.Fatalf("Unexpected entry %+v", entries[1])
+	}
+
+	err := absorb.Absorb(&entries, source.Regexp(strings.NewReader(log), regexp.MustCompile(`(\w+)`)))
+	if err == nil {
+		t.Fatal("Expected an error without named groups")
+	}
+}
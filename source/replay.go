@@ -0,0 +1,436 @@
+package source
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jyopp/absorb"
+)
+
+// Recordings begin with a magic string and version, followed by one record per call to an
+// Absorber's Open, Absorb, or Close. Each value is written with a kind byte, so that it is
+// replayed with exactly the type it was emitted with.
+const replayMagic = "absorb-replay\x01"
+
+const (
+	opOpen byte = 'O' + iota
+	opRow
+	opClose
+)
+
+const (
+	kindNil byte = iota
+	kindBool
+	kindInt
+	kindInt8
+	kindInt16
+	kindInt32
+	kindInt64
+	kindUint
+	kindUint8
+	kindUint16
+	kindUint32
+	kindUint64
+	kindFloat32
+	kindFloat64
+	kindComplex64
+	kindComplex128
+	kindString
+	kindBytes
+	kindNilBytes
+	kindTime
+)
+
+// ErrBadRecording is returned when replaying data that was not written by Record.
+var ErrBadRecording = errors.New("source: invalid replay recording")
+
+// Record returns an Absorbable that emits the rows of src, while writing its keys and
+// rows to w. Replay emits the recorded rows again with identical values, so the results
+// of an expensive query can be captured once and reused in tests.
+//
+// Values must be nil, or of a basic kind (bool, string, numbers), []byte, or time.Time.
+// Times keep their offset from UTC, but not the name of their location.
+// Emit returns an error if a value cannot be recorded, or if writing to w fails; Rows are
+// still emitted to the Absorber.
+func Record(src absorb.Absorbable, w io.Writer) absorb.Absorbable {
+	return &recording{src: src, w: w}
+}
+
+type recording struct {
+	src absorb.Absorbable
+	w   io.Writer
+}
+
+func (r *recording) Emit(into absorb.Absorber) error {
+	rec := &recorder{next: into, w: bufio.NewWriter(r.w)}
+	rec.w.WriteString(replayMagic)
+	err := r.src.Emit(rec)
+	if err == nil {
+		err = rec.err
+	}
+	if flushErr := rec.w.Flush(); err == nil {
+		err = flushErr
+	}
+	return err
+}
+
+// recorder writes each call to an Absorber before passing it along.
+type recorder struct {
+	next absorb.Absorber
+	w    *bufio.Writer
+	buf  []byte
+	// err is the first error encountered while recording.
+	err error
+}
+
+func (r *recorder) Open(tag string, count int, keys ...string) {
+	r.buf = append(r.buf[:0], opOpen)
+	r.buf = appendString(r.buf, tag)
+	r.buf = appendVarint(r.buf, int64(count))
+	r.buf = appendUvarint(r.buf, uint64(len(keys)))
+	for _, key := range keys {
+		r.buf = appendString(r.buf, key)
+	}
+	r.write()
+	r.next.Open(tag, count, keys...)
+}
+
+func (r *recorder) Absorb(values ...interface{}) {
+	r.buf = append(r.buf[:0], opRow)
+	r.buf = appendUvarint(r.buf, uint64(len(values)))
+	for _, value := range values {
+		var err error
+		if r.buf, err = appendValue(r.buf, value); err != nil && r.err == nil {
+			r.err = err
+		}
+	}
+	r.write()
+	r.next.Absorb(values...)
+}
+
+func (r *recorder) Close() {
+	r.buf = append(r.buf[:0], opClose)
+	r.write()
+	r.next.Close()
+}
+
+func (r *recorder) write() {
+	if r.err == nil {
+		_, r.err = r.w.Write(r.buf)
+	}
+}
+
+// The binary.Append* functions require Go 1.19.
+func appendVarint(buf []byte, v int64) []byte {
+	var scratch [binary.MaxVarintLen64]byte
+	return append(buf, scratch[:binary.PutVarint(scratch[:], v)]...)
+}
+
+func appendUvarint(buf []byte, v uint64) []byte {
+	var scratch [binary.MaxVarintLen64]byte
+	return append(buf, scratch[:binary.PutUvarint(scratch[:], v)]...)
+}
+
+func appendUint32(buf []byte, v uint32) []byte {
+	var scratch [4]byte
+	binary.LittleEndian.PutUint32(scratch[:], v)
+	return append(buf, scratch[:]...)
+}
+
+func appendUint64(buf []byte, v uint64) []byte {
+	var scratch [8]byte
+	binary.LittleEndian.PutUint64(scratch[:], v)
+	return append(buf, scratch[:]...)
+}
+
+func appendString(buf []byte, s string) []byte {
+	buf = appendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+func appendValue(buf []byte, value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case nil:
+		return append(buf, kindNil), nil
+	case bool:
+		b := byte(0)
+		if v {
+			b = 1
+		}
+		return append(buf, kindBool, b), nil
+	case int:
+		return appendVarint(append(buf, kindInt), int64(v)), nil
+	case int8:
+		return appendVarint(append(buf, kindInt8), int64(v)), nil
+	case int16:
+		return appendVarint(append(buf, kindInt16), int64(v)), nil
+	case int32:
+		return appendVarint(append(buf, kindInt32), int64(v)), nil
+	case int64:
+		return appendVarint(append(buf, kindInt64), v), nil
+	case uint:
+		return appendUvarint(append(buf, kindUint), uint64(v)), nil
+	case uint8:
+		return appendUvarint(append(buf, kindUint8), uint64(v)), nil
+	case uint16:
+		return appendUvarint(append(buf, kindUint16), uint64(v)), nil
+	case uint32:
+		return appendUvarint(append(buf, kindUint32), uint64(v)), nil
+	case uint64:
+		return appendUvarint(append(buf, kindUint64), v), nil
+	case float32:
+		return appendUint32(append(buf, kindFloat32), math.Float32bits(v)), nil
+	case float64:
+		return appendUint64(append(buf, kindFloat64), math.Float64bits(v)), nil
+	case complex64:
+		buf = appendUint32(append(buf, kindComplex64), math.Float32bits(real(v)))
+		return appendUint32(buf, math.Float32bits(imag(v))), nil
+	case complex128:
+		buf = appendUint64(append(buf, kindComplex128), math.Float64bits(real(v)))
+		return appendUint64(buf, math.Float64bits(imag(v))), nil
+	case string:
+		return appendString(append(buf, kindString), v), nil
+	case []byte:
+		if v == nil {
+			return append(buf, kindNilBytes), nil
+		}
+		return appendString(append(buf, kindBytes), string(v)), nil
+	case time.Time:
+		data, err := v.MarshalBinary()
+		if err != nil {
+			return append(buf, kindNil), err
+		}
+		return appendString(append(buf, kindTime), string(data)), nil
+	}
+	return append(buf, kindNil), fmt.Errorf("source: cannot record value of type %T", value)
+}
+
+// ReplaySource emits rows recorded by Record.
+type ReplaySource struct {
+	r io.Reader
+}
+
+// Replay creates a source that emits the rows recorded by Record from r.
+func Replay(r io.Reader) *ReplaySource {
+	return &ReplaySource{r: r}
+}
+
+// Emit implements absorb.Absorbable
+func (s *ReplaySource) Emit(into absorb.Absorber) error {
+	r := bufio.NewReader(s.r)
+	magic := make([]byte, len(replayMagic))
+	if _, err := io.ReadFull(r, magic); err != nil || string(magic) != replayMagic {
+		return ErrBadRecording
+	}
+
+	var rowData []interface{}
+	open := false
+	defer func() {
+		// A truncated or corrupt recording must not leave into open.
+		if open {
+			into.Close()
+		}
+	}()
+	for {
+		op, err := r.ReadByte()
+		if err == io.EOF && !open {
+			return nil
+		} else if err != nil {
+			return badRecording(err)
+		}
+
+		switch op {
+		case opOpen:
+			tag, err := readString(r)
+			if err != nil {
+				return badRecording(err)
+			}
+			count, err := binary.ReadVarint(r)
+			if err != nil {
+				return badRecording(err)
+			}
+			n, err := binary.ReadUvarint(r)
+			if err != nil {
+				return badRecording(err)
+			}
+			// Keys are appended as they are read, since n is not trusted.
+			var keys []string
+			for i := uint64(0); i < n; i++ {
+				key, err := readString(r)
+				if err != nil {
+					return badRecording(err)
+				}
+				keys = append(keys, key)
+			}
+			into.Open(tag, int(count), keys...)
+			open = true
+		case opRow:
+			n, err := binary.ReadUvarint(r)
+			if err != nil {
+				return badRecording(err)
+			}
+			rowData = rowData[:0]
+			for i := uint64(0); i < n; i++ {
+				value, err := readValue(r)
+				if err != nil {
+					return badRecording(err)
+				}
+				rowData = append(rowData, value)
+			}
+			into.Absorb(rowData...)
+		case opClose:
+			open = false
+			into.Close()
+		default:
+			return ErrBadRecording
+		}
+	}
+}
+
+func badRecording(err error) error {
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return fmt.Errorf("%w: %v", ErrBadRecording, err)
+}
+
+// readString reads a length-prefixed string. The buffer only grows as input arrives, so
+// that a corrupt length cannot allocate more than the recording holds.
+func readString(r *bufio.Reader) (string, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return "", err
+	}
+	if n > math.MaxInt64 {
+		return "", io.ErrUnexpectedEOF
+	}
+	var b strings.Builder
+	if copied, err := io.CopyN(&b, r, int64(n)); uint64(copied) < n {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func readValue(r *bufio.Reader) (interface{}, error) {
+	kind, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	switch kind {
+	case kindNil:
+		return nil, nil
+	case kindBool:
+		b, err := r.ReadByte()
+		return b != 0, err
+	case kindInt, kindInt8, kindInt16, kindInt32, kindInt64:
+		v, err := binary.ReadVarint(r)
+		switch kind {
+		case kindInt:
+			return int(v), err
+		case kindInt8:
+			return int8(v), err
+		case kindInt16:
+			return int16(v), err
+		case kindInt32:
+			return int32(v), err
+		}
+		return v, err
+	case kindUint, kindUint8, kindUint16, kindUint32, kindUint64:
+		v, err := binary.ReadUvarint(r)
+		switch kind {
+		case kindUint:
+			return uint(v), err
+		case kindUint8:
+			return uint8(v), err
+		case kindUint16:
+			return uint16(v), err
+		case kindUint32:
+			return uint32(v), err
+		}
+		return v, err
+	case kindFloat32, kindComplex64:
+		var bits [2]uint32
+		n := 1
+		if kind == kindComplex64 {
+			n = 2
+		}
+		if err := binary.Read(r, binary.LittleEndian, bits[:n]); err != nil {
+			return nil, err
+		}
+		if kind == kindFloat32 {
+			return math.Float32frombits(bits[0]), nil
+		}
+		return complex(math.Float32frombits(bits[0]), math.Float32frombits(bits[1])), nil
+	case kindFloat64, kindComplex128:
+		var bits [2]uint64
+		n := 1
+		if kind == kindComplex128 {
+			n = 2
+		}
+		if err := binary.Read(r, binary.LittleEndian, bits[:n]); err != nil {
+			return nil, err
+		}
+		if kind == kindFloat64 {
+			return math.Float64frombits(bits[0]), nil
+		}
+		return complex(math.Float64frombits(bits[0]), math.Float64frombits(bits[1])), nil
+	case kindString:
+		return readString(r)
+	case kindBytes:
+		s, err := readString(r)
+		return []byte(s), err
+	case kindNilBytes:
+		return []byte(nil), nil
+	case kindTime:
+		s, err := readString(r)
+		if err != nil {
+			return nil, err
+		}
+		var t time.Time
+		err = t.UnmarshalBinary([]byte(s))
+		return t, err
+	}
+	return nil, fmt.Errorf("unknown value kind %d", kind)
+}
+
+// Cassette returns an Absorbable that replays the recording at path if it exists, or
+// otherwise emits src while recording it to path. Use it in tests and local development
+// to run an expensive source once, and replay identical rows afterward.
+func Cassette(path string, src absorb.Absorbable) absorb.Absorbable {
+	return &cassette{path: path, src: src}
+}
+
+type cassette struct {
+	path string
+	src  absorb.Absorbable
+}
+
+func (c *cassette) Emit(into absorb.Absorber) error {
+	if f, err := os.Open(c.path); err == nil {
+		defer f.Close()
+		return Replay(f).Emit(into)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	f, err := os.Create(c.path)
+	if err != nil {
+		return err
+	}
+	err = Record(c.src, f).Emit(into)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		// Don't leave a partial recording to be replayed.
+		os.Remove(c.path)
+	}
+	return err
+}
@@ -0,0 +1,119 @@
+package source_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jyopp/absorb"
+	"github.com/jyopp/absorb/source"
+)
+
+// rowsSource emits fixed rows, counting how many times it has been emitted.
+type rowsSource struct {
+	keys  []string
+	rows  [][]interface{}
+	emits *int
+}
+
+func (rs rowsSource) Emit(into absorb.Absorber) error {
+	*rs.emits++
+	into.Open("test", len(rs.rows), rs.keys...)
+	defer into.Close()
+	for _, row := range rs.rows {
+		into.Absorb(row...)
+	}
+	return nil
+}
+
+func TestRecordReplay(t *testing.T) {
+	when := time.Date(2024, 5, 6, 7, 8, 9, 10, time.UTC)
+	var emits int
+	src := rowsSource{
+		keys: []string{"a", "b", "c", "d"},
+		rows: [][]interface{}{
+			{nil, true, -3, int8(-4)},
+			{int16(5), int32(-6), int64(7), uint(8)},
+			{uint8(9), uint16(10), uint32(11), uint64(1 << 63)},
+			{float32(1.5), 2.25, complex64(1 + 2i), complex(3, -4)},
+			{"text", []byte("bytes"), []byte(nil), when},
+		},
+		emits: &emits,
+	}
+
+	var buf bytes.Buffer
+	var recorded, replayed []map[string]interface{}
+	if err := absorb.Absorb(&recorded, source.Record(src, &buf)); err != nil {
+		t.Fatal(err)
+	}
+	if err := absorb.Absorb(&replayed, source.Replay(&buf)); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(recorded, replayed) || len(replayed) != len(src.rows) {
+		t.Fatalf("Expected %+v, got %+v", recorded, replayed)
+	}
+
+	if err := absorb.Absorb(&replayed, source.Replay(bytes.NewBufferString("nonsense"))); !errors.Is(err, source.ErrBadRecording) {
+		t.Fatalf("Expected ErrBadRecording, got %v", err)
+	}
+}
+
+func TestReplayCorrupt(t *testing.T) {
+	var emits int
+	src := rowsSource{keys: []string{"a"}, rows: [][]interface{}{{"x"}, {"y"}}, emits: &emits}
+	var buf bytes.Buffer
+	var dst []map[string]interface{}
+	if err := absorb.Absorb(&dst, source.Record(src, &buf)); err != nil {
+		t.Fatal(err)
+	}
+	recording := buf.Bytes()
+
+	// A truncated recording closes the Absorber, so that it may be opened again.
+	into := absorb.New(&dst)
+	if err := source.Replay(bytes.NewReader(recording[:len(recording)-3])).Emit(into); !errors.Is(err, source.ErrBadRecording) {
+		t.Fatalf("Expected ErrBadRecording, got %v", err)
+	}
+	into.Open("test", -1, "a")
+	into.Close()
+
+	// A corrupt length fails without allocating it.
+	header := len("absorb-replay\x01") + 1
+	corrupt := append([]byte(nil), recording[:header]...)
+	var length [binary.MaxVarintLen64]byte
+	corrupt = append(corrupt, length[:binary.PutUvarint(length[:], 1<<62)]...)
+	if err := absorb.Absorb(&dst, source.Replay(bytes.NewReader(corrupt))); !errors.Is(err, source.ErrBadRecording) {
+		t.Fatalf("Expected ErrBadRecording, got %v", err)
+	}
+}
+
+func TestRecordUnsupported(t *testing.T) {
+	var emits int
+	src := rowsSource{keys: []string{"a"}, rows: [][]interface{}{{struct{}{}}}, emits: &emits}
+	var dst []map[string]interface{}
+	if err := absorb.Absorb(&dst, source.Record(src, &bytes.Buffer{})); err == nil {
+		t.Fatal("Expected an error recording an unsupported value")
+	}
+}
+
+func TestCassette(t *testing.T) {
+	var emits int
+	src := rowsSource{keys: []string{"Name", "Count"}, rows: [][]interface{}{{"a", 1}, {"b", 2}}, emits: &emits}
+	path := filepath.Join(t.TempDir(), "rows.replay")
+
+	for i := 0; i < 2; i++ {
+		var dst []gobRecord
+		if err := absorb.Absorb(&dst, source.Cassette(path, src)); err != nil {
+			t.Fatal(err)
+		}
+		if expect := []gobRecord{{"a", 1}, {"b", 2}}; !reflect.DeepEqual(dst, expect) {
+			t.Fatalf("Expected %+v, got %+v", expect, dst)
+		}
+	}
+	if emits != 1 {
+		t.Fatalf("Expected the source to be emitted once, got %d", emits)
+	}
+}
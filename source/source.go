@@ -0,0 +1,5 @@
+// Package source provides absorb.Absorbable adapters for common data formats.
+//
+// Every adapter depends only on the standard library. Adapters which need
+// third-party modules are provided in the repository's _example directory.
+package source
@@ -0,0 +1,251 @@
+package source
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jyopp/absorb"
+)
+
+// TOMLSource emits each table of a TOML array of tables, such as every [[server]] of a
+// config file, as a row. Keys are the union of the tables' keys, in the order they first
+// appear, in tag namespace "toml"; a table without one of them emits nil. Keys of
+// sub-tables, such as [server.limits], are prefixed with the sub-table's name and a dot,
+// as are dotted keys.
+//
+// Values are strings, int64, float64, bool, time.Time for offset and local date-times,
+// or []interface{} for arrays of these. Multi-line strings and inline tables are not
+// supported. Other tables in the document are skipped.
+type TOMLSource struct {
+	r     io.Reader
+	table string
+}
+
+// TOMLTables creates a source that emits each table of the array named table, such as
+// "server" for [[server]] or "fruit.variety" for [[fruit.variety]], from r.
+func TOMLTables(r io.Reader, table string) *TOMLSource {
+	return &TOMLSource{r: r, table: table}
+}
+
+// Emit implements absorb.Absorbable
+func (s *TOMLSource) Emit(into absorb.Absorber) error {
+	var records records
+	// prefix is the key prefix of the current sub-table, and inside is set while reading
+	// one of the source's tables.
+	var prefix string
+	inside := false
+
+	scanner := bufio.NewScanner(s.r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			header, array, err := tomlHeader(line)
+			if err != nil {
+				return fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			switch {
+			case array && header == s.table:
+				records.add()
+				inside, prefix = true, ""
+			case !array && inside && strings.HasPrefix(header, s.table+"."):
+				prefix = strings.TrimPrefix(header, s.table+".") + "."
+			default:
+				inside = false
+			}
+			continue
+		}
+		if !inside {
+			continue
+		}
+		key, value, err := tomlKeyValue(line)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		records.set(prefix+key, value)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	records.emit(into, "toml")
+	return nil
+}
+
+// records collects rows whose keys vary, to be emitted with the union of their keys.
+type records struct {
+	keys []string
+	rows []map[string]interface{}
+}
+
+func (r *records) add() {
+	r.rows = append(r.rows, make(map[string]interface{}))
+}
+
+// set sets key in the last row.
+func (r *records) set(key string, value interface{}) {
+	row := r.rows[len(r.rows)-1]
+	if _, ok := row[key]; !ok {
+		r.addKey(key)
+	}
+	row[key] = value
+}
+
+func (r *records) addKey(key string) {
+	for _, k := range r.keys {
+		if k == key {
+			return
+		}
+	}
+	r.keys = append(r.keys, key)
+}
+
+func (r *records) emit(into absorb.Absorber, tag string) {
+	into.Open(tag, len(r.rows), r.keys...)
+	defer into.Close()
+	rowData := make([]interface{}, len(r.keys))
+	for _, row := range r.rows {
+		for idx, key := range r.keys {
+			rowData[idx] = row[key]
+		}
+		into.Absorb(rowData...)
+	}
+}
+
+// tomlHeader parses a table header, such as "[server]" or "[[server]]", reporting whether
+// it names an array of tables.
+func tomlHeader(line string) (name string, array bool, err error) {
+	line = stripTOMLComment(line)
+	if array = strings.HasPrefix(line, "[["); array {
+		if !strings.HasSuffix(line, "]]") {
+			return "", false, fmt.Errorf("malformed table header %q", line)
+		}
+		line = line[2 : len(line)-2]
+	} else if strings.HasSuffix(line, "]") {
+		line = line[1 : len(line)-1]
+	} else {
+		return "", false, fmt.Errorf("malformed table header %q", line)
+	}
+	parts := strings.Split(line, ".")
+	for idx, part := range parts {
+		parts[idx] = strings.Trim(strings.TrimSpace(part), `"'`)
+	}
+	return strings.Join(parts, "."), array, nil
+}
+
+// tomlKeyValue parses a "key = value" line.
+func tomlKeyValue(line string) (string, interface{}, error) {
+	eq := strings.IndexByte(line, '=')
+	if eq <= 0 {
+		return "", nil, fmt.Errorf("expected key = value, got %q", line)
+	}
+	parts := strings.Split(line[:eq], ".")
+	for idx, part := range parts {
+		parts[idx] = strings.Trim(strings.TrimSpace(part), `"'`)
+	}
+	value, rest, err := tomlValue(strings.TrimSpace(line[eq+1:]))
+	if err != nil {
+		return "", nil, err
+	}
+	if rest = stripTOMLComment(rest); rest != "" {
+		return "", nil, fmt.Errorf("unexpected %q after value", rest)
+	}
+	return strings.Join(parts, "."), value, nil
+}
+
+// stripTOMLComment trims spaces and any comment from text outside of a string.
+func stripTOMLComment(text string) string {
+	if idx := strings.IndexByte(text, '#'); idx >= 0 {
+		text = text[:idx]
+	}
+	return strings.TrimSpace(text)
+}
+
+// tomlValue parses the value at the start of text, and returns the text after it.
+func tomlValue(text string) (interface{}, string, error) {
+	switch {
+	case text == "":
+		return nil, "", errors.New("missing value")
+	case strings.HasPrefix(text, `"""`) || strings.HasPrefix(text, "'''"):
+		return nil, "", errors.New("multi-line strings are not supported")
+	case text[0] == '"':
+		for i := 1; i < len(text); i++ {
+			switch text[i] {
+			case '\\':
+				i++
+			case '"':
+				s, err := strconv.Unquote(text[:i+1])
+				return s, text[i+1:], err
+			}
+		}
+		return nil, "", errors.New("unterminated string")
+	case text[0] == '\'':
+		end := strings.IndexByte(text[1:], '\'')
+		if end < 0 {
+			return nil, "", errors.New("unterminated string")
+		}
+		return text[1 : end+1], text[end+2:], nil
+	case text[0] == '[':
+		var values []interface{}
+		text = strings.TrimSpace(text[1:])
+		for !strings.HasPrefix(text, "]") {
+			value, rest, err := tomlValue(text)
+			if err != nil {
+				return nil, "", err
+			}
+			values = append(values, value)
+			text = strings.TrimSpace(rest)
+			if strings.HasPrefix(text, ",") {
+				text = strings.TrimSpace(text[1:])
+			} else if !strings.HasPrefix(text, "]") {
+				return nil, "", errors.New("unterminated array")
+			}
+		}
+		return values, text[1:], nil
+	case text[0] == '{':
+		return nil, "", errors.New("inline tables are not supported")
+	}
+
+	end := strings.IndexAny(text, ",]#")
+	if end < 0 {
+		end = len(text)
+	}
+	token, rest := strings.TrimSpace(text[:end]), text[end:]
+	value, err := tomlScalar(token)
+	return value, rest, err
+}
+
+// tomlScalar parses a bare value: a boolean, number, or date-time.
+func tomlScalar(token string) (interface{}, error) {
+	switch token {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "inf", "+inf", "-inf", "nan", "+nan", "-nan":
+		token = strings.Replace(token, "inf", "Inf", 1)
+		return strconv.ParseFloat(strings.Replace(token, "nan", "NaN", 1), 64)
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999", "2006-01-02 15:04:05.999999999Z07:00", "2006-01-02"} {
+		if t, err := time.Parse(layout, token); err == nil {
+			return t, nil
+		}
+	}
+	digits := strings.ReplaceAll(token, "_", "")
+	if strings.HasPrefix(digits, "0x") || strings.HasPrefix(digits, "0o") || strings.HasPrefix(digits, "0b") {
+		return strconv.ParseInt(digits, 0, 64)
+	}
+	if i, err := strconv.ParseInt(digits, 10, 64); err == nil {
+		return i, nil
+	}
+	if f, err := strconv.ParseFloat(digits, 64); err == nil {
+		return f, nil
+	}
+	return nil, fmt.Errorf("invalid value %q", token)
+}
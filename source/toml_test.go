@@ -0,0 +1,101 @@
+package source_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jyopp/absorb"
+	"github.com/jyopp/absorb/source"
+)
+
+const servers = `
+title = "fleet" # not a server
+
+[[server]]
+name = "alpha"
+ip = '10.0.0.1'
+ports = [ 8000, 8001 ]
+enabled = true
+
+[server.limits]
+cpu = 1.5
+
+[[server]]
+name = "beta \"b\""
+weight = 1_000
+added = 1979-05-27T07:32:00Z
+
+[database]
+name = "ignored"
+`
+
+func TestTOMLTables(t *testing.T) {
+	type Server struct {
+		Name    string        `toml:"name"`
+		IP      *string       `toml:"ip"`
+		Ports   []interface{} `toml:"ports"`
+		Enabled bool          `toml:"enabled"`
+		CPU     float64       `toml:"limits.cpu"`
+		Weight  int           `toml:"weight"`
+		Added   time.Time     `toml:"added"`
+	}
+
+	var dst []Server
+	if err := absorb.Absorb(&dst, source.TOMLTables(strings.NewReader(servers), "server")); err != nil {
+		t.Fatal(err)
+	}
+	if len(dst) != 2 {
+		t.Fatalf("Expected 2 servers, got %+v", dst)
+	}
+	alpha, beta := dst[0], dst[1]
+	if alpha.Name != "alpha" || *alpha.IP != "10.0.0.1" || len(alpha.Ports) != 2 || alpha.Ports[1] != int64(8001) || !alpha.Enabled || alpha.CPU != 1.5 {
+		t.Fatalf("Unexpected server %+v", alpha)
+	}
+	if beta.Name != `beta "b"` || beta.IP != nil || beta.Weight != 1000 || beta.Added.Year() != 1979 {
+		t.Fatalf("Unexpected server %+v", beta)
+	}
+
+	err := absorb.Absorb(&dst, source.TOMLTables(strings.NewReader("[[server]]\nname = {a = 1}\n"), "server"))
+	if err == nil || !strings.HasPrefix(err.Error(), "line 2:") {
+		t.Fatalf("Expected an error for inline tables, got %v", err)
+	}
+}
+
+func TestINI(t *testing.T) {
+	const config = `
+; global settings
+debug = true
+
+[primary]
+host = db1.example.com
+port: 5432
+
+[replica]
+host = "db2.example.com"
+`
+	type Section struct {
+		Name  string  `ini:"section"`
+		Debug *string `ini:"debug"`
+		Host  string  `ini:"host"`
+		Port  string  `ini:"port"`
+	}
+	var dst []Section
+	if err := absorb.Absorb(&dst, source.INI(strings.NewReader(config))); err != nil {
+		t.Fatal(err)
+	}
+	if len(dst) != 3 || dst[0].Name != "" || *dst[0].Debug != "true" {
+		t.Fatalf("Unexpected sections %+v", dst)
+	}
+	if dst[1].Name != "primary" || dst[1].Host != "db1.example.com" || dst[1].Port != "5432" || dst[1].Debug != nil {
+		t.Fatalf("Unexpected section %+v", dst[1])
+	}
+	if dst[2].Name != "replica" || dst[2].Host != "db2.example.com" {
+		t.Fatalf("Unexpected section %+v", dst[2])
+	}
+
+	// Without global keys, only named sections are emitted.
+	if err := absorb.Absorb(&dst, source.INI(strings.NewReader("[a]\nx=1\n"))); err != nil || len(dst) != 1 || dst[0].Name != "a" {
+		t.Fatalf("Unexpected sections %+v (err %v)", dst, err)
+	}
+}
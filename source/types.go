@@ -0,0 +1,41 @@
+package source
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ValueType selects how an adapter parses raw text before emitting it.
+type ValueType int
+
+const (
+	// TypeString emits the text unchanged, as a string.
+	TypeString ValueType = iota
+	// TypeInt parses the text as a base-10 int64.
+	TypeInt
+	// TypeFloat parses the text as a float64.
+	TypeFloat
+	// TypeBool parses the text with strconv.ParseBool.
+	TypeBool
+)
+
+// parse converts text to the receiver's type. Blank text is parsed as nil for every
+// type except TypeString.
+func (t ValueType) parse(text string) (interface{}, error) {
+	if t == TypeString {
+		return text, nil
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, nil
+	}
+	switch t {
+	case TypeInt:
+		return strconv.ParseInt(text, 10, 64)
+	case TypeFloat:
+		return strconv.ParseFloat(text, 64)
+	case TypeBool:
+		return strconv.ParseBool(text)
+	}
+	panic("unknown source.ValueType " + strconv.Itoa(int(t)))
+}
@@ -0,0 +1,105 @@
+package source
+
+import (
+	"encoding/xml"
+	"io"
+
+	"github.com/jyopp/absorb"
+)
+
+// XMLSource streams every element with a given name from an XML document, such as each
+// <record> in a large export, without loading the document into memory.
+//
+// Each element's attributes and the text of its direct children are emitted as string
+// values, keyed by their local names, in tag namespace "xml".
+type XMLSource struct {
+	r       io.Reader
+	element string
+	// Keys are passed to Open. If empty, the keys are taken from the first matching
+	// element, and keys that only appear in later elements are ignored.
+	Keys []string
+}
+
+// XML creates a source that emits every element named element from r.
+func XML(r io.Reader, element string) *XMLSource {
+	return &XMLSource{r: r, element: element}
+}
+
+type xmlField struct {
+	key, value string
+}
+
+// Emit implements absorb.Absorbable
+func (s *XMLSource) Emit(into absorb.Absorber) error {
+	decoder := xml.NewDecoder(s.r)
+
+	record, err := s.nextRecord(decoder, nil)
+	keys := s.Keys
+	if len(keys) == 0 {
+		for _, field := range record {
+			keys = append(keys, field.key)
+		}
+	}
+	keyIdx := make(map[string]int, len(keys))
+	for idx, key := range keys {
+		keyIdx[key] = idx
+	}
+
+	into.Open("xml", -1, keys...)
+	defer into.Close()
+
+	rowData := make([]interface{}, len(keys))
+	for ; err == nil && record != nil; record, err = s.nextRecord(decoder, record[:0]) {
+		for idx := range rowData {
+			rowData[idx] = nil
+		}
+		for _, field := range record {
+			if idx, ok := keyIdx[field.key]; ok {
+				rowData[idx] = field.value
+			}
+		}
+		into.Absorb(rowData...)
+	}
+	return err
+}
+
+// nextRecord advances to the next matching element and returns its fields, appended to buf.
+// Returns a nil record at the end of the document.
+func (s *XMLSource) nextRecord(decoder *xml.Decoder, buf []xmlField) ([]xmlField, error) {
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if start, ok := token.(xml.StartElement); ok && start.Name.Local == s.element {
+			return s.readRecord(decoder, start, buf)
+		}
+	}
+}
+
+func (s *XMLSource) readRecord(decoder *xml.Decoder, start xml.StartElement, buf []xmlField) ([]xmlField, error) {
+	for _, attr := range start.Attr {
+		buf = append(buf, xmlField{attr.Name.Local, attr.Value})
+	}
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			var child struct {
+				Text string `xml:",chardata"`
+			}
+			if err := decoder.DecodeElement(&child, &token); err != nil {
+				return nil, err
+			}
+			buf = append(buf, xmlField{token.Name.Local, child.Text})
+		case xml.EndElement:
+			// Nested children are consumed by DecodeElement, so this ends the record.
+			return buf, nil
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package source_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jyopp/absorb"
+	"github.com/jyopp/absorb/source"
+)
+
+const testXML = `<?xml version="1.0"?>
+<export>
+	<record id="1"><name>Picard</name><deck>1</deck></record>
+	<record id="2"><name>Riker</name><extra>ignored</extra></record>
+	<other id="3"><name>Skipped</name></other>
+	<record id="4" deck="12"><name>LaForge</name></record>
+</export>`
+
+func TestXML(t *testing.T) {
+	type Record struct {
+		ID   string `xml:"id"`
+		Name string
+		Deck *string
+	}
+
+	var dst []Record
+	src := source.XML(strings.NewReader(testXML), "record")
+	if err := absorb.Absorb(&dst, src); err != nil {
+		t.Fatal(err)
+	}
+	if len(dst) != 3 {
+		t.Fatalf("Expected 3 records, got %+v", dst)
+	}
+	if dst[0].ID != "1" || dst[0].Name != "Picard" || dst[0].Deck == nil || *dst[0].Deck != "1" {
+		t.Fatalf("Unexpected first record %+v", dst[0])
+	}
+	if dst[1].Name != "Riker" || dst[1].Deck != nil {
+		t.Fatalf("Unexpected second record %+v", dst[1])
+	}
+	if dst[2].ID != "4" || dst[2].Deck == nil || *dst[2].Deck != "12" {
+		t.Fatalf("Unexpected third record %+v", dst[2])
+	}
+}
+
+func TestXMLKeys(t *testing.T) {
+	var dst []map[string]string
+	src := source.XML(strings.NewReader(testXML), "record")
+	src.Keys = []string{"name", "extra"}
+	if err := absorb.Absorb(&dst, src); err != nil {
+		t.Fatal(err)
+	}
+	if len(dst) != 3 || dst[1]["extra"] != "ignored" || dst[2]["name"] != "LaForge" {
+		t.Fatalf("Unexpected records %+v", dst)
+	}
+}
@@ -0,0 +1,92 @@
+package absorb
+
+import (
+	"reflect"
+)
+
+// FromChan returns an Absorbable that emits every value received from ch until it is
+// closed. This allows a channel filled by one absorb pipeline to feed another.
+//
+// Struct values (or pointers to structs) are emitted as rows keyed by their exported
+// fields, using the field's tag in the given namespace where present, or the field's
+// name otherwise. As when absorbing, fields with an explicitly empty tag are skipped.
+// Other values are emitted whole under the single key "value".
+func FromChan[T any](ch <-chan T, tag string) Absorbable {
+	return &chanSource[T]{ch: ch, tag: tag}
+}
+
+type chanSource[T any] struct {
+	ch  <-chan T
+	tag string
+}
+
+func (s *chanSource[T]) Emit(into Absorber) error {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	keys, fields := structKeys(typ, s.tag)
+	columns := []ColumnSpec{{Key: "value", Type: typ}}
+	if keys != nil {
+		structType := typ
+		if structType.Kind() == reflect.Ptr {
+			structType = structType.Elem()
+		}
+		columns = make([]ColumnSpec, len(keys))
+		for idx, key := range keys {
+			columns[idx] = ColumnSpec{Key: key, Type: structType.FieldByIndex(fields[idx]).Type}
+		}
+	}
+
+	OpenTyped(into, s.tag, -1, columns...)
+	defer into.Close()
+
+	rowData := make([]interface{}, len(columns))
+	for value := range s.ch {
+		if fields == nil {
+			rowData[0] = value
+		} else if !emitFields(reflect.ValueOf(value), fields, rowData) {
+			// Nil pointers have no fields to emit.
+			continue
+		}
+		into.Absorb(rowData...)
+	}
+	return nil
+}
+
+// structKeys returns the keys for the exported fields of typ, or of the type it points
+// to, along with the index of each field. Returns nil if typ is not a struct.
+func structKeys(typ reflect.Type, tag string) (keys []string, fields [][]int) {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil, nil
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		key := field.Name
+		if tagVal, ok := field.Tag.Lookup(tag); ok {
+			if tagVal, _ = parseTag(tagVal); tagVal == "" {
+				continue
+			}
+			key = tagVal
+		}
+		keys = append(keys, key)
+		fields = append(fields, field.Index)
+	}
+	return keys, fields
+}
+
+// emitFields copies the given fields of a struct value into rowData.
+// Returns false if the value is a nil pointer.
+func emitFields(value reflect.Value, fields [][]int, rowData []interface{}) bool {
+	value = reflect.Indirect(value)
+	if !value.IsValid() {
+		return false
+	}
+	for idx, field := range fields {
+		rowData[idx] = value.FieldByIndex(field).Interface()
+	}
+	return true
+}
@@ -0,0 +1,44 @@
+package absorb_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jyopp/absorb"
+)
+
+func TestFromChan(t *testing.T) {
+	// Absorb into a channel, then absorb that channel into a slice of maps.
+	ch := make(chan *TestDst)
+	go func() {
+		defer close(ch)
+		absorb.Absorb(ch, testSource{i: 2})
+	}()
+
+	var dst []map[string]interface{}
+	if err := absorb.Absorb(&dst, absorb.FromChan(ch, "test")); err != nil {
+		t.Fatal(err)
+	}
+	expect := []map[string]interface{}{
+		{"Name": "test", "Aliased": 1, "Unused": 0},
+		{"Name": "test", "Aliased": 2, "Unused": 0},
+	}
+	if !reflect.DeepEqual(dst, expect) {
+		t.Fatalf("Expected %+v, got %+v", expect, dst)
+	}
+}
+
+func TestFromChanValues(t *testing.T) {
+	ch := make(chan string, 3)
+	ch <- "a"
+	ch <- "b"
+	close(ch)
+
+	var dst []string
+	if err := absorb.Absorb(&dst, absorb.FromChan(ch, "")); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(dst, []string{"a", "b"}) {
+		t.Fatalf("Unexpected values %v", dst)
+	}
+}
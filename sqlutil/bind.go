@@ -0,0 +1,121 @@
+package sqlutil
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Placeholder returns the bind parameter for the nth argument of a statement, counting
+// from 1, in the syntax of a database driver.
+type Placeholder func(n int) string
+
+var (
+	// Question is the placeholder syntax of MySQL and SQLite, "?".
+	Question Placeholder = func(int) string { return "?" }
+	// Dollar is the placeholder syntax of PostgreSQL, "$1", "$2", and so on.
+	Dollar Placeholder = func(n int) string { return "$" + strconv.Itoa(n) }
+)
+
+// Bind returns the Columns of v, a struct or a pointer to one, along with the value of
+// each column's field, as the arguments of an INSERT or UPDATE statement. It is the
+// write-side complement of absorbing query results: a struct absorbed from a row may be
+// bound to write that row back. Fields promoted through a nil embedded pointer are nil.
+//
+// Example:
+//
+//	columns, args := sqlutil.Bind(user, "db")
+//	_, err := db.Exec(sqlutil.Insert("users", columns, sqlutil.Dollar), args...)
+func Bind(v interface{}, tag string) (columns []string, args []interface{}) {
+	val := reflect.Indirect(reflect.ValueOf(v))
+	if val.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("sqlutil: cannot bind %T, which is not a struct", v))
+	}
+	columns, fields := columnFields(val.Type(), tag)
+	args = make([]interface{}, len(fields))
+	for idx, field := range fields {
+		if f, err := val.FieldByIndexErr(field.Index); err == nil {
+			args[idx] = f.Interface()
+		}
+	}
+	return columns, args
+}
+
+// Insert returns an INSERT statement for table, with a bind parameter for each of columns.
+// Panics with ErrIdentifier unless table and columns are plain identifiers.
+func Insert(table string, columns []string, ph Placeholder) string {
+	mustIdentifiers(true, table)
+	mustIdentifiers(false, columns...)
+	return insertRows(table, columns, 1, ph)
+}
+
+// insertRows returns an INSERT statement for table, with bind parameters for the given
+// number of rows of columns. Identifiers must already have been checked.
+func insertRows(table string, columns []string, rows int, ph Placeholder) string {
+	var b strings.Builder
+	b.WriteString("INSERT INTO " + table + " (" + strings.Join(columns, ", ") + ") VALUES ")
+	params := make([]string, len(columns))
+	for row := 0; row < rows; row++ {
+		for idx := range columns {
+			params[idx] = ph(row*len(columns) + idx + 1)
+		}
+		if row > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString("(" + strings.Join(params, ", ") + ")")
+	}
+	return b.String()
+}
+
+// Update returns an UPDATE statement for table that sets every one of columns except keys,
+// in the rows whose keys match, along with args reordered to match its bind parameters.
+// Columns and args are as returned by Bind. Panics if there are no keys, if every column
+// is a key, if a key is not one of columns, or with ErrIdentifier unless table and
+// columns are plain identifiers.
+//
+// Example:
+//
+//	columns, args := sqlutil.Bind(user, "db")
+//	query, args := sqlutil.Update("users", columns, args, []string{"id"}, sqlutil.Question)
+//	_, err := db.Exec(query, args...)
+func Update(table string, columns []string, args []interface{}, keys []string, ph Placeholder) (string, []interface{}) {
+	mustIdentifiers(true, table)
+	mustIdentifiers(false, columns...)
+	if len(keys) == 0 {
+		panic("sqlutil: cannot update " + table + " without key columns")
+	}
+	isKey := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		isKey[key] = true
+	}
+	var set, where []string
+	ordered := make([]interface{}, 0, len(args))
+	for idx, column := range columns {
+		if !isKey[column] {
+			ordered = append(ordered, args[idx])
+			set = append(set, column+" = "+ph(len(ordered)))
+		}
+	}
+	if len(set) == 0 {
+		panic("sqlutil: cannot update " + table + " without columns other than its keys")
+	}
+	for _, key := range keys {
+		idx := indexOf(columns, key)
+		if idx < 0 {
+			panic("sqlutil: key " + strconv.Quote(key) + " is not a bound column")
+		}
+		ordered = append(ordered, args[idx])
+		where = append(where, key+" = "+ph(len(ordered)))
+	}
+	return "UPDATE " + table + " SET " + strings.Join(set, ", ") + " WHERE " + strings.Join(where, " AND "), ordered
+}
+
+func indexOf(columns []string, column string) int {
+	for idx, c := range columns {
+		if c == column {
+			return idx
+		}
+	}
+	return -1
+}
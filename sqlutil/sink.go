@@ -0,0 +1,190 @@
+package sqlutil
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jyopp/absorb"
+)
+
+// DB executes statements. It is satisfied by *sql.DB, *sql.Tx, and *sql.Conn.
+type DB interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+// Sink is an Absorber that inserts each row it absorbs into a table. Open prepares an
+// INSERT statement for the keys it is given, which name the table's columns, and Absorb
+// executes it. Rows may be inserted in batches, with one statement per batch. Open fails
+// with ErrIdentifier unless the table and keys are plain identifiers, since keys often
+// come from untrusted input, such as the header of a CSV file.
+//
+// Like other Absorbers, Sink panics if a statement fails. Use Load to have the error
+// returned instead.
+type Sink struct {
+	// Context is used for every statement, or context.Background if nil.
+	Context context.Context
+	// BatchSize is the number of rows inserted by each statement. Rows are inserted one
+	// at a time if it is not positive. The last batch is inserted by Close.
+	BatchSize int
+	// MaxParams limits the bind parameters of each statement, reducing the rows of a
+	// batch so that wide tables fit the database's limit. If not positive, it is
+	// DefaultMaxParams.
+	MaxParams int
+
+	db      DB
+	table   string
+	ph      Placeholder
+	columns []string
+	stmt    *sql.Stmt
+	batch   []interface{}
+	rows    int64
+}
+
+// DefaultMaxParams is the default limit on the bind parameters of a Sink's statements,
+// which is the lowest limit of common databases: SQLite's default of 999.
+const DefaultMaxParams = 999
+
+// NewSink returns a Sink that inserts rows into table through db, using the driver's
+// placeholder syntax.
+func NewSink(db DB, table string, ph Placeholder) *Sink {
+	return &Sink{db: db, table: table, ph: ph}
+}
+
+// Load inserts every row of src into table, and returns the number of rows inserted.
+// Rows are inserted in batches of up to 100, and of up to DefaultMaxParams values.
+// Failed statements stop the source, and their error is returned.
+//
+// Example:
+//
+//	n, err := sqlutil.Load(db, "users", sqlutil.Dollar, csvSource)
+func Load(db DB, table string, ph Placeholder, src absorb.Absorbable) (n int64, err error) {
+	s := NewSink(db, table, ph)
+	s.BatchSize = 100
+	defer func() {
+		if p := recover(); p != nil {
+			sinkErr, ok := p.(*SinkError)
+			if !ok {
+				panic(p)
+			}
+			n, err = s.rows, sinkErr
+		}
+	}()
+	err = src.Emit(s)
+	return s.rows, err
+}
+
+// SinkError reports a statement that failed while inserting rows into a table.
+type SinkError struct {
+	Table string
+	Err   error
+}
+
+func (e *SinkError) Error() string {
+	return "sqlutil: inserting into " + e.Table + ": " + e.Err.Error()
+}
+
+func (e *SinkError) Unwrap() error {
+	return e.Err
+}
+
+// Rows returns the number of rows inserted since the Sink was created.
+func (s *Sink) Rows() int64 {
+	return s.rows
+}
+
+func (s *Sink) Open(tag string, count int, keys ...string) {
+	if len(keys) == 0 {
+		panic(s.fail(errors.New("no columns to insert")))
+	}
+	if err := checkIdentifiers(true, s.table); err != nil {
+		panic(s.fail(err))
+	}
+	if err := checkIdentifiers(false, keys...); err != nil {
+		panic(s.fail(err))
+	}
+	s.closeStmt()
+	s.columns, s.batch = keys, nil
+	stmt, err := s.db.PrepareContext(s.context(), insertRows(s.table, keys, s.batchSize(), s.ph))
+	if err != nil {
+		panic(s.fail(err))
+	}
+	s.stmt = stmt
+}
+
+func (s *Sink) Absorb(values ...interface{}) {
+	if s.stmt == nil {
+		panic(absorb.ErrNotOpen)
+	}
+	if len(values) != len(s.columns) {
+		panic(fmt.Errorf("%w: %d values for %d columns", absorb.ErrArity, len(values), len(s.columns)))
+	}
+	for _, value := range values {
+		if b, ok := value.([]byte); ok {
+			// Sources may reuse their buffers before the batch is executed.
+			value = append([]byte(nil), b...)
+		}
+		s.batch = append(s.batch, value)
+	}
+	if len(s.batch) == s.batchSize()*len(s.columns) {
+		s.exec(s.stmt.ExecContext(s.context(), s.batch...))
+	}
+}
+
+// Close inserts any rows left in the last batch, and releases the prepared statement.
+func (s *Sink) Close() {
+	if s.stmt == nil {
+		panic(absorb.ErrNotOpen)
+	}
+	defer s.closeStmt()
+	if len(s.batch) > 0 {
+		query := insertRows(s.table, s.columns, len(s.batch)/len(s.columns), s.ph)
+		s.exec(s.db.ExecContext(s.context(), query, s.batch...))
+	}
+}
+
+// exec counts the rows inserted by a statement, and clears the batch.
+func (s *Sink) exec(_ sql.Result, err error) {
+	inserted := len(s.batch) / len(s.columns)
+	// A failed batch is discarded, rather than retried by Close.
+	s.batch = s.batch[:0]
+	if err != nil {
+		panic(s.fail(err))
+	}
+	s.rows += int64(inserted)
+}
+
+func (s *Sink) closeStmt() {
+	if s.stmt != nil {
+		s.stmt.Close()
+		s.stmt = nil
+	}
+}
+
+func (s *Sink) fail(err error) *SinkError {
+	return &SinkError{Table: s.table, Err: err}
+}
+
+func (s *Sink) context() context.Context {
+	if s.Context == nil {
+		return context.Background()
+	}
+	return s.Context
+}
+
+// batchSize returns the number of rows inserted by each statement, which is at least 1.
+func (s *Sink) batchSize() int {
+	size, maxParams := s.BatchSize, s.MaxParams
+	if maxParams < 1 {
+		maxParams = DefaultMaxParams
+	}
+	if len(s.columns) > 0 && size*len(s.columns) > maxParams {
+		size = maxParams / len(s.columns)
+	}
+	if size < 1 {
+		return 1
+	}
+	return size
+}
@@ -0,0 +1,159 @@
+package sqlutil_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/jyopp/absorb"
+	"github.com/jyopp/absorb/sqlutil"
+)
+
+// recorder is a database/sql driver that records the statements it executes, and fails
+// those that insert the value "fail".
+type recorder struct {
+	execs []string
+}
+
+func (r *recorder) Open(string) (driver.Conn, error) { return recorderConn{r}, nil }
+
+type recorderConn struct{ r *recorder }
+
+func (c recorderConn) Prepare(query string) (driver.Stmt, error) {
+	return recorderStmt{c.r, query}, nil
+}
+func (c recorderConn) Close() error              { return nil }
+func (c recorderConn) Begin() (driver.Tx, error) { return nil, errors.New("no transactions") }
+
+type recorderStmt struct {
+	r     *recorder
+	query string
+}
+
+func (s recorderStmt) Close() error  { return nil }
+func (s recorderStmt) NumInput() int { return -1 }
+func (s recorderStmt) Exec(args []driver.Value) (driver.Result, error) {
+	for _, arg := range args {
+		if arg == "fail" {
+			return nil, errors.New("constraint failed")
+		}
+	}
+	s.r.execs = append(s.r.execs, fmt.Sprint(s.query, args))
+	return driver.RowsAffected(len(args)), nil
+}
+func (s recorderStmt) Query([]driver.Value) (driver.Rows, error) { return nil, io.EOF }
+
+var driverCount int
+
+func openRecorder(t *testing.T) (*sql.DB, *recorder) {
+	r := &recorder{}
+	driverCount++
+	name := fmt.Sprintf("recorder%d", driverCount)
+	sql.Register(name, r)
+	db, err := sql.Open(name, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db, r
+}
+
+type rowSource [][]interface{}
+
+func (rs rowSource) Emit(into absorb.Absorber) error {
+	return headerSource{[]string{"id", "name"}, rs}.Emit(into)
+}
+
+// headerSource emits rows with the given keys, as a CSV file with a header would.
+type headerSource struct {
+	keys []string
+	rows rowSource
+}
+
+func (hs headerSource) Emit(into absorb.Absorber) error {
+	rs := hs.rows
+	into.Open("", len(rs), hs.keys...)
+	defer into.Close()
+	for _, row := range rs {
+		into.Absorb(row...)
+	}
+	return nil
+}
+
+func TestSink(t *testing.T) {
+	db, r := openRecorder(t)
+	src := rowSource{{1, "a"}, {2, "b"}, {3, "c"}}
+
+	sink := sqlutil.NewSink(db, "users", sqlutil.Question)
+	sink.BatchSize = 2
+	if err := src.Emit(sink); err != nil {
+		t.Fatal(err)
+	}
+	expect := []string{
+		"INSERT INTO users (id, name) VALUES (?, ?), (?, ?)[1 a 2 b]",
+		"INSERT INTO users (id, name) VALUES (?, ?)[3 c]",
+	}
+	if strings.Join(r.execs, "\n") != strings.Join(expect, "\n") || sink.Rows() != 3 {
+		t.Fatalf("Unexpected statements for %d rows:\n%s", sink.Rows(), strings.Join(r.execs, "\n"))
+	}
+
+	// Load returns the failure of a statement.
+	r.execs = nil
+	n, err := sqlutil.Load(db, "users", sqlutil.Dollar, rowSource{{1, "a"}, {2, "fail"}})
+	var sinkErr *sqlutil.SinkError
+	if !errors.As(err, &sinkErr) || sinkErr.Table != "users" || n != 0 || len(r.execs) != 0 {
+		t.Fatalf("Unexpected result: %d rows, err %v, statements %v", n, err, r.execs)
+	}
+	n, err = sqlutil.Load(db, "users", sqlutil.Dollar, src)
+	if err != nil || n != 3 || len(r.execs) != 1 || !strings.HasPrefix(r.execs[0], "INSERT INTO users (id, name) VALUES ($1, $2), ($3, $4), ($5, $6)[") {
+		t.Fatalf("Unexpected result: %d rows, err %v, statements %v", n, err, r.execs)
+	}
+}
+
+func TestSinkIdentifiers(t *testing.T) {
+	db, r := openRecorder(t)
+	hostile := headerSource{[]string{"id) VALUES (1); DROP TABLE users; --", "name"}, rowSource{{1, "a"}}}
+	n, err := sqlutil.Load(db, "users", sqlutil.Question, hostile)
+	if !errors.Is(err, sqlutil.ErrIdentifier) || n != 0 || len(r.execs) != 0 {
+		t.Fatalf("Expected ErrIdentifier, got %d rows, err %v, statements %v", n, err, r.execs)
+	}
+	_, err = sqlutil.Load(db, "users; DROP TABLE users", sqlutil.Question, rowSource{{1, "a"}})
+	if !errors.Is(err, sqlutil.ErrIdentifier) || len(r.execs) != 0 {
+		t.Fatalf("Expected ErrIdentifier for the table, got %v, statements %v", err, r.execs)
+	}
+
+	n, err = sqlutil.Load(db, "app.users", sqlutil.Question, headerSource{[]string{"_id", "Name2"}, rowSource{{1, "a"}}})
+	if err != nil || n != 1 || r.execs[0] != "INSERT INTO app.users (_id, Name2) VALUES (?, ?)[1 a]" {
+		t.Fatalf("Unexpected result: %d rows, err %v, statements %v", n, err, r.execs)
+	}
+}
+
+func TestSinkMaxParams(t *testing.T) {
+	db, r := openRecorder(t)
+	sink := sqlutil.NewSink(db, "users", sqlutil.Question)
+	sink.BatchSize, sink.MaxParams = 10, 5
+	if err := (rowSource{{1, "a"}, {2, "b"}, {3, "c"}, {4, "d"}, {5, "e"}}).Emit(sink); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.execs) != 3 || sink.Rows() != 5 || !strings.HasPrefix(r.execs[0], "INSERT INTO users (id, name) VALUES (?, ?), (?, ?)[") {
+		t.Fatalf("Unexpected statements for %d rows:\n%s", sink.Rows(), strings.Join(r.execs, "\n"))
+	}
+
+	// Load keeps wide tables within SQLite's default limit of 999 parameters.
+	r.execs = nil
+	wide := headerSource{keys: make([]string, 12)}
+	for idx := range wide.keys {
+		wide.keys[idx] = fmt.Sprintf("c%d", idx)
+	}
+	for i := 0; i < 100; i++ {
+		wide.rows = append(wide.rows, make([]interface{}, 12))
+	}
+	n, err := sqlutil.Load(db, "wide", sqlutil.Question, wide)
+	if err != nil || n != 100 || len(r.execs) != 2 {
+		t.Fatalf("Unexpected result: %d rows, err %v, %d statements", n, err, len(r.execs))
+	}
+}
@@ -0,0 +1,116 @@
+// Package sqlutil builds SQL from the same struct tags that absorb maps, so that queries
+// select exactly the columns a destination expects, and structs can be written back to the
+// tables they were read from.
+package sqlutil
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jyopp/absorb"
+)
+
+// Columns returns the columns that elements of type t, a struct or a pointer to one, map
+// when absorbed with tag, as reported by absorb.KeysFor. Embedded structs whose fields are
+// promoted contribute those fields rather than a column of their own, and unexported
+// fields are skipped. Returns nil if t is not a struct.
+func Columns(t reflect.Type, tag string) []string {
+	keys, _ := columnFields(t, tag)
+	return keys
+}
+
+// SelectList returns the comma-separated list of Columns for t, for use in a SELECT
+// statement. If alias is not empty, each column is qualified by it, as in "u.name", so
+// that lists for several tables may be combined in a join. Columns are not quoted, so
+// SelectList panics with ErrIdentifier unless the alias and columns are plain identifiers.
+//
+// Example:
+//
+//	query := "SELECT " + sqlutil.SelectList(reflect.TypeOf(User{}), "db", "u") +
+//		" FROM users u WHERE u.active"
+func SelectList(t reflect.Type, tag, alias string) string {
+	columns := Columns(t, tag)
+	mustIdentifiers(false, columns...)
+	if alias != "" {
+		mustIdentifiers(false, alias)
+		for idx, column := range columns {
+			columns[idx] = alias + "." + column
+		}
+	}
+	return strings.Join(columns, ", ")
+}
+
+// ErrIdentifier reports a table or column name that is not a plain SQL identifier.
+// Names are written into statements unquoted, so sqlutil rejects any other name rather
+// than let names from outside the program, such as the headers of a CSV file, inject SQL.
+var ErrIdentifier = errors.New("sqlutil: not a plain identifier")
+
+// checkIdentifiers returns an ErrIdentifier unless each name is a plain SQL identifier:
+// an ASCII letter or underscore, followed by ASCII letters, digits, and underscores. If
+// qualified, names may also join several identifiers with dots, as in "schema.table".
+func checkIdentifiers(qualified bool, names ...string) error {
+	for _, name := range names {
+		parts := []string{name}
+		if qualified {
+			parts = strings.Split(name, ".")
+		}
+		for _, part := range parts {
+			if !isIdentifier(part) {
+				return fmt.Errorf("%w: %q", ErrIdentifier, name)
+			}
+		}
+	}
+	return nil
+}
+
+// mustIdentifiers panics with the error of checkIdentifiers, if any.
+func mustIdentifiers(qualified bool, names ...string) {
+	if err := checkIdentifiers(qualified, names...); err != nil {
+		panic(err)
+	}
+}
+
+func isIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+	for idx, c := range name {
+		switch {
+		case c == '_', 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+		case '0' <= c && c <= '9' && idx > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// columnFields returns the columns of t, and the field that each maps to.
+func columnFields(t reflect.Type, tag string) ([]string, []reflect.StructField) {
+	keys, fields := absorb.KeysFor(t, tag), absorb.FieldsFor(t, tag)
+	var columns []string
+	var columnFields []reflect.StructField
+	for idx, field := range fields {
+		if field.IsExported() && !promotes(field, fields) {
+			columns = append(columns, keys[idx])
+			columnFields = append(columnFields, field)
+		}
+	}
+	return columns, columnFields
+}
+
+// promotes reports whether field is an embedded struct through which any of fields is
+// promoted.
+func promotes(field reflect.StructField, fields []reflect.StructField) bool {
+	if !field.Anonymous {
+		return false
+	}
+	for _, f := range fields {
+		if len(f.Index) > len(field.Index) && reflect.DeepEqual(f.Index[:len(field.Index)], field.Index) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+package sqlutil_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/jyopp/absorb"
+	"github.com/jyopp/absorb/sqlutil"
+)
+
+type Audit struct {
+	CreatedAt string `db:"created_at"`
+	UpdatedAt string `db:"updated_at"`
+}
+
+type User struct {
+	ID       int64  `db:"id"`
+	Name     string `db:"full_name"`
+	Password string `db:""`
+	Email    string
+	*Audit
+}
+
+func TestSelectList(t *testing.T) {
+	typ := reflect.TypeOf(User{})
+	columns := sqlutil.Columns(typ, "db")
+	expect := []string{"id", "full_name", "Email", "created_at", "updated_at"}
+	if !reflect.DeepEqual(columns, expect) {
+		t.Fatalf("Expected %v, got %v", expect, columns)
+	}
+	// Every column maps to a field when absorbed.
+	var users []User
+	if wanted := absorb.WantedKeys(absorb.New(&users), "db", columns); !reflect.DeepEqual(wanted, columns) {
+		t.Fatalf("Expected every column to be wanted, got %v", wanted)
+	}
+
+	if list := sqlutil.SelectList(typ, "db", ""); list != "id, full_name, Email, created_at, updated_at" {
+		t.Fatalf("Unexpected list %q", list)
+	}
+	if list := sqlutil.SelectList(reflect.TypeOf(&Audit{}), "db", "a"); list != "a.created_at, a.updated_at" {
+		t.Fatalf("Unexpected list %q", list)
+	}
+	if list := sqlutil.SelectList(reflect.TypeOf(0), "db", ""); list != "" {
+		t.Fatalf("Expected an empty list, got %q", list)
+	}
+}
+
+func TestBind(t *testing.T) {
+	user := User{ID: 7, Name: "Ann", Password: "secret", Email: "ann@example.com"}
+	columns, args := sqlutil.Bind(&user, "db")
+	expect := []interface{}{int64(7), "Ann", "ann@example.com", nil, nil}
+	if len(columns) != 5 || !reflect.DeepEqual(args, expect) {
+		t.Fatalf("Expected %v, got %v: %v", expect, columns, args)
+	}
+
+	user.Audit = &Audit{CreatedAt: "today"}
+	columns, args = sqlutil.Bind(user, "db")
+	if args[3] != "today" || args[4] != "" {
+		t.Fatalf("Unexpected args %v", args)
+	}
+
+	insert := sqlutil.Insert("users", columns[:3], sqlutil.Dollar)
+	if insert != "INSERT INTO users (id, full_name, Email) VALUES ($1, $2, $3)" {
+		t.Fatalf("Unexpected statement %q", insert)
+	}
+
+	update, ordered := sqlutil.Update("users", columns[:3], args[:3], []string{"id"}, sqlutil.Question)
+	if update != "UPDATE users SET full_name = ?, Email = ? WHERE id = ?" {
+		t.Fatalf("Unexpected statement %q", update)
+	}
+	if !reflect.DeepEqual(ordered, []interface{}{"Ann", "ann@example.com", int64(7)}) {
+		t.Fatalf("Unexpected args %v", ordered)
+	}
+
+	for name, keys := range map[string][]string{
+		"unknown key": {"uuid"},
+		"no keys":     nil,
+		"all keys":    columns[:3],
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("Expected a panic for %s", name)
+				}
+			}()
+			sqlutil.Update("users", columns[:3], args[:3], keys, sqlutil.Question)
+		}()
+	}
+}
+
+func TestIdentifiers(t *testing.T) {
+	expectPanic := func(name string, fn func()) {
+		t.Helper()
+		defer func() {
+			if err, _ := recover().(error); !errors.Is(err, sqlutil.ErrIdentifier) {
+				t.Fatalf("%s: expected ErrIdentifier, got %v", name, err)
+			}
+		}()
+		fn()
+	}
+	hostile := []string{"id", "name = 'x'; --"}
+	expectPanic("Insert column", func() { sqlutil.Insert("users", hostile, sqlutil.Question) })
+	expectPanic("Insert table", func() { sqlutil.Insert("users (id) --", []string{"id"}, sqlutil.Question) })
+	expectPanic("Update column", func() {
+		sqlutil.Update("users", hostile, []interface{}{1, 2}, []string{"id"}, sqlutil.Question)
+	})
+	expectPanic("SelectList alias", func() { sqlutil.SelectList(reflect.TypeOf(User{}), "db", "u, secrets s") })
+
+	type Hostile struct {
+		Name string `db:"name FROM users; --"`
+	}
+	expectPanic("SelectList column", func() { sqlutil.SelectList(reflect.TypeOf(Hostile{}), "db", "") })
+}
@@ -0,0 +1,172 @@
+package absorb
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ChangeOp is the kind of a Change.
+type ChangeOp int
+
+const (
+	Insert ChangeOp = iota
+	Update
+	Delete
+)
+
+func (op ChangeOp) String() string {
+	switch op {
+	case Insert:
+		return "Insert"
+	case Update:
+		return "Update"
+	case Delete:
+		return "Delete"
+	}
+	return fmt.Sprintf("ChangeOp(%d)", int(op))
+}
+
+// Change is a row that was added, modified, or removed between two versions of a source.
+type Change struct {
+	Op  ChangeOp
+	Key interface{}
+	// Row is the new row for inserts and updates, or the old row for deletes.
+	Row Row
+}
+
+// WriterSink is a destination that can apply individual changes, such as a database table,
+// search index, or cache. It is Absorbable, and emits its current rows for comparison.
+type WriterSink interface {
+	Absorbable
+	Insert(row Row) error
+	Update(row Row) error
+	Delete(key interface{}) error
+}
+
+// Diff compares the rows emitted by old and new, matched by their value for the column
+// named key, and returns the changes that turn old into new. Inserts and updates are
+// returned in the order new emits them, after every delete.
+//
+// Only the columns emitted by new are compared, so old may have extra columns. Values are
+// equal if they are deeply equal, or if they format identically, so that an int64 column
+// matches an int. Both sources are held in memory.
+// Panics if either source does not emit the key column.
+func Diff(old, new Absorbable, key string) ([]Change, error) {
+	before, err := collectRows(old, key)
+	if err != nil {
+		return nil, err
+	}
+	after, err := collectRows(new, key)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []Change
+	for _, row := range before.rows {
+		if _, ok := after.index[before.id(row)]; !ok {
+			changes = append(changes, Change{Op: Delete, Key: row.Value(key), Row: row})
+		}
+	}
+	for _, row := range after.rows {
+		prev, ok := before.index[after.id(row)]
+		if !ok {
+			changes = append(changes, Change{Op: Insert, Key: row.Value(key), Row: row})
+		} else if !sameRow(row, before.rows[prev]) {
+			changes = append(changes, Change{Op: Update, Key: row.Value(key), Row: row})
+		}
+	}
+	return changes, nil
+}
+
+// SyncStats counts the changes applied by Sync.
+type SyncStats struct {
+	Inserted, Updated, Deleted int
+}
+
+// Sync mirrors src into dst: It compares their rows by the column named key, as Diff does,
+// and applies each change to dst. Stops at the first error returned by dst, and returns
+// the counts of changes applied so far.
+//
+// Example:
+//
+//	stats, err := absorb.Sync(usersQuery, searchIndex, "id")
+func Sync(src Absorbable, dst WriterSink, key string) (SyncStats, error) {
+	var stats SyncStats
+	changes, err := Diff(dst, src, key)
+	if err != nil {
+		return stats, err
+	}
+	for _, change := range changes {
+		switch change.Op {
+		case Insert:
+			err = dst.Insert(change.Row)
+			stats.Inserted++
+		case Update:
+			err = dst.Update(change.Row)
+			stats.Updated++
+		case Delete:
+			err = dst.Delete(change.Key)
+			stats.Deleted++
+		}
+		if err != nil {
+			return stats, fmt.Errorf("absorb: sync %s %v: %w", change.Op, change.Key, err)
+		}
+	}
+	return stats, nil
+}
+
+// rowSet holds every row emitted by a source, indexed by the formatted value of a key.
+type rowSet struct {
+	key   string
+	rows  []Row
+	index map[string]int
+}
+
+func (s *rowSet) id(row Row) string {
+	return fmt.Sprint(row.Value(s.key))
+}
+
+// collectRows absorbs every row of src into a rowSet.
+func collectRows(src Absorbable, key string) (*rowSet, error) {
+	c := &rowCollector{set: &rowSet{key: key, index: make(map[string]int)}}
+	if err := src.Emit(c); err != nil {
+		return nil, err
+	}
+	return c.set, nil
+}
+
+// rowCollector is an Absorber that keeps a copy of each row.
+type rowCollector struct {
+	set  *rowSet
+	keys []string
+}
+
+func (c *rowCollector) Open(tag string, count int, keys ...string) {
+	found := false
+	for _, k := range keys {
+		found = found || k == c.set.key
+	}
+	if !found {
+		panic(fmt.Errorf("%w: cannot compare rows by %q", ErrMissingKey, c.set.key))
+	}
+	c.keys = append([]string(nil), keys...)
+}
+
+func (c *rowCollector) Absorb(values ...interface{}) {
+	row := Row{Keys: c.keys, Values: copyBytes(append([]interface{}(nil), values...))}
+	c.set.index[c.set.id(row)] = len(c.set.rows)
+	c.set.rows = append(c.set.rows, row)
+}
+
+func (c *rowCollector) Close() {}
+
+// sameRow reports whether old has the same values as row, for each of row's keys.
+func sameRow(row, old Row) bool {
+	for idx, key := range row.Keys {
+		a, b := row.Values[idx], old.Value(key)
+		if !reflect.DeepEqual(a, b) && fmt.Sprint(a) != fmt.Sprint(b) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,86 @@
+package absorb_test
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/jyopp/absorb"
+)
+
+// tableSink is an in-memory WriterSink of rows keyed by "id".
+type tableSink struct {
+	rows map[int64]string
+	ops  []string
+}
+
+func (ts *tableSink) Emit(into absorb.Absorber) error {
+	into.Open("test", len(ts.rows), "id", "name", "extra")
+	defer into.Close()
+	ids := make([]int64, 0, len(ts.rows))
+	for id := range ts.rows {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, id := range ids {
+		into.Absorb(id, ts.rows[id], "ignored")
+	}
+	return nil
+}
+
+func (ts *tableSink) Insert(row absorb.Row) error {
+	ts.ops = append(ts.ops, "insert "+row.Value("name").(string))
+	ts.rows[int64(row.Value("id").(int))] = row.Value("name").(string)
+	return nil
+}
+
+func (ts *tableSink) Update(row absorb.Row) error {
+	ts.ops = append(ts.ops, "update "+row.Value("name").(string))
+	ts.rows[int64(row.Value("id").(int))] = row.Value("name").(string)
+	return nil
+}
+
+func (ts *tableSink) Delete(key interface{}) error {
+	ts.ops = append(ts.ops, "delete "+ts.rows[key.(int64)])
+	delete(ts.rows, key.(int64))
+	return nil
+}
+
+// namesSource emits rows of ids and names, with int ids.
+type namesSource map[int]string
+
+func (ns namesSource) Emit(into absorb.Absorber) error {
+	into.Open("test", len(ns), "id", "name")
+	defer into.Close()
+	for id := 1; id <= 5; id++ {
+		if name, ok := ns[id]; ok {
+			into.Absorb(id, name)
+		}
+	}
+	return nil
+}
+
+func TestSync(t *testing.T) {
+	sink := &tableSink{rows: map[int64]string{1: "ann", 2: "bob", 3: "cat"}}
+	src := namesSource{1: "ann", 3: "cathy", 4: "dan"}
+
+	stats, err := absorb.Sync(src, sink, "id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := (absorb.SyncStats{Inserted: 1, Updated: 1, Deleted: 1}); stats != expect {
+		t.Fatalf("Expected %+v, got %+v", expect, stats)
+	}
+	if expect := []string{"delete bob", "update cathy", "insert dan"}; !reflect.DeepEqual(sink.ops, expect) {
+		t.Fatalf("Expected %v, got %v", expect, sink.ops)
+	}
+
+	// A second sync finds nothing to do.
+	if changes, err := absorb.Diff(sink, src, "id"); err != nil || len(changes) != 0 {
+		t.Fatalf("Expected no changes, got %+v (%v)", changes, err)
+	}
+
+	subpanic(t, "Missing Key", func() {
+		absorb.Sync(src, sink, "name_id")
+	})
+}
@@ -0,0 +1,113 @@
+package absorb
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Synchronized returns an Absorber that absorbs into dst, as New(dst, opts...) would, and
+// may be shared by goroutines that emit concurrently, such as parallel shard readers.
+// Each call is serialized with a mutex, so rows are absorbed one at a time in the order
+// their callers acquire it.
+//
+// Each goroutine may Open and Close the Absorber for itself. The first Open opens dst,
+// and later Opens must have the same tag and keys, and their counts are ignored. Closing
+// does not close dst, so that a shard that opens after the others have closed adds its
+// rows to theirs; The owner calls Finish to close dst once every shard is done.
+//
+// Example:
+//
+//	var events []Event
+//	into := absorb.Synchronized(&events)
+//	var wg sync.WaitGroup
+//	for _, shard := range shards {
+//		wg.Add(1)
+//		go func(shard absorb.Absorbable) {
+//			defer wg.Done()
+//			shard.Emit(into)
+//		}(shard)
+//	}
+//	wg.Wait()
+//	err := into.Finish()
+func Synchronized(dst interface{}, opts ...Option) *SynchronizedAbsorber {
+	return &SynchronizedAbsorber{next: New(dst, opts...)}
+}
+
+// SynchronizedAbsorber absorbs rows from concurrent goroutines into one destination.
+// See Synchronized.
+type SynchronizedAbsorber struct {
+	mu   sync.Mutex
+	next Absorber
+	tag  string
+	keys []string
+	// open is set from the first Open until Finish.
+	open bool
+	// opens counts the Opens not yet balanced by a Close.
+	opens int
+}
+
+func (s *SynchronizedAbsorber) Open(tag string, count int, keys ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.open {
+		if tag != s.tag || !sameKeys(keys, s.keys) {
+			panic(fmt.Sprintf("cannot open %q with keys %q, already open as %q with %q", tag, keys, s.tag, s.keys))
+		}
+		s.opens++
+		return
+	}
+	s.next.Open(tag, count, keys...)
+	s.tag, s.keys = tag, append([]string(nil), keys...)
+	s.open, s.opens = true, 1
+}
+
+func (s *SynchronizedAbsorber) Absorb(values ...interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.opens == 0 {
+		panic(ErrNotOpen)
+	}
+	s.next.Absorb(values...)
+}
+
+// Close balances an Open. It leaves dst open for other goroutines, until Finish.
+func (s *SynchronizedAbsorber) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.opens == 0 {
+		panic(ErrNotOpen)
+	}
+	s.opens--
+}
+
+// Finish closes dst, once every Open has been closed, and returns any failure to close
+// it. Finish does nothing if the Absorber was never opened. Opening it again afterward
+// starts dst over, as reopening any Absorber does.
+// Panics if a goroutine has not closed the Absorber.
+func (s *SynchronizedAbsorber) Finish() (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.opens > 0 {
+		panic(fmt.Sprintf("cannot finish while %d Opens are not closed", s.opens))
+	}
+	if !s.open {
+		return nil
+	}
+	s.open = false
+	defer recoverError(&err)
+	s.next.Close()
+	return nil
+}
+
+// sameKeys reports whether a and b hold the same keys in the same order.
+func sameKeys(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for idx := range a {
+		if a[idx] != b[idx] {
+			return false
+		}
+	}
+	return true
+}
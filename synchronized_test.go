@@ -0,0 +1,84 @@
+package absorb_test
+
+import (
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/jyopp/absorb"
+)
+
+// shardSource emits n rows numbered from first, and waits for start, if set, to open.
+type shardSource struct {
+	first, n int
+	start    chan struct{}
+}
+
+func (s shardSource) Emit(into absorb.Absorber) error {
+	if s.start != nil {
+		<-s.start
+	}
+	into.Open("test", s.n, "Name", "Aliased")
+	defer into.Close()
+	for i := 0; i < s.n; i++ {
+		into.Absorb("test", s.first+i)
+	}
+	return nil
+}
+
+func TestSynchronized(t *testing.T) {
+	var all []TestDst
+	into := absorb.Synchronized(&all)
+
+	// The last shard opens only after every other shard has closed.
+	late := make(chan struct{})
+	var others, wg sync.WaitGroup
+	for shard := 0; shard < 8; shard++ {
+		src := shardSource{first: shard * 100, n: 100}
+		if shard == 7 {
+			src.start = late
+		} else {
+			others.Add(1)
+		}
+		wg.Add(1)
+		go func(shard int, src shardSource) {
+			defer wg.Done()
+			if shard < 7 {
+				defer others.Done()
+			}
+			if err := src.Emit(into); err != nil {
+				t.Error(err)
+			}
+		}(shard, src)
+	}
+	others.Wait()
+	close(late)
+	wg.Wait()
+	if err := into.Finish(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(all) != 800 {
+		t.Fatalf("Expected 800 rows, got %d", len(all))
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Actual < all[j].Actual })
+	for idx, dst := range all {
+		if dst.Actual != idx {
+			t.Fatalf("Row %d: expected %d, got %+v", idx, idx, dst)
+		}
+	}
+
+	subpanic(t, "Absorb after Close", func() {
+		into.Absorb("test", 1)
+	})
+	subpanic(t, "Mismatched keys", func() {
+		into.Open("test", -1, "Name")
+		defer into.Close()
+		into.Open("test", -1, "Aliased")
+	})
+	subpanic(t, "Finish while open", func() {
+		into.Open("test", -1, "Name")
+		defer into.Close()
+		into.Finish()
+	})
+}
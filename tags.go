@@ -0,0 +1,36 @@
+package absorb
+
+import "strings"
+
+// tagOptions holds the comma-separated options that follow the key in a struct tag,
+// such as "unit=MiB" in `csv:"size,unit=MiB"`.
+type tagOptions string
+
+// parseTag splits a struct tag value into its key and options.
+func parseTag(tag string) (string, tagOptions) {
+	key, opts, _ := strings.Cut(tag, ",")
+	return key, tagOptions(opts)
+}
+
+// has reports whether the options include the given flag, such as "cents".
+func (o tagOptions) has(flag string) bool {
+	_, ok := o.lookup(flag, false)
+	return ok
+}
+
+// get returns the value of a name=value option, such as "MiB" for "unit".
+func (o tagOptions) get(name string) (string, bool) {
+	return o.lookup(name, true)
+}
+
+func (o tagOptions) lookup(name string, valued bool) (string, bool) {
+	for s := string(o); s != ""; {
+		var opt string
+		opt, s, _ = strings.Cut(s, ",")
+		key, value, hasValue := strings.Cut(opt, "=")
+		if strings.TrimSpace(key) == name && hasValue == valued {
+			return strings.TrimSpace(value), true
+		}
+	}
+	return "", false
+}
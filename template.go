@@ -0,0 +1,71 @@
+package absorb
+
+import (
+	"fmt"
+	"io"
+	"text/template"
+)
+
+// Template returns an Absorber that executes tmpl once for each row, writing the output to
+// w. Each row is passed to the template as a map from its keys to its values, so that
+// columns are referred to as fields, such as {{.name}}. Byte slices are passed as strings.
+// This renders reports, fixtures, or code from any source.
+//
+// Execution errors, including errors writing to w, panic.
+//
+// Example:
+//
+//	tmpl := template.Must(template.New("row").Parse("{{.id}}: {{.name}}\n"))
+//	err := src.Emit(absorb.Template(os.Stdout, tmpl))
+func Template(w io.Writer, tmpl *template.Template) *TemplateAbsorber {
+	return &TemplateAbsorber{w: w, tmpl: tmpl}
+}
+
+// TemplateAbsorber renders each row it absorbs with a template. See Template.
+type TemplateAbsorber struct {
+	w    io.Writer
+	tmpl *template.Template
+	keys []string
+	open bool
+	rows int
+}
+
+// Rows returns the number of rows rendered since the last call to Open.
+func (t *TemplateAbsorber) Rows() int {
+	return t.rows
+}
+
+func (t *TemplateAbsorber) Open(tag string, count int, keys ...string) {
+	if t.open {
+		panic(ErrAlreadyOpen)
+	}
+	t.keys, t.open, t.rows = keys, true, 0
+}
+
+func (t *TemplateAbsorber) Absorb(values ...interface{}) {
+	if !t.open {
+		panic(ErrNotOpen)
+	}
+	row := make(map[string]interface{}, len(t.keys))
+	for idx, key := range t.keys {
+		if idx >= len(values) {
+			break
+		}
+		if b, ok := values[idx].([]byte); ok {
+			row[key] = string(b)
+		} else {
+			row[key] = values[idx]
+		}
+	}
+	if err := t.tmpl.Execute(t.w, row); err != nil {
+		panic(fmt.Errorf("absorb: rendering row %d: %w", t.rows+1, err))
+	}
+	t.rows++
+}
+
+func (t *TemplateAbsorber) Close() {
+	if !t.open {
+		panic(ErrNotOpen)
+	}
+	t.open = false
+}
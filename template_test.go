@@ -0,0 +1,26 @@
+package absorb_test
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/jyopp/absorb"
+)
+
+func TestTemplate(t *testing.T) {
+	tmpl := template.Must(template.New("row").Parse("{{.Name}}={{.Aliased}};"))
+	var out strings.Builder
+	abs := absorb.Template(&out, tmpl)
+	if err := (messySource{{"a", 1}, {[]byte("b"), nil}}).Emit(abs); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); got != "a=1;b=<no value>;" || abs.Rows() != 2 {
+		t.Fatalf("Unexpected output %q for %d rows", got, abs.Rows())
+	}
+
+	failing := template.Must(template.New("row").Parse("{{.Name.Missing}}"))
+	subpanic(t, "template error", func() {
+		(messySource{{"a", 1}}).Emit(absorb.Template(&out, failing))
+	})
+}
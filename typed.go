@@ -0,0 +1,101 @@
+package absorb
+
+import "reflect"
+
+// TypedAbsorber is implemented by Absorbers that accept the types of columns when opened.
+// Sources that know their column types in advance, such as SQL result sets, may call
+// OpenTyped instead of Open, so that the conversion for each column is selected once
+// rather than for each value. Absorbers created with New implement it.
+type TypedAbsorber interface {
+	Absorber
+	// OpenTyped is equivalent to Open with the key of each column. Columns whose Type is
+	// known are expected to hold values of that type, or nil; values of other types are
+	// still converted as usual.
+	OpenTyped(tag string, count int, columns ...ColumnSpec)
+}
+
+// OpenTyped opens into with the given columns, passing their types along if into is a
+// TypedAbsorber, or only their keys otherwise.
+func OpenTyped(into Absorber, tag string, count int, columns ...ColumnSpec) {
+	if typed, ok := into.(TypedAbsorber); ok {
+		typed.OpenTyped(tag, count, columns...)
+		return
+	}
+	keys := make([]string, len(columns))
+	for idx, col := range columns {
+		keys[idx] = col.Key
+	}
+	into.Open(tag, count, keys...)
+}
+
+func (a *absorberImpl) OpenTyped(tag string, count int, columns ...ColumnSpec) {
+	keys := make([]string, len(columns))
+	types := make([]reflect.Type, len(columns))
+	for idx, col := range columns {
+		keys[idx], types[idx] = col.Key, col.Type
+	}
+	a.Open(tag, count, keys...)
+	if a.keyed == nil && a.keep == nil && a.defaults == nil && !a.flatten {
+		// Otherwise, the values reaching the builder are not the columns given here.
+		a.builder = a.builder.typed(types, a.cfg)
+	}
+}
+
+// typed returns a copy of the builder whose setters convert values of the given column
+// types directly to their fields, when a column and its field are scalars of the same
+// kind and no converter, Scanner, or TextUnmarshaler could claim the value. Values of
+// other types are passed to the usual setters.
+func (a *elementBuilder) typed(types []reflect.Type, cfg *config) *elementBuilder {
+	if a.Setters == nil || len(types) != len(a.Setters) {
+		return a
+	}
+	var typed *elementBuilder
+	for idx, colType := range types {
+		if colType == nil || a.Setters[idx] == nil || !a.Plain[idx] {
+			continue
+		}
+		field := a.Fields[idx]
+		if !directlyConvertible(colType, field.Type, cfg) {
+			continue
+		}
+		if typed == nil {
+			b := *a
+			b.Setters = append([]fieldSetter(nil), a.Setters...)
+			typed = &b
+		}
+		typed.Setters[idx] = directSetter(field, colType, a.Setters[idx])
+	}
+	if typed == nil {
+		return a
+	}
+	return typed
+}
+
+// directlyConvertible reports whether values of type from can be converted to type to
+// by reflect alone, as the conversion chain would.
+func directlyConvertible(from, to reflect.Type, cfg *config) bool {
+	switch {
+	case from.Kind() != to.Kind() || !from.ConvertibleTo(to) || cfg.converter(to) != nil:
+		return false
+	case reflect.PtrTo(to).Implements(scannerType) || reflect.PtrTo(to).Implements(textUnmarshalerType):
+		return false
+	}
+	if kind := to.Kind(); kind == reflect.Bool || kind == reflect.String {
+		return true
+	}
+	class, _ := numericInfo(to.Kind())
+	return class != classNone
+}
+
+// directSetter returns a setter for field that converts values of colType without
+// consulting the conversion chain, and passes other values to set.
+func directSetter(field reflect.StructField, colType reflect.Type, set fieldSetter) fieldSetter {
+	index, fieldType := field.Index, field.Type
+	return func(elem reflect.Value, value interface{}, cfg *config) {
+		if val := reflect.ValueOf(value); val.Type() == colType {
+			fieldByIndex(elem, index).Set(val.Convert(fieldType))
+		} else {
+			set(elem, value, cfg)
+		}
+	}
+}
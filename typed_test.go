@@ -0,0 +1,76 @@
+package absorb_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jyopp/absorb"
+)
+
+type userID int64
+
+type upperString string
+
+func (u *upperString) UnmarshalText(text []byte) error {
+	*u = upperString(strings.ToUpper(string(text)))
+	return nil
+}
+
+func TestOpenTyped(t *testing.T) {
+	type record struct {
+		ID    userID
+		Name  upperString
+		Score float32
+	}
+	columns := []absorb.ColumnSpec{
+		{Key: "ID", Type: reflect.TypeOf(int64(0))},
+		{Key: "Name", Type: reflect.TypeOf("")},
+		{Key: "Score"},
+	}
+
+	var dst []record
+	abs := absorb.New(&dst)
+	absorb.OpenTyped(abs, "", 3, columns...)
+	abs.Absorb(int64(1), "ann", 1.5)
+	// Values of other types than declared are still converted.
+	abs.Absorb(uint8(2), []byte("bob"), nil)
+	abs.Absorb(nil, nil, float32(3))
+	abs.Close()
+
+	expect := []record{{1, "ANN", 1.5}, {2, "BOB", 0}, {0, "", 3}}
+	if !reflect.DeepEqual(dst, expect) {
+		t.Fatalf("Expected %+v, got %+v", expect, dst)
+	}
+
+	// Plain Absorbers are opened with the columns' keys.
+	var names []string
+	limited := absorb.Limit(absorb.New(&names), 1)
+	absorb.OpenTyped(limited, "", 2, absorb.ColumnSpec{Key: "Name", Type: reflect.TypeOf("")})
+	limited.Absorb("a")
+	limited.Absorb("b")
+	limited.Close()
+	if len(names) != 1 || names[0] != "a" {
+		t.Fatalf("Unexpected names %v", names)
+	}
+}
+
+func TestFromChanTyped(t *testing.T) {
+	type id int
+	type row struct {
+		ID   int
+		Name string
+	}
+	ch := make(chan row, 2)
+	ch <- row{1, "a"}
+	ch <- row{2, "b"}
+	close(ch)
+
+	var ids []struct{ ID id }
+	if err := absorb.Absorb(&ids, absorb.FromChan(ch, "")); err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 || ids[1].ID != 2 {
+		t.Fatalf("Unexpected elements %+v", ids)
+	}
+}
@@ -0,0 +1,213 @@
+package absorb
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Unit-aware fields declare the unit of their source column with struct tag options,
+// and hold values in a canonical unit:
+//
+//	type File struct {
+//		Size    int64         `csv:"size,unit=MiB"`         // bytes
+//		Timeout time.Duration `csv:"timeout,unit=ms"`       // nanoseconds
+//		Price   int64         `csv:"price,currency=USD"`    // cents
+//		Fee     int64         `csv:"fee,currency=JPY,cents"` // yen, already in minor units
+//	}
+//
+// Numeric values are scaled from the declared unit. String values may carry their own
+// unit suffix, such as "1.5 GiB", "250ms", or "12.99 USD", and otherwise use the declared
+// unit. Data sizes and durations are rounded to the nearest whole byte or nanosecond, but
+// an amount of money that cannot be represented exactly in minor units panics.
+
+type unitFamily int
+
+const (
+	unitSize unitFamily = iota
+	unitDuration
+	unitCurrency
+)
+
+// unitSpec describes the unit of a source column, and how to scale it to canonical units.
+type unitSpec struct {
+	family unitFamily
+	// scale converts a quantity of the declared unit to canonical units.
+	scale *big.Rat
+	// currency is the declared ISO 4217 code, and minorScale converts major to minor units.
+	currency   string
+	minorScale *big.Rat
+}
+
+var sizeUnits = map[string]int64{
+	"B":  1,
+	"KB": 1e3, "MB": 1e6, "GB": 1e9, "TB": 1e12, "PB": 1e15,
+	"KiB": 1 << 10, "MiB": 1 << 20, "GiB": 1 << 30, "TiB": 1 << 40, "PiB": 1 << 50,
+}
+
+var durationUnits = map[string]int64{
+	"ns": int64(time.Nanosecond), "us": int64(time.Microsecond), "µs": int64(time.Microsecond),
+	"ms": int64(time.Millisecond), "s": int64(time.Second), "m": int64(time.Minute),
+	"min": int64(time.Minute), "h": int64(time.Hour), "d": int64(24 * time.Hour),
+}
+
+// currencyExponents lists ISO 4217 currencies without two decimal places of minor units.
+var currencyExponents = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+}
+
+// newUnitSpec returns the unit declared by a field's tag options, or nil if there is none.
+// Panics if the unit is not known.
+func newUnitSpec(field reflect.StructField, opts tagOptions) *unitSpec {
+	if unit, ok := opts.get("unit"); ok {
+		if n, ok := lookupUnit(sizeUnits, unit); ok {
+			return &unitSpec{family: unitSize, scale: big.NewRat(n, 1)}
+		}
+		if n, ok := lookupUnit(durationUnits, unit); ok {
+			return &unitSpec{family: unitDuration, scale: big.NewRat(n, 1)}
+		}
+		panic("cannot absorb field " + field.Name + " with unknown unit " + strconv.Quote(unit))
+	}
+	if code, ok := opts.get("currency"); ok {
+		code = strings.ToUpper(code)
+		if len(code) != 3 {
+			panic("cannot absorb field " + field.Name + " with invalid currency " + strconv.Quote(code))
+		}
+		exp, ok := currencyExponents[code]
+		if !ok {
+			exp = 2
+		}
+		minor := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil))
+		spec := &unitSpec{family: unitCurrency, scale: minor, currency: code, minorScale: minor}
+		if opts.has("cents") {
+			// The column already holds minor units.
+			spec.scale = big.NewRat(1, 1)
+		}
+		return spec
+	}
+	return nil
+}
+
+// lookupUnit finds a unit by name, ignoring case if there is no exact match.
+func lookupUnit(units map[string]int64, name string) (int64, bool) {
+	if n, ok := units[name]; ok {
+		return n, true
+	}
+	for unit, n := range units {
+		if strings.EqualFold(unit, name) {
+			return n, true
+		}
+	}
+	return 0, false
+}
+
+// assignUnit scales src from the declared unit, and assigns it to dst in canonical units.
+func assignUnit(dst, src reflect.Value, spec *unitSpec, cfg *config) {
+	src = reflect.Indirect(src)
+	var quantity *big.Rat
+	switch src.Kind() {
+	case reflect.String:
+		quantity = spec.parse(src.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		quantity = new(big.Rat).Mul(big.NewRat(src.Int(), 1), spec.scale)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		quantity = new(big.Rat).Mul(new(big.Rat).SetInt(new(big.Int).SetUint64(src.Uint())), spec.scale)
+	case reflect.Float32, reflect.Float64:
+		// Format the float as it was written, so that 19.99 is not 19.989999...
+		quantity = spec.parseNumber(strconv.FormatFloat(src.Float(), 'g', -1, src.Type().Bits()))
+		quantity.Mul(quantity, spec.scale)
+	default:
+		panic("cannot convert " + src.Type().String() + " to a quantity of units")
+	}
+
+	if dst.Kind() == reflect.Ptr {
+		if dst.IsZero() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		dst = dst.Elem()
+	}
+	switch dst.Kind() {
+	case reflect.Float32, reflect.Float64:
+		f, _ := quantity.Float64()
+		dst.SetFloat(f)
+		return
+	}
+	if !quantity.IsInt() && spec.family == unitCurrency {
+		panic(fmt.Sprintf("cannot represent %s %s exactly in minor units", quantity.FloatString(3), spec.currency))
+	}
+	_assign(dst, reflect.ValueOf(roundRat(quantity)), cfg)
+}
+
+// parse parses a quantity, with an optional unit suffix, and returns it in canonical units.
+func (spec *unitSpec) parse(text string) *big.Rat {
+	text = strings.TrimSpace(text)
+	end := strings.LastIndexAny(text, "0123456789.") + 1
+	number, unit := strings.TrimSpace(text[:end]), strings.TrimSpace(text[end:])
+
+	scale := spec.scale
+	switch spec.family {
+	case unitSize:
+		if unit != "" {
+			n, ok := lookupUnit(sizeUnits, unit)
+			if !ok {
+				panic("cannot convert " + strconv.Quote(text) + " to a data size")
+			}
+			scale = big.NewRat(n, 1)
+		}
+	case unitDuration:
+		if unit != "" {
+			n, ok := lookupUnit(durationUnits, unit)
+			if _, isNumber := new(big.Rat).SetString(number); !ok || !isNumber {
+				// Compound durations such as "1h30m"
+				d, err := time.ParseDuration(text)
+				if err != nil {
+					panic("cannot convert " + strconv.Quote(text) + " to a duration")
+				}
+				return big.NewRat(int64(d), 1)
+			}
+			scale = big.NewRat(n, 1)
+		}
+	case unitCurrency:
+		// Currency codes may precede or follow the amount.
+		if strings.HasPrefix(strings.ToUpper(text), spec.currency) {
+			number, unit = strings.TrimSpace(text[len(spec.currency):]), spec.currency
+		}
+		if unit != "" {
+			if !strings.EqualFold(unit, spec.currency) {
+				panic("cannot convert " + strconv.Quote(text) + " to an amount of " + spec.currency)
+			}
+			// Amounts with a currency code are in major units.
+			scale = spec.minorScale
+		}
+	}
+	quantity := spec.parseNumber(number)
+	return quantity.Mul(quantity, scale)
+}
+
+func (spec *unitSpec) parseNumber(text string) *big.Rat {
+	quantity, ok := new(big.Rat).SetString(text)
+	if !ok {
+		panic("cannot convert " + strconv.Quote(text) + " to a quantity of units")
+	}
+	return quantity
+}
+
+// roundRat rounds r to the nearest integer, with halves rounded away from zero.
+// Panics if the result does not fit in an int64.
+func roundRat(r *big.Rat) int64 {
+	n := new(big.Int).Abs(r.Num())
+	n.Mul(n, big.NewInt(2)).Add(n, r.Denom())
+	n.Quo(n, new(big.Int).Mul(r.Denom(), big.NewInt(2)))
+	if r.Sign() < 0 {
+		n.Neg(n)
+	}
+	if !n.IsInt64() {
+		panic(fmt.Errorf("%w: %s does not fit in int64", ErrNumericRange, r.FloatString(0)))
+	}
+	return n.Int64()
+}
@@ -0,0 +1,62 @@
+package absorb_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jyopp/absorb"
+)
+
+type unitSource struct {
+	values []interface{}
+}
+
+func (us unitSource) Emit(into absorb.Absorber) error {
+	into.Open("csv", 1, "size", "timeout", "price", "fee", "ratio")
+	defer into.Close()
+	into.Absorb(us.values...)
+	return nil
+}
+
+type unitDst struct {
+	Size    int64         `csv:"size,unit=MiB"`
+	Timeout time.Duration `csv:"timeout,unit=ms"`
+	Price   int64         `csv:"price,currency=USD"`
+	Fee     *int          `csv:"fee,currency=jpy,cents"`
+	Ratio   float64       `csv:"ratio,unit=KB"`
+}
+
+func TestUnits(t *testing.T) {
+	for _, values := range [][]interface{}{
+		{1.5, 250, 19.99, 500, "2.5"},
+		{"1.5", "250", "19.99", "500", 2.5},
+		{"1536 KiB", "0.25s", "USD 19.99", "JPY 500", "2500 B"},
+		{"1.5MiB", "250ms", "19.99 usd", uint16(500), "2.5 kb"},
+		{float32(1.5), "0h0m0.25s", float32(19.99), int64(500), float32(2.5)},
+	} {
+		var dst unitDst
+		if err := absorb.Absorb(&dst, unitSource{values}); err != nil {
+			t.Fatal(err)
+		}
+		if dst.Size != 1572864 || dst.Timeout != 250*time.Millisecond || dst.Price != 1999 || dst.Fee == nil || *dst.Fee != 500 || dst.Ratio != 2500 {
+			t.Errorf("Unexpected values %+v from %v", dst, values)
+		}
+	}
+
+	var dst unitDst
+	subpanic(t, "Fractional Cents", func() {
+		absorb.Absorb(&dst, unitSource{[]interface{}{1, 1, "12.345", 1, 1}})
+	})
+	subpanic(t, "Wrong Currency", func() {
+		absorb.Absorb(&dst, unitSource{[]interface{}{1, 1, "12.34 EUR", 1, 1}})
+	})
+	subpanic(t, "Wrong Unit", func() {
+		absorb.Absorb(&dst, unitSource{[]interface{}{"1 hour", 1, 1, 1, 1}})
+	})
+	subpanic(t, "Unknown Unit", func() {
+		var dst struct {
+			Size int `csv:"size,unit=furlongs"`
+		}
+		absorb.Absorb(&dst, unitSource{[]interface{}{1, 1, 1, 1, 1}})
+	})
+}
@@ -0,0 +1,212 @@
+package absorb
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// ColumnSpec describes a column emitted to an AbsorberV2.
+type ColumnSpec struct {
+	Key string
+	// Type is the type of the column's values, or nil if it is not known in advance.
+	Type reflect.Type
+}
+
+// AbsorberV2 is a richer contract for absorbing rows. Unlike Absorber, its methods
+// report failures as errors instead of panicking, accept a context to cancel absorption,
+// describe column types up front, and accept rows with their own keys.
+//
+// UpgradeAbsorber and DowngradeAbsorber adapt between the two interfaces, so that sources
+// written for either contract can fill any destination.
+type AbsorberV2 interface {
+	// Open prepares the absorber for rows with the given columns, as Absorber.Open does.
+	Open(ctx context.Context, tag string, count int, columns ...ColumnSpec) error
+	// Absorb absorbs a row with one value for each column given to Open.
+	Absorb(ctx context.Context, values ...interface{}) error
+	// AbsorbKeyed absorbs a row whose values are keyed by the given keys, which must be a
+	// subset of the columns given to Open, in any order. Missing columns are nil.
+	AbsorbKeyed(ctx context.Context, keys []string, values []interface{}) error
+	Close(ctx context.Context) error
+}
+
+// AbsorbableV2 is implemented by sources that target the AbsorberV2 contract.
+type AbsorbableV2 interface {
+	EmitV2(ctx context.Context, into AbsorberV2) error
+}
+
+// NewV2 creates an AbsorberV2 that writes elements into dst, as New does.
+func NewV2(dst interface{}, opts ...Option) AbsorberV2 {
+	return UpgradeAbsorber(New(dst, opts...))
+}
+
+// AbsorbContext absorbs all of src's values into a new AbsorberV2 for dst.
+// Equivalent to src.EmitV2(ctx, absorb.NewV2(dst, opts...)).
+func AbsorbContext(ctx context.Context, dst interface{}, src AbsorbableV2, opts ...Option) error {
+	return src.EmitV2(ctx, NewV2(dst, opts...))
+}
+
+// UpgradeAbsorber adapts an Absorber to the AbsorberV2 interface.
+// Panics raised by the Absorber are returned as errors, and column types are passed on
+// if the Absorber is a TypedAbsorber.
+func UpgradeAbsorber(a Absorber) AbsorberV2 {
+	if down, ok := a.(*downgradedAbsorber); ok {
+		return down.next
+	}
+	return &upgradedAbsorber{next: a}
+}
+
+type upgradedAbsorber struct {
+	next    Absorber
+	keys    []string
+	rowData []interface{}
+}
+
+func (u *upgradedAbsorber) Open(ctx context.Context, tag string, count int, columns ...ColumnSpec) (err error) {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	u.keys = make([]string, len(columns))
+	for idx, col := range columns {
+		u.keys[idx] = col.Key
+	}
+	u.rowData = make([]interface{}, len(columns))
+	defer recoverError(&err)
+	OpenTyped(u.next, tag, count, columns...)
+	return nil
+}
+
+func (u *upgradedAbsorber) Absorb(ctx context.Context, values ...interface{}) (err error) {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	defer recoverError(&err)
+	u.next.Absorb(values...)
+	return nil
+}
+
+func (u *upgradedAbsorber) AbsorbKeyed(ctx context.Context, keys []string, values []interface{}) error {
+	if len(keys) != len(values) {
+		return fmt.Errorf("absorb: %d keys given for %d values", len(keys), len(values))
+	}
+	for idx := range u.rowData {
+		u.rowData[idx] = nil
+	}
+	for idx, key := range keys {
+		col := -1
+		for i, k := range u.keys {
+			if k == key {
+				col = i
+				break
+			}
+		}
+		if col < 0 {
+			return fmt.Errorf("absorb: row has key %q, which was not opened", key)
+		}
+		u.rowData[col] = values[idx]
+	}
+	return u.Absorb(ctx, u.rowData...)
+}
+
+func (u *upgradedAbsorber) Close(ctx context.Context) (err error) {
+	defer recoverError(&err)
+	u.next.Close()
+	return nil
+}
+
+// recoverError converts a panic into an error, stored in err.
+func recoverError(err *error) {
+	if p := recover(); p != nil {
+		*err = panicError(p)
+	}
+}
+
+// panicError returns the value of a recovered panic as an error.
+func panicError(p interface{}) error {
+	if err, ok := p.(error); ok {
+		return err
+	}
+	return fmt.Errorf("absorb: %v", p)
+}
+
+// DowngradeAbsorber adapts an AbsorberV2 to the Absorber interface, using ctx for each
+// call. Errors returned by the AbsorberV2 are raised as panics, as Absorber requires.
+func DowngradeAbsorber(ctx context.Context, a AbsorberV2) Absorber {
+	return &downgradedAbsorber{ctx: ctx, next: a}
+}
+
+type downgradedAbsorber struct {
+	ctx  context.Context
+	next AbsorberV2
+}
+
+// shimError carries an AbsorberV2 error through a panic in a downgraded Absorber, so that
+// UpgradeSource can return it without recovering unrelated panics.
+type shimError struct {
+	err error
+}
+
+func (d *downgradedAbsorber) check(err error) {
+	if err != nil {
+		panic(shimError{err})
+	}
+}
+
+func (d *downgradedAbsorber) Open(tag string, count int, keys ...string) {
+	columns := make([]ColumnSpec, len(keys))
+	for idx, key := range keys {
+		columns[idx].Key = key
+	}
+	d.check(d.next.Open(d.ctx, tag, count, columns...))
+}
+
+func (d *downgradedAbsorber) Absorb(values ...interface{}) {
+	d.check(d.next.Absorb(d.ctx, values...))
+}
+
+func (d *downgradedAbsorber) Close() {
+	d.check(d.next.Close(d.ctx))
+}
+
+// UpgradeSource adapts an Absorbable to the AbsorbableV2 interface. Errors returned by the
+// AbsorberV2, including the context's error, stop the source and are returned by EmitV2.
+func UpgradeSource(src Absorbable) AbsorbableV2 {
+	if down, ok := src.(downgradedSource); ok {
+		return down.src
+	}
+	return upgradedSource{src}
+}
+
+type upgradedSource struct {
+	src Absorbable
+}
+
+func (u upgradedSource) EmitV2(ctx context.Context, into AbsorberV2) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			shim, ok := p.(shimError)
+			if !ok {
+				panic(p)
+			}
+			err = shim.err
+		}
+	}()
+	return u.src.Emit(DowngradeAbsorber(ctx, into))
+}
+
+// DowngradeSource adapts an AbsorbableV2 to the Absorbable interface, emitting with a
+// background context.
+func DowngradeSource(src AbsorbableV2) Absorbable {
+	if up, ok := src.(upgradedSource); ok {
+		return up.src
+	}
+	return downgradedSource{src}
+}
+
+type downgradedSource struct {
+	src AbsorbableV2
+}
+
+func (d downgradedSource) Emit(into Absorber) error {
+	return d.src.EmitV2(context.Background(), UpgradeAbsorber(into))
+}
@@ -0,0 +1,82 @@
+package absorb_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/jyopp/absorb"
+)
+
+// keyedSource targets AbsorberV2, emitting rows with varying keys.
+type keyedSource struct{}
+
+func (keyedSource) EmitV2(ctx context.Context, into absorb.AbsorberV2) error {
+	columns := []absorb.ColumnSpec{
+		{Key: "Name", Type: reflect.TypeOf("")},
+		{Key: "Aliased", Type: reflect.TypeOf(0)},
+	}
+	if err := into.Open(ctx, "test", 2, columns...); err != nil {
+		return err
+	}
+	if err := into.AbsorbKeyed(ctx, []string{"Aliased", "Name"}, []interface{}{1, "one"}); err != nil {
+		return err
+	}
+	if err := into.AbsorbKeyed(ctx, []string{"Aliased"}, []interface{}{2}); err != nil {
+		return err
+	}
+	return into.Close(ctx)
+}
+
+func TestAbsorberV2(t *testing.T) {
+	ctx := context.Background()
+	expect := []TestDst{{"one", 1, 0}, {"", 2, 0}}
+
+	var dst []TestDst
+	if err := absorb.AbsorbContext(ctx, &dst, keyedSource{}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(dst, expect) {
+		t.Fatalf("Expected %+v, got %+v", expect, dst)
+	}
+
+	// v2 sources fill v1 absorbers, and v1 sources fill v2 absorbers.
+	dst = nil
+	if err := absorb.Absorb(&dst, absorb.DowngradeSource(keyedSource{})); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(dst, expect) {
+		t.Fatalf("Expected %+v, got %+v", expect, dst)
+	}
+	dst = nil
+	if err := absorb.AbsorbContext(ctx, &dst, absorb.UpgradeSource(testSource{i: 3})); err != nil {
+		t.Fatal(err)
+	}
+	if len(dst) != 3 {
+		t.Fatalf("Unexpected rows %+v", dst)
+	}
+}
+
+func TestAbsorberV2Errors(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var dst []TestDst
+	if err := absorb.AbsorbContext(ctx, &dst, absorb.UpgradeSource(testSource{i: 3})); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+
+	// Panics from the underlying Absorber become errors.
+	abs := absorb.NewV2(&dst)
+	if err := abs.Absorb(context.Background(), "test", 1); !errors.Is(err, absorb.ErrNotOpen) {
+		t.Fatalf("Expected ErrNotOpen, got %v", err)
+	}
+	var n int
+	abs = absorb.NewV2(&n)
+	if err := abs.Open(context.Background(), "", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := abs.Absorb(context.Background(), "not a number"); err == nil {
+		t.Fatal("Expected conversion error")
+	}
+}
@@ -0,0 +1,59 @@
+package absorb
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// AfterAbsorber is implemented by elements that validate or normalize themselves once a
+// row's values are assigned, such as a struct checking that a required field is set. A
+// pointer to the element is called after any finalizer, and before the element is
+// stored, sent, or passed to a callback.
+//
+// A returned error is wrapped in a *ValidationError. It is handled like a failed
+// conversion: the Absorber panics with it, unless CollectErrors collects it or an
+// ErrorHandler set with OnError returns Skip, which skip the row, or Zero, which keeps
+// the element as it is. The ErrorHandler is called with an empty key.
+type AfterAbsorber interface {
+	AfterAbsorb() error
+}
+
+var afterAbsorberType = reflect.TypeOf((*AfterAbsorber)(nil)).Elem()
+
+// ValidationError reports an error returned by an element's AfterAbsorb method.
+type ValidationError struct {
+	// Row is the number of the row in the source, counting from 1.
+	Row int
+	// Type is the element's type.
+	Type reflect.Type
+	Err  error
+}
+
+func (e *ValidationError) Error() string {
+	return "absorb: row " + strconv.Itoa(e.Row) + ": invalid " + e.Type.String() + ": " + e.Err.Error()
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// validate calls the AfterAbsorb method of the element built in target for the row at idx.
+func (a *absorberImpl) validate(target reflect.Value, idx int) {
+	if target.Kind() != reflect.Ptr {
+		target = target.Addr()
+	}
+	err := target.Interface().(AfterAbsorber).AfterAbsorb()
+	if err == nil {
+		return
+	}
+	verr := &ValidationError{Row: a.cfg.resume + idx + 1, Type: target.Type().Elem(), Err: err}
+	if fn := a.cfg.onError; fn != nil {
+		switch fn(verr.Row, "", verr) {
+		case Skip:
+			panic(errSkipRow)
+		case Zero:
+			return
+		}
+	}
+	panic(verr)
+}
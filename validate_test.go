@@ -0,0 +1,78 @@
+package absorb_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jyopp/absorb"
+)
+
+var errNoName = errors.New("name is required")
+
+type validatedDst struct {
+	Name   string
+	Actual int `test:"Aliased"`
+	Checks int
+}
+
+func (v *validatedDst) AfterAbsorb() error {
+	v.Checks++
+	if v.Name == "" {
+		return errNoName
+	}
+	return nil
+}
+
+func TestAfterAbsorb(t *testing.T) {
+	src := messySource{
+		{"a", 1},
+		{"", 2},
+		{"c", 3},
+	}
+
+	var dst []validatedDst
+	subpanic(t, "invalid", func() {
+		absorb.Absorb(&dst, src)
+	})
+	if len(dst) == 0 || dst[0].Checks != 1 {
+		t.Fatalf("Expected the first element to be validated once, got %+v", dst)
+	}
+
+	// CollectErrors skips the invalid row and reports it.
+	dst = nil
+	err := absorb.Absorb(&dst, src, absorb.CollectErrors())
+	var rowErrs absorb.RowErrors
+	var validErr *absorb.ValidationError
+	if !errors.As(err, &rowErrs) || len(rowErrs) != 1 || !errors.As(err, &validErr) || validErr.Row != 2 || !errors.Is(err, errNoName) {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if len(dst) != 2 || dst[0].Name != "a" || dst[1].Name != "c" {
+		t.Fatalf("Unexpected elements %+v", dst)
+	}
+
+	// An ErrorHandler is called with an empty key, and may keep the element.
+	var rows []int
+	var ptrs []*validatedDst
+	keep := absorb.OnError(func(row int, key string, err error) absorb.ErrorAction {
+		if key != "" || !errors.Is(err, errNoName) {
+			t.Errorf("Unexpected error %v for key %q", err, key)
+		}
+		rows = append(rows, row)
+		return absorb.Zero
+	})
+	if err := absorb.Absorb(&ptrs, src, keep); err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0] != 2 || len(ptrs) != 3 || ptrs[1].Actual != 2 || ptrs[1].Checks != 1 {
+		t.Fatalf("Unexpected rows %v, elements %+v", rows, ptrs)
+	}
+
+	skip := absorb.OnError(func(int, string, error) absorb.ErrorAction { return absorb.Skip })
+	ch := make(chan validatedDst, len(src))
+	if err := absorb.Absorb(ch, src, skip); err != nil {
+		t.Fatal(err)
+	}
+	if len(ch) != 2 {
+		t.Fatalf("Expected 2 elements sent, got %d", len(ch))
+	}
+}